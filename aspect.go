@@ -0,0 +1,138 @@
+package mpg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// pelAspectRatios holds the height of a pixel divided by its width for each
+// aspect ratio code of the sequence header. Codes 0 and 15 are not defined.
+var pelAspectRatios = [16]float64{
+	1, 1, 0.6735, 0.7031, 0.7615, 0.8055, 0.8437, 0.8935,
+	0.9157, 0.9815, 1.0255, 1.0695, 1.0950, 1.1575, 1.2015, 1,
+}
+
+// displayAspectRatios holds the width of the display divided by its height for
+// each aspect ratio code of an MPEG2 sequence header. Code 1 means square
+// pixels, and the others are not defined.
+var displayAspectRatios = [16]float64{2: 4.0 / 3, 3: 16.0 / 9, 4: 2.21}
+
+// videoPixelAspectRatio returns the width of a pixel of "video" divided by its
+// height. MPEG2 codes the aspect ratio of the display rather than of the
+// pixels.
+func videoPixelAspectRatio(video *plm_video_t) float64 {
+	if video.Mpeg2 == nil {
+		return 1 / pelAspectRatios[video.Aspect_ratio]
+	}
+	if dar := displayAspectRatios[video.Aspect_ratio]; dar != 0 {
+		return dar * float64(video.Height) / float64(video.Width)
+	}
+	return 1
+}
+
+// PixelAspectRatio is the width of a pixel divided by its height. Pixels of
+// anamorphic video, such as 352x240 VCDs shown at 4:3, are not square, so
+// the frame has to be scaled to "DisplaySize" to look right. It is 1 if there
+// is no video.
+func (plm *Player) PixelAspectRatio() float64 { defer plm.lock()(); return plm.pixelAspectRatio() }
+
+func (plm *Player) pixelAspectRatio() float64 {
+	p := plm.plm
+	if plm_init_decoders(p) == _false || p.Video_decoder == nil {
+		return 1
+	}
+	return videoPixelAspectRatio(p.Video_decoder)
+}
+
+// DisplaySize is the size the video should be shown at, which is its width
+// scaled by "PixelAspectRatio" and its height.
+func (plm *Player) DisplaySize() (width, height int) {
+	defer plm.lock()()
+	return plm.displaySize()
+}
+
+func (plm *Player) displaySize() (width, height int) {
+	width, height = int(plm_get_width(plm.plm)), int(plm_get_height(plm.plm))
+	return int(math.Round(float64(width) * plm.pixelAspectRatio())), height
+}
+
+// AspectCorrection returns true if "DrawTo" scales frames to their display
+// aspect ratio.
+func (plm *Player) AspectCorrection() bool { defer plm.lock()(); return plm.aspectCorrection }
+
+// SetAspectCorrection sets whether "DrawTo" scales frames to fit the image
+// they are drawn to, keeping the aspect ratio of "DisplaySize". The frame is
+// centered, and the parts of the image it does not cover are left unchanged.
+func (plm *Player) SetAspectCorrection(enabled bool) {
+	defer plm.lock()()
+	plm.aspectCorrection = enabled
+}
+
+// fitRect returns the largest rectangle with the aspect ratio of "width" and
+// "height" that fits centered in "bounds".
+func fitRect(bounds image.Rectangle, width, height int) image.Rectangle {
+	if width <= 0 || height <= 0 {
+		return image.Rectangle{}
+	}
+	w, h := bounds.Dx(), bounds.Dy()
+	if w*height > h*width {
+		w = int(math.Round(float64(h) * float64(width) / float64(height)))
+	} else {
+		h = int(math.Round(float64(w) * float64(height) / float64(width)))
+	}
+	min := bounds.Min.Add(image.Pt((bounds.Dx()-w)/2, (bounds.Dy()-h)/2))
+	return image.Rectangle{min, min.Add(image.Pt(w, h))}
+}
+
+// drawScaled draws the frame scaled to "r" with bilinear filtering.
+func (f *Frame) drawScaled(img draw.Image, r image.Rectangle) {
+	clip := r.Intersect(img.Bounds())
+	if clip.Empty() || f.Width == 0 || f.Height == 0 {
+		return
+	}
+	rgba, _ := img.(*image.RGBA)
+	sx := float64(f.Width) / float64(r.Dx())
+	sy := float64(f.Height) / float64(r.Dy())
+	cw, ch := (f.Width+1)/2, (f.Height+1)/2
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		fy := (float64(y-r.Min.Y)+0.5)*sy - 0.5
+		for x := clip.Min.X; x < clip.Max.X; x++ {
+			fx := (float64(x-r.Min.X)+0.5)*sx - 0.5
+			// Chroma samples sit between 2x2 luma samples.
+			luma := bilinear(f.Y, f.YStride, f.Width, f.Height, fx, fy)
+			cb := bilinear(f.Cb, f.CStride, cw, ch, (fx-0.5)/2, (fy-0.5)/2)
+			cr := bilinear(f.Cr, f.CStride, cw, ch, (fx-0.5)/2, (fy-0.5)/2)
+			red, green, blue := ycbcrToRGB(luma, cb, cr)
+			if rgba != nil {
+				i := rgba.PixOffset(x, y)
+				rgba.Pix[i+0] = red
+				rgba.Pix[i+1] = green
+				rgba.Pix[i+2] = blue
+				rgba.Pix[i+3] = 0xFF
+			} else {
+				img.Set(x, y, color.RGBA{red, green, blue, 0xFF})
+			}
+		}
+	}
+}
+
+// bilinear samples "plane" at "x" and "y", clamped to its "width" and
+// "height".
+func bilinear(plane []byte, stride, width, height int, x, y float64) uint8 {
+	x = math.Max(0, math.Min(x, float64(width-1)))
+	y = math.Max(0, math.Min(y, float64(height-1)))
+	x0, y0 := int(x), int(y)
+	x1, y1 := x0, y0
+	if x1 < width-1 {
+		x1++
+	}
+	if y1 < height-1 {
+		y1++
+	}
+	tx, ty := x-float64(x0), y-float64(y0)
+	top := float64(plane[x0+y0*stride])*(1-tx) + float64(plane[x1+y0*stride])*tx
+	bottom := float64(plane[x0+y1*stride])*(1-tx) + float64(plane[x1+y1*stride])*tx
+	return uint8(top*(1-ty) + bottom*ty + 0.5)
+}
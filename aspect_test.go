@@ -0,0 +1,138 @@
+package mpg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// withAspectRatio returns a copy of "data" with the aspect ratio code of every
+// sequence header set to "code".
+func withAspectRatio(data []byte, code int) []byte {
+	data = append([]byte(nil), data...)
+	for i := 0; ; {
+		n := bytes.Index(data[i:], []byte{0x00, 0x00, 0x01, 0xB3})
+		if n < 0 {
+			return data
+		}
+		i += n + 4
+		writeBits(data, i, 24, 4, code)
+	}
+}
+
+func TestPixelAspectRatio(t *testing.T) {
+	// Height of a pixel divided by its width, from ISO/IEC 11172-2.
+	if pelAspectRatios[1] != 1 || pelAspectRatios[3] != 0.7031 || pelAspectRatios[8] != 0.9157 ||
+		pelAspectRatios[12] != 1.0950 || pelAspectRatios[14] != 1.2015 {
+		t.Errorf("pel aspect ratios are %v", pelAspectRatios)
+	}
+	for code := 1; code <= 14; code++ {
+		plm, err := NewPlayerFromBytes(withAspectRatio(readTestFile(t, testFile), code))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := 1 / pelAspectRatios[code]
+		if got := plm.PixelAspectRatio(); got != want || plm.Info().PixelAspectRatio != want {
+			t.Errorf("code %d: pixel aspect ratio is %v, want %v", code, got, want)
+		}
+		width, height := plm.DisplaySize()
+		if want := int(math.Round(testWidth * want)); width != want || height != testHeight {
+			t.Errorf("code %d: display size is %dx%d, want %dx%d", code, width, height, want, testHeight)
+		}
+	}
+
+	plm, err := NewPlayerFromBytes(readTestFile(t, "testdata/novideo.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plm.PixelAspectRatio() != 1 {
+		t.Errorf("pixel aspect ratio without video is %v, want 1", plm.PixelAspectRatio())
+	}
+}
+
+func TestFitRect(t *testing.T) {
+	for _, c := range []struct {
+		bounds        image.Rectangle
+		width, height int
+		want          image.Rectangle
+	}{
+		// Letterbox
+		{image.Rect(0, 0, 100, 100), 200, 100, image.Rect(0, 25, 100, 75)},
+		// Pillarbox
+		{image.Rect(0, 0, 100, 100), 50, 100, image.Rect(25, 0, 75, 100)},
+		{image.Rect(0, 0, 160, 90), 4, 3, image.Rect(20, 0, 140, 90)},
+		{image.Rect(10, 20, 110, 70), 2, 1, image.Rect(10, 20, 110, 70)},
+		{image.Rect(-50, -50, 50, 50), 91, 48, image.Rect(-50, -27, 50, 26)},
+		{image.Rect(0, 0, 100, 100), 0, 48, image.Rectangle{}},
+	} {
+		if got := fitRect(c.bounds, c.width, c.height); got != c.want {
+			t.Errorf("fitting %dx%d in %v gave %v, want %v", c.width, c.height, c.bounds, got, c.want)
+		}
+	}
+}
+
+func TestDrawToAspectCorrection(t *testing.T) {
+	// 16:9 pixels make the 64x48 video 91x48.
+	plm, err := NewPlayerFromBytes(withAspectRatio(readTestFile(t, testFile), 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plm.NextFrame(); err != nil {
+		t.Fatal(err)
+	}
+	frame := image.NewRGBA(image.Rect(0, 0, testWidth, testHeight))
+	plm.DrawTo(frame)
+
+	border := color.RGBA{1, 2, 3, 4}
+	newImage := func() *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 182, 182))
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:], []byte{border.R, border.G, border.B, border.A})
+		}
+		return img
+	}
+	img := newImage()
+	plm.SetAspectCorrection(true)
+	if !plm.AspectCorrection() {
+		t.Fatal("aspect correction is not enabled")
+	}
+	plm.DrawTo(img)
+	// The frame is scaled to 182x96, centered with borders of 43 pixels.
+	want := image.Rect(0, 43, 182, 139)
+	if got := fitRect(img.Rect, 91, 48); got != want {
+		t.Fatalf("frame is drawn to %v, want %v", got, want)
+	}
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			if c := img.RGBAAt(x, y); image.Pt(x, y).In(want) != (c != border) {
+				t.Fatalf("pixel (%d,%d) is %v, inside the frame: %v", x, y, c, image.Pt(x, y).In(want))
+			}
+		}
+	}
+	// Scaled pixels are close to the pixels of the frame they come from.
+	for _, p := range []image.Point{{10, 10}, {32, 24}, {50, 40}} {
+		got := img.RGBAAt(want.Min.X+p.X*want.Dx()/testWidth, want.Min.Y+p.Y*want.Dy()/testHeight)
+		c := frame.RGBAAt(p.X, p.Y)
+		for _, d := range []int{int(got.R) - int(c.R), int(got.G) - int(c.G), int(got.B) - int(c.B)} {
+			if d < -24 || d > 24 {
+				t.Errorf("scaled pixel of (%d,%d) is %v, want about %v", p.X, p.Y, got, c)
+				break
+			}
+		}
+	}
+
+	// Without aspect correction, the frame is drawn at its coded size.
+	img = newImage()
+	plm.SetAspectCorrection(false)
+	plm.DrawTo(img)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			inside := x < testWidth && y < testHeight
+			if c := img.RGBAAt(x, y); inside && c != frame.RGBAAt(x, y) || !inside && c != border {
+				t.Fatalf("pixel (%d,%d) is %v without aspect correction", x, y, c)
+			}
+		}
+	}
+}
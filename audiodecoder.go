@@ -0,0 +1,356 @@
+package mpg
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gotranspile/cxgo/runtime/stdio"
+)
+
+// AudioDecoder decodes a raw MPEG1 audio stream of any layer, such as an ".mp2"
+// or ".mp3" file, that is not wrapped in an MPG container. MPEG2 Layer I and II
+// audio at the low sample rates of 16, 22.05 and 24 kHz is decoded as well. Use
+// a "Player" for MPG files.
+//
+// A decoder is safe to use from multiple goroutines.
+type AudioDecoder struct {
+	mu       sync.Mutex
+	audio    *plm_audio_t
+	seekable bool
+	reader   *readerSource
+	lastErr  error
+	// start is the offset of the first frame, after any leading junk such as
+	// an ID3 tag.
+	start uint64
+
+	// pending holds audio decoded by "Read" that was not read yet.
+	pending      bytes.Buffer
+	sampleFormat SampleFormat
+}
+
+// audioSeekPreroll is the number of frames decoded and discarded before the
+// frame seeked to, so the synthesis filter is filled with the audio leading up
+// to it.
+const audioSeekPreroll = 2
+
+func newAudioDecoder(buffer *plm_buffer_t, src *readerSource) (*AudioDecoder, error) {
+	var start uint64
+	skipID3(buffer)
+	if src == nil {
+		start = findAudioSync(buffer)
+		plm_buffer_seek(buffer, start)
+	}
+	audio := plm_audio_create_with_buffer(buffer, _true)
+	if plm_audio_has_header(audio) != _true {
+		plm_audio_destroy(audio)
+		return nil, ExpectedHeader{}
+	}
+	return &AudioDecoder{
+		audio:        audio,
+		seekable:     src == nil,
+		reader:       src,
+		start:        start,
+		sampleFormat: SampleS16LE,
+	}, nil
+}
+
+// skipID3 skips the ID3v2 tag MP3 files often start with. Frame syncs could
+// otherwise be found by mistake in the data of the tag.
+func skipID3(buffer *plm_buffer_t) {
+	start := buffer.Bit_index
+	if plm_buffer_has(buffer, 80) != _true || plm_buffer_read(buffer, 24) != 0x494433 {
+		buffer.Bit_index = start
+		return
+	}
+	plm_buffer_skip(buffer, 16)
+	flags := plm_buffer_read(buffer, 8)
+	// The size is stored in 7 bits per byte, and does not count the header
+	// or the footer.
+	var size uint64
+	for i := 0; i < 4; i++ {
+		size = size<<7 | uint64(plm_buffer_read(buffer, 8)&0x7F)
+	}
+	if flags&0x10 != 0 {
+		size += 10
+	}
+	for ; size > 0 && plm_buffer_has(buffer, 8) == _true; size-- {
+		plm_buffer_skip(buffer, 8)
+	}
+}
+
+// findAudioSync returns the offset of the first frame sync in "buffer", as
+// found by "plm_audio_find_frame_sync".
+func findAudioSync(buffer *plm_buffer_t) uint64 {
+	for plm_buffer_has(buffer, 16) == _true {
+		first, second := plm_buffer_read(buffer, 8), plm_buffer_read(buffer, 8)
+		if first == 0xFF && second&0xF0 == 0xF0 && second&0x06 != 0 {
+			return plm_buffer_tell(buffer) - 2
+		}
+		// The second byte may start the sync.
+		buffer.Bit_index -= 8
+	}
+	return 0
+}
+
+// NewAudioDecoderFromFile creates a new audio decoder from a given file. The
+// file is not closed when the decoder is closed.
+func NewAudioDecoderFromFile(f *os.File) (*AudioDecoder, error) {
+	return newAudioDecoder(plm_buffer_create_with_file(stdio.OpenFrom(f), _false), nil)
+}
+
+// NewAudioDecoderFromFilename creates a new audio decoder from a given
+// filename.
+func NewAudioDecoderFromFilename(file string) (*AudioDecoder, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	return newAudioDecoder(plm_buffer_create_with_file(stdio.OpenFrom(f), _true), nil)
+}
+
+// NewAudioDecoderFromBytes creates a new audio decoder from a list of raw
+// bytes. "data" must not be modified while the decoder is in use.
+func NewAudioDecoderFromBytes(data []byte) (*AudioDecoder, error) {
+	if len(data) == 0 {
+		return nil, ExpectedHeader{}
+	}
+	return newAudioDecoder(plm_buffer_create_with_memory(&data[0], uint64(len(data)), _false), nil)
+}
+
+// NewAudioDecoderFromReader creates a new audio decoder that reads from "r".
+// Like "NewPlayerFromReader", it can only decode forward once if "r" can not
+// seek.
+//
+// If "r" is an "io.Closer", it is not closed when the decoder is closed.
+func NewAudioDecoderFromReader(r io.Reader) (*AudioDecoder, error) {
+	return newAudioDecoder(newReaderBuffer(r))
+}
+
+// Close closes the decoder and discards data.
+func (d *AudioDecoder) Close() {
+	defer d.lock()()
+	d.pending.Reset()
+	plm_audio_destroy(d.audio)
+	d.audio = nil
+}
+
+// lock locks the decoder and returns the function that unlocks it.
+func (d *AudioDecoder) lock() (unlock func()) {
+	d.mu.Lock()
+	return d.mu.Unlock
+}
+
+// SampleRate returns the sample rate of the audio stream in samples per
+// second.
+func (d *AudioDecoder) SampleRate() int {
+	defer d.lock()()
+	return int(plm_audio_get_samplerate(d.audio))
+}
+
+// BitRate returns the bitrate of the audio stream in bits per second.
+func (d *AudioDecoder) BitRate() int {
+	defer d.lock()()
+	return int(plm_audio_get_bitrate(d.audio)) * 1000
+}
+
+// ChannelMode returns how the channels of the audio stream are coded. Audio
+// is always decoded to two channels, and mono audio is played on both.
+func (d *AudioDecoder) ChannelMode() ChannelMode {
+	defer d.lock()()
+	return ChannelMode(d.audio.Mode)
+}
+
+// SampleFormat returns the format "Read" writes samples in. The default is
+// "SampleS16LE".
+func (d *AudioDecoder) SampleFormat() SampleFormat {
+	defer d.lock()()
+	return d.sampleFormat
+}
+
+// SetSampleFormat sets the format "Read" writes samples in. Audio that was
+// decoded but not read yet is discarded. Unknown formats are ignored.
+func (d *AudioDecoder) SetSampleFormat(format SampleFormat) {
+	defer d.lock()()
+	if format < SampleF32LE || format > SampleU8 {
+		return
+	}
+	d.sampleFormat = format
+	d.pending.Reset()
+}
+
+// NextSamples decodes and returns exactly one frame of audio. It returns
+// "io.EOF" once the audio has ended.
+//
+// Samples returned here are not read by "Read", so the two should not be
+// mixed.
+func (d *AudioDecoder) NextSamples() (*Samples, error) {
+	defer d.lock()()
+	s := plm_audio_decode(d.audio)
+	d.collectErrors()
+	if s == nil {
+		return nil, io.EOF
+	}
+	return newSamples(s), nil
+}
+
+// Read decodes audio as needed and reads it into "buf" as interleaved stereo
+// samples in the format set with "SetSampleFormat". It returns "io.EOF" once
+// the audio has ended, so it can be used as an "io.Reader" for Ebiten or Oto.
+func (d *AudioDecoder) Read(buf []byte) (n int, err error) {
+	defer d.lock()()
+	for d.pending.Len() < len(buf) {
+		s := plm_audio_decode(d.audio)
+		d.collectErrors()
+		if s == nil {
+			break
+		}
+		d.sampleFormat.writeSamples(&d.pending, s.Interleaved[:s.Count*2])
+	}
+	n, _ = d.pending.Read(buf)
+	if n == 0 && len(buf) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// Time is the presentation time of the next sample returned by "Read".
+func (d *AudioDecoder) Time() time.Duration {
+	defer d.lock()()
+	pending := d.pending.Len() / (d.sampleFormat.BytesPerSample() * 2)
+	return floatToSecs(plm_audio_get_time(d.audio) - float64(pending)/float64(plm_audio_get_samplerate(d.audio)))
+}
+
+// Duration is how long the entire audio is, estimated from its bitrate and
+// size. This is "UnknownDuration" if the decoder can not seek.
+func (d *AudioDecoder) Duration() time.Duration {
+	defer d.lock()()
+	if !d.seekable {
+		return UnknownDuration
+	}
+	return floatToSecs(float64(d.frameCount()*d.samplesPerFrame()) / float64(plm_audio_get_samplerate(d.audio)))
+}
+
+// frameSize is the average size of a frame in bytes. Frames are padded by a
+// byte now and then, so the stream averages out to its bitrate.
+func (d *AudioDecoder) frameSize() float64 {
+	bitrate := float64(plm_audio_get_bitrate(d.audio))
+	return bitrate * 125 * float64(d.samplesPerFrame()) / float64(plm_audio_get_samplerate(d.audio))
+}
+
+// samplesPerFrame is the number of samples per channel in each frame.
+func (d *AudioDecoder) samplesPerFrame() int {
+	return int(plm_audio_get_samples_per_frame(d.audio))
+}
+
+// frameCount is the number of frames in the stream, assuming the bitrate does
+// not change.
+func (d *AudioDecoder) frameCount() int {
+	size := float64(plm_buffer_get_size(d.audio.Buffer) - d.start)
+	// Padding may leave the stream a byte short of a whole number of frames.
+	return int((size + 1) / d.frameSize())
+}
+
+// Seekable returns true if the decoder is able to seek and rewind.
+func (d *AudioDecoder) Seekable() bool { return d.seekable }
+
+// Rewind moves to the beginning. This does nothing if the decoder can not
+// seek.
+func (d *AudioDecoder) Rewind() {
+	defer d.lock()()
+	if d.seekable {
+		d.pending.Reset()
+		plm_audio_rewind(d.audio)
+		plm_buffer_seek(d.audio.Buffer, d.start)
+		clearSynthesis(d.audio)
+	}
+}
+
+// clearSynthesis resets the synthesis filter, so audio decoded from the start
+// of the stream again is the same as the first time.
+func clearSynthesis(audio *plm_audio_t) {
+	audio.V = [2][1024]float32{}
+	audio.V_pos = 0
+	if audio.Layer3 != nil {
+		audio.Layer3.Overlap = [2][576]float32{}
+	}
+}
+
+// Seek moves to the specified time. The position in the stream is calculated
+// from the bitrate, so the stream is expected to have a constant bitrate, as
+// MP2 streams usually do. MP3 streams with a variable bitrate seek to roughly
+// the right time.
+//
+// If "exact" is false, this will seek to the start of the frame of audio
+// playing at that time. If "exact" is true, the samples of that frame before
+// the exact time are also dropped from what "Read" returns.
+//
+// Seek returns "ErrNotSeekable" if the decoder can not seek, and
+// "ErrSeekFailed" if the stream is empty.
+func (d *AudioDecoder) Seek(elapsed time.Duration, exact bool) error {
+	defer d.lock()()
+	if !d.seekable {
+		return ErrNotSeekable
+	}
+	audio := d.audio
+	frames := d.frameCount()
+	if frames == 0 {
+		return ErrSeekFailed
+	}
+	rate := float64(plm_audio_get_samplerate(audio))
+	spf := d.samplesPerFrame()
+	sample := int(elapsed.Seconds() * rate)
+	frame := sample / spf
+	if frame >= frames {
+		frame, sample = frames-1, (frames-1)*spf
+	}
+	first := frame - audioSeekPreroll
+	if audio.Layer == plm_audio_layer_iii {
+		// Layer III frames may use data from the bit reservoir in the frames
+		// before them, so enough frames are decoded to fill it.
+		first -= int(math.Ceil(plm_audio_layer3_max_reservoir / d.frameSize()))
+	}
+	if audio.Layer3 != nil {
+		audio.Layer3.Reservoir_size = 0
+	}
+	if first < 0 {
+		first = 0
+	}
+	// Start looking for the frame sync a little early, as frames start up to
+	// a byte before or after their average position.
+	pos := d.start + uint64(math.Floor(float64(first)*d.frameSize()))
+	if first > 0 {
+		pos -= 2
+	} else {
+		clearSynthesis(audio)
+	}
+	d.pending.Reset()
+	plm_buffer_seek(audio.Buffer, pos)
+	audio.Next_frame_data_size = 0
+	audio.Samples_decoded = int64(first * spf)
+	audio.Time = float64(audio.Samples_decoded) / rate
+	for i := first; i < frame; i++ {
+		plm_audio_decode(audio)
+	}
+	if exact {
+		if s := plm_audio_decode(audio); s != nil {
+			skip := sample - frame*spf
+			d.sampleFormat.writeSamples(&d.pending, s.Interleaved[skip*2:s.Count*2])
+		}
+	}
+	d.collectErrors()
+	return nil
+}
+
+// LastError returns the last "*DecodeError" found while decoding, or nil if the
+// data decoded so far was valid.
+func (d *AudioDecoder) LastError() error { defer d.lock()(); return d.lastErr }
+
+func (d *AudioDecoder) collectErrors() {
+	if d.audio.Error != plm_error_none {
+		d.lastErr = newDecodeError(StageAudio, &d.audio.Error, int64(plm_buffer_tell(d.audio.Buffer)), d.reader)
+	}
+}
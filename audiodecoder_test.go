@@ -0,0 +1,64 @@
+package mpg
+
+import (
+	"io"
+	"testing"
+)
+
+// testdata/test.mp2 is an MP2 stream of 10 frames of a 440 Hz tone at 44100 Hz
+// and 192 kbit/s.
+
+func TestAudioDecoder(t *testing.T) {
+	d, err := NewAudioDecoderFromBytes(readTestFile(t, "testdata/test.mp2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if d.SampleRate() != 44100 || d.BitRate() != 192000 {
+		t.Errorf("%d Hz at %d bit/s, want 44100 Hz at 192000 bit/s", d.SampleRate(), d.BitRate())
+	}
+	var left []float32
+	for {
+		s, err := d.NextSamples()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < len(s.Interleaved); i += 2 {
+			left = append(left, s.Interleaved[i])
+		}
+	}
+	if len(left) != 10*1152 {
+		t.Fatalf("decoded %d samples, want %d", len(left), 10*1152)
+	}
+	if f := dominantFrequency(left, 44100, 400, 500, 10); f != 440 {
+		t.Errorf("dominant frequency is %v Hz, want 440 Hz", f)
+	}
+}
+
+func TestAudioDecoderSetSampleFormat(t *testing.T) {
+	data := readTestFile(t, "testdata/test.mp2")
+	for _, format := range []SampleFormat{SampleF32LE, SampleS16LE, SampleS24LE, SampleS32LE, SampleU8} {
+		d, err := NewAudioDecoderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.SetSampleFormat(format)
+		// Unknown formats are ignored.
+		d.SetSampleFormat(SampleFormat(99))
+		d.SetSampleFormat(-1)
+		if d.SampleFormat() != format {
+			t.Fatalf("sample format is %v, want %v", d.SampleFormat(), format)
+		}
+		pcm, err := io.ReadAll(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 10 * 1152 * 2 * format.BytesPerSample(); len(pcm) != want {
+			t.Errorf("%v: read %d bytes, want %d", format, len(pcm), want)
+		}
+		d.Close()
+	}
+}
@@ -0,0 +1,491 @@
+package mpg
+
+import (
+	"math"
+)
+
+// AudioEncoderOptions configures an "AudioEncoder".
+type AudioEncoderOptions struct {
+	// SampleRate of the input in samples per second. Must be 32000, 44100 or
+	// 48000. Defaults to 44100.
+	SampleRate int
+	// BitRate is the bit rate of the encoded stream in bits per second. Must be
+	// one of the MPEG1 Layer II bit rates between 32000 and 384000. Defaults to
+	// 192000 for stereo and 96000 for mono.
+	BitRate int
+	// Mode selects how channels are coded. "ChannelModeMono" expects one
+	// channel of input, all other modes expect two interleaved channels.
+	// Defaults to "ChannelModeStereo".
+	Mode ChannelMode
+}
+
+// audioEncoderSNR is the signal to noise ratio in decibels every quantizer
+// class in "plm_audio_quant_tab" achieves.
+var audioEncoderSNR = [17]float64{
+	7.00, 11.00, 16.00, 20.84, 25.28, 31.59, 37.75, 43.84, 49.89,
+	55.93, 61.96, 67.98, 74.01, 80.03, 86.05, 92.01, 98.01,
+}
+
+// AudioEncoder encodes PCM samples into an MPEG1 Layer II (MP2) audio
+// elementary stream.
+//
+// The output can be written to a ".mp2" file, decoded by "AudioDecoder" or
+// multiplexed together with video using a "Muxer".
+type AudioEncoder struct {
+	opts            AudioEncoderOptions
+	channels        int
+	bitrateIndex    int
+	samplerateIndex int
+	sblimit         int
+	tab3            int
+	paddingRest     int
+	frames          int
+
+	// pcm holds the samples that do not yet fill a frame, per channel.
+	pcm     [2][]float32
+	x       [2][512]float64
+	xPos    int
+	window  [512]float64
+	matrix  [32][64]float64
+	subband [2][32][36]float64
+
+	scalefactor [2][32][3]int
+	scfsi       [2][32]int
+	allocation  [2][32]int
+	w           bitWriter
+}
+
+// NewAudioEncoder creates a new encoder with the given options. It returns
+// "ErrInvalidOptions" if the sample rate, bitrate or mode is not supported.
+func NewAudioEncoder(opts AudioEncoderOptions) (*AudioEncoder, error) {
+	if opts.SampleRate == 0 {
+		opts.SampleRate = 44100
+	}
+	enc := &AudioEncoder{opts: opts, channels: 2, samplerateIndex: -1, bitrateIndex: -1}
+	switch opts.Mode {
+	case ChannelModeMono:
+		enc.channels = 1
+	case ChannelModeStereo, ChannelModeJointStereo, ChannelModeDualChannel:
+	default:
+		return nil, ErrInvalidOptions
+	}
+	if opts.BitRate == 0 {
+		enc.opts.BitRate = 96000 * enc.channels
+	}
+	for i := 0; i < 3; i++ {
+		if int(plm_audio_sample_rate[i]) == opts.SampleRate {
+			enc.samplerateIndex = i
+		}
+	}
+	for i := 0; i < 14; i++ {
+		if int(plm_audio_bit_rate[i])*1000 == enc.opts.BitRate {
+			enc.bitrateIndex = i
+		}
+	}
+	if enc.samplerateIndex < 0 || enc.bitrateIndex < 0 {
+		return nil, ErrInvalidOptions
+	}
+
+	// Use the same allocation tables "plm_audio_decode_frame" selects.
+	tab1 := 1
+	if enc.channels == 1 {
+		tab1 = 0
+	}
+	tab2 := plm_audio_quant_lut_step_1[tab1][enc.bitrateIndex]
+	tab3 := int(quant_lut_step_2[tab2][enc.samplerateIndex])
+	enc.sblimit = tab3 & 63
+	enc.tab3 = tab3 >> 6
+
+	// The analysis window is the synthesis window the decoder uses, scaled
+	// so that a subband sample of 1 matches a full scale sample after
+	// "plm_audio_read_samples".
+	for i := range enc.window {
+		enc.window[i] = float64(plm_audio_synthesis_window[i]) / 524288
+	}
+	for k := 0; k < 32; k++ {
+		for i := 0; i < 64; i++ {
+			enc.matrix[k][i] = math.Cos(float64((2*k+1)*(i-16)) * math.Pi / 64)
+		}
+	}
+	return enc, nil
+}
+
+// SampleRate returns the sample rate of the encoded stream.
+func (enc *AudioEncoder) SampleRate() int { return enc.opts.SampleRate }
+
+// Channels returns the number of interleaved channels the encoder expects.
+func (enc *AudioEncoder) Channels() int { return enc.channels }
+
+// Frames returns the number of frames encoded so far. Every frame holds 1152
+// samples per channel.
+func (enc *AudioEncoder) Frames() int { return enc.frames }
+
+// EncodeFloat32 encodes interleaved samples in the range of -1 to 1 and returns
+// all frames that were completed. Samples that do not complete a frame are kept
+// until the next call or until "Flush".
+//
+// The returned slice is only valid until the next call to the encoder.
+func (enc *AudioEncoder) EncodeFloat32(samples []float32) []byte {
+	enc.w.reset()
+	for i := 0; i+enc.channels <= len(samples); i += enc.channels {
+		for ch := 0; ch < enc.channels; ch++ {
+			enc.pcm[ch] = append(enc.pcm[ch], samples[i+ch])
+		}
+		if len(enc.pcm[0]) == plm_audio_samples_per_frame {
+			enc.encodeFrame()
+		}
+	}
+	return enc.w.bytes()
+}
+
+// EncodeInt16 encodes interleaved signed 16 bit samples. See "EncodeFloat32".
+func (enc *AudioEncoder) EncodeInt16(samples []int16) []byte {
+	enc.w.reset()
+	for i := 0; i+enc.channels <= len(samples); i += enc.channels {
+		for ch := 0; ch < enc.channels; ch++ {
+			enc.pcm[ch] = append(enc.pcm[ch], float32(samples[i+ch])/32768)
+		}
+		if len(enc.pcm[0]) == plm_audio_samples_per_frame {
+			enc.encodeFrame()
+		}
+	}
+	return enc.w.bytes()
+}
+
+// Flush pads the remaining samples with silence and returns the last frame, if
+// there were any samples left.
+func (enc *AudioEncoder) Flush() []byte {
+	enc.w.reset()
+	if len(enc.pcm[0]) > 0 {
+		for ch := 0; ch < enc.channels; ch++ {
+			for len(enc.pcm[ch]) < plm_audio_samples_per_frame {
+				enc.pcm[ch] = append(enc.pcm[ch], 0)
+			}
+		}
+		enc.encodeFrame()
+	}
+	return enc.w.bytes()
+}
+
+// analyze runs the polyphase filterbank over the buffered samples of a frame.
+func (enc *AudioEncoder) analyze() {
+	var y [64]float64
+	for t := 0; t < 36; t++ {
+		pos := enc.xPos
+		for ch := 0; ch < enc.channels; ch++ {
+			x := &enc.x[ch]
+			// Shift in 32 new samples, newest first.
+			pos = enc.xPos
+			for i := 0; i < 32; i++ {
+				pos = (pos - 1) & 511
+				x[pos] = float64(enc.pcm[ch][t*32+i])
+			}
+			for i := 0; i < 64; i++ {
+				sum := 0.0
+				for j := 0; j < 8; j++ {
+					sum += enc.window[i+64*j] * x[(pos+i+64*j)&511]
+				}
+				y[i] = sum
+			}
+			for k := 0; k < 32; k++ {
+				sum := 0.0
+				for i := 0; i < 64; i++ {
+					sum += enc.matrix[k][i] * y[i]
+				}
+				enc.subband[ch][k][t] = sum
+			}
+		}
+		enc.xPos = pos
+	}
+	for ch := 0; ch < enc.channels; ch++ {
+		enc.pcm[ch] = enc.pcm[ch][:0]
+	}
+}
+
+// scalefactorIndex returns the smallest scalefactor that fits "peak".
+func scalefactorIndex(peak float64) int {
+	for i := 62; i > 0; i-- {
+		if 2*math.Pow(2, -float64(i)/3) >= peak {
+			return i
+		}
+	}
+	return 0
+}
+
+func scalefactorValue(index int) float64 {
+	return 2 * math.Pow(2, -float64(index)/3)
+}
+
+func (enc *AudioEncoder) encodeFrame() {
+	enc.analyze()
+
+	bound := 32
+	mode := int64(enc.opts.Mode)
+	switch enc.opts.Mode {
+	case ChannelModeMono:
+		bound = 0
+	case ChannelModeJointStereo:
+		perChannel := enc.opts.BitRate / 2
+		switch {
+		case perChannel < 48000:
+			bound = 4
+		case perChannel < 64000:
+			bound = 8
+		case perChannel < 96000:
+			bound = 12
+		default:
+			bound = 16
+		}
+		// Above the bound both channels share their samples. The decoder
+		// uses those of the first channel for both, so code the middle.
+		for sb := bound; sb < enc.sblimit; sb++ {
+			for t := 0; t < 36; t++ {
+				mid := (enc.subband[0][sb][t] + enc.subband[1][sb][t]) / 2
+				enc.subband[0][sb][t], enc.subband[1][sb][t] = mid, mid
+			}
+		}
+	}
+	if bound > enc.sblimit {
+		bound = enc.sblimit
+	}
+
+	// Scalefactors and how they are shared between the three parts.
+	for ch := 0; ch < enc.channels; ch++ {
+		for sb := 0; sb < enc.sblimit; sb++ {
+			sf := &enc.scalefactor[ch][sb]
+			for part := 0; part < 3; part++ {
+				peak := 0.0
+				for t := part * 12; t < part*12+12; t++ {
+					peak = math.Max(peak, math.Abs(enc.subband[ch][sb][t]))
+				}
+				sf[part] = scalefactorIndex(peak)
+			}
+			switch {
+			case abs(sf[0]-sf[1]) <= 1 && abs(sf[1]-sf[2]) <= 1 && abs(sf[0]-sf[2]) <= 1:
+				m := minInt(sf[0], minInt(sf[1], sf[2]))
+				*sf = [3]int{m, m, m}
+				enc.scfsi[ch][sb] = 2
+			case abs(sf[0]-sf[1]) <= 1:
+				m := minInt(sf[0], sf[1])
+				sf[0], sf[1] = m, m
+				enc.scfsi[ch][sb] = 1
+			case abs(sf[1]-sf[2]) <= 1:
+				m := minInt(sf[1], sf[2])
+				sf[1], sf[2] = m, m
+				enc.scfsi[ch][sb] = 3
+			default:
+				enc.scfsi[ch][sb] = 0
+			}
+		}
+	}
+	if mode == plm_audio_mode_joint_stereo {
+		for sb := bound; sb < enc.sblimit; sb++ {
+			enc.scalefactor[1][sb] = enc.scalefactor[0][sb]
+			enc.scfsi[1][sb] = enc.scfsi[0][sb]
+		}
+	}
+
+	// The frame size is rounded down, padding bytes make up for the
+	// remainder.
+	frameBytes := int(plm_audio_bit_rate[enc.bitrateIndex]) * 144000 / enc.opts.SampleRate
+	padding := 0
+	enc.paddingRest += int(plm_audio_bit_rate[enc.bitrateIndex]) * 144000 % enc.opts.SampleRate
+	if enc.paddingRest >= enc.opts.SampleRate {
+		enc.paddingRest -= enc.opts.SampleRate
+		padding = 1
+	}
+	frameBytes += padding
+
+	available := frameBytes*8 - 32
+	for sb := 0; sb < enc.sblimit; sb++ {
+		bits := int(plm_audio_quant_lut_step_3[enc.tab3][sb] >> 4)
+		if sb < bound {
+			available -= bits * enc.channels
+		} else {
+			available -= bits
+		}
+	}
+	enc.allocate(bound, available)
+
+	w := &enc.w
+	start := w.len()
+	w.write(0x7FF, 11)
+	w.write(uint32(plm_audio_mpeg_1), 2)
+	w.write(uint32(plm_audio_layer_ii), 2)
+	w.write(1, 1) // protection_bit, no CRC
+	w.write(uint32(enc.bitrateIndex+1), 4)
+	w.write(uint32(enc.samplerateIndex), 2)
+	w.write(uint32(padding), 1)
+	w.write(0, 1) // private_bit
+	w.write(uint32(mode), 2)
+	if mode == plm_audio_mode_joint_stereo {
+		w.write(uint32(bound/4-1), 2)
+	} else {
+		w.write(0, 2)
+	}
+	w.write(0, 1) // copyright
+	w.write(1, 1) // original
+	w.write(0, 2) // emphasis
+
+	for sb := 0; sb < enc.sblimit; sb++ {
+		bits := uint(plm_audio_quant_lut_step_3[enc.tab3][sb] >> 4)
+		channels := enc.channels
+		if sb >= bound {
+			channels = 1
+		}
+		for ch := 0; ch < channels; ch++ {
+			w.write(uint32(enc.allocation[ch][sb]), bits)
+		}
+	}
+	for sb := 0; sb < enc.sblimit; sb++ {
+		for ch := 0; ch < enc.channels; ch++ {
+			if enc.allocation[ch][sb] != 0 {
+				w.write(uint32(enc.scfsi[ch][sb]), 2)
+			}
+		}
+	}
+	for sb := 0; sb < enc.sblimit; sb++ {
+		for ch := 0; ch < enc.channels; ch++ {
+			if enc.allocation[ch][sb] == 0 {
+				continue
+			}
+			sf := enc.scalefactor[ch][sb]
+			switch enc.scfsi[ch][sb] {
+			case 0:
+				w.write(uint32(sf[0]), 6)
+				w.write(uint32(sf[1]), 6)
+				w.write(uint32(sf[2]), 6)
+			case 1:
+				w.write(uint32(sf[0]), 6)
+				w.write(uint32(sf[2]), 6)
+			case 2:
+				w.write(uint32(sf[0]), 6)
+			case 3:
+				w.write(uint32(sf[0]), 6)
+				w.write(uint32(sf[1]), 6)
+			}
+		}
+	}
+	for part := 0; part < 3; part++ {
+		for granule := 0; granule < 4; granule++ {
+			t := part*12 + granule*3
+			for sb := 0; sb < enc.sblimit; sb++ {
+				channels := enc.channels
+				if sb >= bound {
+					channels = 1
+				}
+				for ch := 0; ch < channels; ch++ {
+					enc.writeSamples(ch, sb, part, t)
+				}
+			}
+		}
+	}
+	for w.len()-start < frameBytes*8 {
+		w.write(0, 1)
+	}
+	enc.frames++
+}
+
+// quantizer returns the quantizer class of an allocation, or -1 if the
+// subband is not allocated.
+func (enc *AudioEncoder) quantizer(sb, allocation int) int {
+	tab4 := plm_audio_quant_lut_step_3[enc.tab3][sb]
+	return int(plm_audio_quant_lut_step_4[tab4&15][allocation]) - 1
+}
+
+// allocate distributes "available" bits between the subbands. It repeatedly
+// improves the subband with the largest noise to signal ratio.
+func (enc *AudioEncoder) allocate(bound, available int) {
+	enc.allocation = [2][32]int{}
+	scalefactorBits := [4]int{18, 12, 6, 12}
+	sampleBits := func(q int) int {
+		spec := plm_audio_quant_tab[q]
+		if spec.Group != 0 {
+			return 12 * int(spec.Bits)
+		}
+		return 36 * int(spec.Bits)
+	}
+	for {
+		bestCh, bestSb, bestCost := -1, -1, 0
+		bestNoise := math.Inf(-1)
+		for sb := 0; sb < enc.sblimit; sb++ {
+			tab4 := plm_audio_quant_lut_step_3[enc.tab3][sb]
+			maxAllocation := 1<<(tab4>>4) - 1
+			channels := enc.channels
+			if sb >= bound {
+				channels = 1
+			}
+			for ch := 0; ch < channels; ch++ {
+				current := enc.allocation[ch][sb]
+				if current == maxAllocation {
+					continue
+				}
+				// The signal level in decibels relative to full scale.
+				sf := enc.scalefactor[ch][sb]
+				level := 20 * math.Log10(scalefactorValue(minInt(sf[0], minInt(sf[1], sf[2]))))
+				if level < -100 {
+					continue
+				}
+				noise := level
+				cost := sampleBits(enc.quantizer(sb, current+1))
+				if current != 0 {
+					noise -= audioEncoderSNR[enc.quantizer(sb, current)]
+					cost -= sampleBits(enc.quantizer(sb, current))
+				} else {
+					cost += 2 + scalefactorBits[enc.scfsi[ch][sb]]
+					if sb >= bound && enc.channels == 2 {
+						cost += 2 + scalefactorBits[enc.scfsi[1][sb]]
+					}
+				}
+				if cost <= available && noise > bestNoise {
+					bestCh, bestSb, bestCost, bestNoise = ch, sb, cost, noise
+				}
+			}
+		}
+		if bestCh < 0 {
+			break
+		}
+		enc.allocation[bestCh][bestSb]++
+		if bestSb >= bound {
+			enc.allocation[1][bestSb] = enc.allocation[0][bestSb]
+		}
+		available -= bestCost
+	}
+}
+
+// writeSamples quantizes and writes three consecutive samples of a subband.
+func (enc *AudioEncoder) writeSamples(ch, sb, part, t int) {
+	if enc.allocation[ch][sb] == 0 {
+		return
+	}
+	spec := plm_audio_quant_tab[enc.quantizer(sb, enc.allocation[ch][sb])]
+	levels := int(spec.Levels)
+	sf := scalefactorValue(enc.scalefactor[ch][sb][part])
+	mid := (levels+1)>>1 - 1
+	var codes [3]int
+	for i := range codes {
+		// The decoder reconstructs (mid - code) * 2 / (levels + 1) times the
+		// scalefactor.
+		v := int(math.Round(enc.subband[ch][sb][t+i] / sf * float64(levels+1) / 2))
+		if v > mid {
+			v = mid
+		} else if v < -mid {
+			v = -mid
+		}
+		codes[i] = mid - v
+	}
+	if spec.Group != 0 {
+		enc.w.write(uint32(codes[0]+levels*(codes[1]+levels*codes[2])), uint(spec.Bits))
+	} else {
+		for _, code := range codes {
+			enc.w.write(uint32(code), uint(spec.Bits))
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package mpg
+
+import (
+	"math"
+	"testing"
+)
+
+// decodeTestAudio decodes all samples of an audio stream.
+func decodeTestAudio(t testing.TB, data []byte) (interleaved []float32) {
+	t.Helper()
+	d, err := NewAudioDecoderFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		s, err := d.NextSamples()
+		if err != nil {
+			break
+		}
+		interleaved = append(interleaved, s.Interleaved...)
+	}
+	if err := d.LastError(); err != nil {
+		t.Fatalf("decoding failed: %v", err)
+	}
+	return interleaved
+}
+
+// toneSNR returns the signal to noise ratio in decibels of the channel "ch" of
+// the interleaved stereo samples "got" compared to "want". Encoder and decoder
+// delay the signal, so the best of all delays up to one frame is returned.
+func toneSNR(got, want []float32, ch int) float64 {
+	best := math.Inf(-1)
+	for lag := 0; lag <= 1152; lag++ {
+		var signal, noise float64
+		// Skip the first frames, which are faded in by the filter banks.
+		for i := 2048; i < len(want)/2 && i+lag < len(got)/2; i++ {
+			s := float64(want[i*2+ch])
+			e := float64(got[(i+lag)*2+ch]) - s
+			signal += s * s
+			noise += e * e
+		}
+		if snr := 10 * math.Log10(signal/noise); snr > best {
+			best = snr
+		}
+	}
+	return best
+}
+
+func TestAudioEncoderRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts AudioEncoderOptions
+	}{
+		{"stereo", AudioEncoderOptions{SampleRate: 44100, BitRate: 192000}},
+		{"joint stereo", AudioEncoderOptions{SampleRate: 48000, BitRate: 128000, Mode: ChannelModeJointStereo}},
+		{"mono", AudioEncoderOptions{SampleRate: 32000, BitRate: 96000, Mode: ChannelModeMono}},
+	}
+	for _, tt := range tests {
+		enc, err := NewAudioEncoder(tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := testTone(440, tt.opts.SampleRate, 0, 20*1152)
+		input := want
+		if tt.opts.Mode == ChannelModeMono {
+			// Mono input has a single channel, which is decoded to both.
+			input = make([]float32, 0, len(want)/2)
+			for i := 0; i < len(want); i += 2 {
+				input = append(input, want[i])
+				want[i+1] = want[i]
+			}
+		}
+		got := decodeTestAudio(t, append(enc.EncodeFloat32(input), enc.Flush()...))
+		if len(got) < len(want) {
+			t.Errorf("%s: decoded %d samples, want at least %d", tt.name, len(got)/2, len(want)/2)
+		}
+		for ch := 0; ch < 2; ch++ {
+			if snr := toneSNR(got, want, ch); snr < 40 {
+				t.Errorf("%s: SNR of channel %d is %.1f dB, want at least 40 dB", tt.name, ch, snr)
+			}
+		}
+	}
+}
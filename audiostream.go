@@ -0,0 +1,94 @@
+package mpg
+
+// AudioStream is the index of the audio stream that is decoded, ranging from 0
+// to "NumAudioStreams" - 1.
+func (plm *Player) AudioStream() int {
+	defer plm.lock()()
+	return int(plm.plm.Audio_stream_index)
+}
+
+// SetAudioStream switches to the audio stream at "index", such as another
+// language track. Playback continues from the current time without rewinding,
+// and the audio buffer is cleared so audio of the previous stream is not
+// played. It returns "ErrAudioStream" if there is no stream at "index".
+func (plm *Player) SetAudioStream(index int) error {
+	defer plm.lock()()
+	p := plm.plm
+	if index < 0 || index >= int(plm_get_num_audio_streams(p)) {
+		return ErrAudioStream
+	}
+	if int64(index) == p.Audio_stream_index {
+		return nil
+	}
+	plm_set_audio_stream(p, int64(index))
+	plm.ClearAudioBuffer()
+	if p.Audio_decoder != nil {
+		plm.resyncAudio()
+	}
+	plm.collectErrors()
+	return nil
+}
+
+// resyncAudio discards audio of the previous stream buffered by the decoder
+// and moves the decoder to the current time in the new stream.
+func (plm *Player) resyncAudio() {
+	p, audio := plm.plm, plm.plm.Audio_decoder
+	// Packets of the new stream are read from where the demuxer is, which is
+	// past the data of the previous stream that was buffered but not decoded.
+	time := plm_audio_get_time(audio)
+	if audio.Has_header == _true {
+		remaining := audio.Buffer.Length<<3 - audio.Buffer.Bit_index
+		time += float64(remaining) / (float64(plm_audio_get_bitrate(audio)) * 1000)
+	}
+	plm_audio_rewind(audio)
+	// The new stream may use another bitrate or sample rate.
+	audio.Has_header = _false
+	if p.Audio_packet_type == 0 {
+		return
+	}
+	if plm.seekable {
+		if start, ok := plm.bufferAudio(); ok {
+			time = start
+		}
+	}
+	plm_audio_has_header(audio)
+	plm_audio_set_time(audio, time)
+}
+
+// bufferAudio looks back in the source for packets of the current audio
+// stream between the current time and where the demuxer is, and buffers them
+// for the audio decoder. It returns the time of the first packet buffered.
+func (plm *Player) bufferAudio() (start float64, ok bool) {
+	p := plm.plm
+	demux := p.Demux
+	saved := *demux
+	savedTs := plm_demux_ts_copy(demux.Ts)
+	resume := plm_buffer_tell(demux.Buffer)
+	startType := p.Video_packet_type
+	if startType == 0 {
+		startType = p.Audio_packet_type
+	}
+	startTime := plm_demux_get_start_time(demux, startType)
+	packet := plm_demux_seek(demux, p.Time, p.Audio_packet_type, _false)
+	// A packet of a transport stream is only complete once the next one of its
+	// stream was read, so it may end exactly where the demuxer is.
+	for packet != nil && (plm_buffer_tell(demux.Buffer) < resume || demux.Ts != nil && plm_buffer_tell(demux.Buffer) == resume) {
+		if packet.Type == p.Audio_packet_type {
+			if !ok && packet.Pts != -1 && packet.Pts-startTime >= p.Time {
+				start, ok = packet.Pts-startTime, true
+			}
+			if ok {
+				plm_buffer_write(p.Audio_buffer, packet.Data, packet.Length)
+			}
+		}
+		packet = plm_demux_decode(demux)
+	}
+	// Return the demuxer to where it was, so video is not read twice.
+	plm_demux_buffer_seek(demux, resume)
+	demux.Current_packet = saved.Current_packet
+	demux.Next_packet = saved.Next_packet
+	demux.Start_code = saved.Start_code
+	demux.Last_decoded_pts = saved.Last_decoded_pts
+	plm_demux_ts_restore(demux, savedTs)
+	return
+}
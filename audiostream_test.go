@@ -0,0 +1,88 @@
+package mpg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+// leftChannel returns the left channel of the F32LE samples in "plm"'s audio
+// buffer, and empties the buffer.
+func leftChannel(plm *Player) []float32 {
+	samples := make([]float32, plm.audioBuffer.Len()/4)
+	binary.Read(plm.audioBuffer, binary.LittleEndian, samples)
+	left := make([]float32, len(samples)/2)
+	for i := range left {
+		left[i] = samples[i*2]
+	}
+	return left
+}
+
+// TestSetAudioStream switches between the audio streams of the test file,
+// which play a tone of 440 Hz and of 880 Hz on their left channel.
+func TestSetAudioStream(t *testing.T) {
+	data := readTestFile(t, testFile)
+	const leadTime = time.Second
+	for _, c := range []struct {
+		name string
+		open func() (*Player, error)
+		// lag is how far after the current time the new stream may
+		// continue. A player that can not seek can not go back for the
+		// audio it already decoded ahead of the video.
+		lag time.Duration
+	}{
+		{"NewPlayerFromBytes", func() (*Player, error) { return NewPlayerFromBytes(data) }, 0},
+		{"NewPlayerFromReader", func() (*Player, error) {
+			return NewPlayerFromReader(struct{ io.Reader }{bytes.NewReader(data)})
+		}, leadTime},
+	} {
+		plm, err := c.open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := plm.NumAudioStreams(); n != 2 {
+			t.Fatalf("%s: file has %d audio streams, want 2", c.name, n)
+		}
+		plm.SetSampleFormat(SampleF32LE)
+		plm.SetAudioLeadTime(leadTime)
+		plm.Decode(time.Second * 3 / 4)
+		if f := dominantFrequency(leftChannel(plm), plm.SampleRate(), 200, 2000, 2); f != 440 {
+			t.Errorf("%s: stream 0 plays %v Hz, want 440 Hz", c.name, f)
+		}
+
+		now := plm.Time()
+		if err := plm.SetAudioStream(1); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if plm.AudioStream() != 1 || plm.audioBuffer.Len() != 0 {
+			t.Errorf("%s: stream is %d with %d bytes buffered, want 1 with the buffer cleared",
+				c.name, plm.AudioStream(), plm.audioBuffer.Len())
+		}
+		if plm.Time() != now {
+			t.Errorf("%s: switching streams moved from %v to %v", c.name, now, plm.Time())
+		}
+		// Audio continues at the current time, not from the start of the
+		// stream.
+		s, err := plm.NextSamples()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		frame := time.Duration(plm_audio_samples_per_frame) * time.Second / time.Duration(plm.SampleRate())
+		if s.Time < now-frame || s.Time > now+c.lag+2*frame {
+			t.Errorf("%s: audio continues at %v, want %v", c.name, s.Time, now)
+		}
+		plm.Decode(time.Second / 2)
+		if f := dominantFrequency(leftChannel(plm), plm.SampleRate(), 200, 2000, 2); f != 880 {
+			t.Errorf("%s: stream 1 plays %v Hz, want 880 Hz", c.name, f)
+		}
+
+		if err := plm.SetAudioStream(2); err != ErrAudioStream {
+			t.Errorf("%s: switching to a missing stream returned %v, want ErrAudioStream", c.name, err)
+		}
+		if err := plm.SetAudioStream(-1); err != ErrAudioStream {
+			t.Errorf("%s: switching to a missing stream returned %v, want ErrAudioStream", c.name, err)
+		}
+	}
+}
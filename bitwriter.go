@@ -0,0 +1,101 @@
+package mpg
+
+import "unsafe"
+
+// bitWriter packs values most significant bit first, the same order
+// "plm_buffer_read" consumes them in.
+type bitWriter struct {
+	data  []byte
+	cur   uint64
+	nbits uint
+}
+
+// write appends the lowest "n" bits of "v". "n" must not exceed 32.
+func (w *bitWriter) write(v uint32, n uint) {
+	w.cur = w.cur<<n | uint64(v)&(1<<n-1)
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.nbits -= 8
+		w.data = append(w.data, byte(w.cur>>w.nbits))
+	}
+}
+
+// writeBool appends a single bit.
+func (w *bitWriter) writeBool(b bool) {
+	if b {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+}
+
+// writeCode appends a variable length code.
+func (w *bitWriter) writeCode(c vlcCode) { w.write(c.bits, c.length) }
+
+// align pads the stream with zero bits up to the next byte boundary.
+func (w *bitWriter) align() {
+	if w.nbits > 0 {
+		w.write(0, 8-w.nbits)
+	}
+}
+
+// startCode aligns the stream and writes the start code "code".
+func (w *bitWriter) startCode(code byte) {
+	w.align()
+	w.data = append(w.data, 0x00, 0x00, 0x01, code)
+}
+
+// len returns the number of bits written so far.
+func (w *bitWriter) len() int { return len(w.data)*8 + int(w.nbits) }
+
+// bytes aligns the stream and returns everything written so far.
+func (w *bitWriter) bytes() []byte {
+	w.align()
+	return w.data
+}
+
+// reset discards all written data but keeps the allocated memory.
+func (w *bitWriter) reset() {
+	w.data = w.data[:0]
+	w.cur = 0
+	w.nbits = 0
+}
+
+// vlcCode is a single variable length code.
+type vlcCode struct {
+	bits   uint32
+	length uint
+}
+
+// vlcEncodeTable walks a decoding tree as used by "plm_buffer_read_vlc" and
+// returns the shortest code for every value it can produce. This way the
+// encoders share the exact same tables as the decoders.
+func vlcEncodeTable(table []plm_vlc_t) map[int16]vlcCode {
+	codes := make(map[int16]vlcCode)
+	var walk func(index int16, c vlcCode)
+	walk = func(index int16, c vlcCode) {
+		for bit := int16(0); bit < 2; bit++ {
+			state := table[index+bit]
+			next := vlcCode{c.bits<<1 | uint32(bit), c.length + 1}
+			switch {
+			case state.Index > 0:
+				walk(state.Index, next)
+			case state.Index == 0:
+				if old, ok := codes[state.Value]; !ok || old.length > next.length {
+					codes[state.Value] = next
+				}
+			}
+		}
+	}
+	walk(0, vlcCode{})
+	return codes
+}
+
+// vlcUintEncodeTable is "vlcEncodeTable" for "plm_vlc_uint_t" tables.
+func vlcUintEncodeTable(table []plm_vlc_uint_t) map[uint16]vlcCode {
+	codes := make(map[uint16]vlcCode)
+	for v, c := range vlcEncodeTable(unsafe.Slice((*plm_vlc_t)(unsafe.Pointer(&table[0])), len(table))) {
+		codes[uint16(v)] = c
+	}
+	return codes
+}
@@ -0,0 +1,73 @@
+package mpg
+
+// ChannelMode is how the channels of an MPEG audio stream are coded.
+type ChannelMode int
+
+const (
+	// ChannelModeStereo is a left and a right channel coded separately.
+	ChannelModeStereo ChannelMode = iota
+	// ChannelModeJointStereo is a left and a right channel that share some of
+	// their data.
+	ChannelModeJointStereo
+	// ChannelModeDualChannel is two independent channels, such as two
+	// languages in a bilingual broadcast.
+	ChannelModeDualChannel
+	// ChannelModeMono is a single channel.
+	ChannelModeMono
+)
+
+func (mode ChannelMode) String() string {
+	switch mode {
+	case ChannelModeStereo:
+		return "stereo"
+	case ChannelModeJointStereo:
+		return "joint stereo"
+	case ChannelModeDualChannel:
+		return "dual channel"
+	case ChannelModeMono:
+		return "mono"
+	}
+	return "unknown"
+}
+
+// DualChannel selects which channels of a "ChannelModeDualChannel" stream are
+// played.
+type DualChannel int
+
+const (
+	// DualChannelBoth plays the first channel on the left and the second
+	// channel on the right.
+	DualChannelBoth DualChannel = iota
+	// DualChannelFirst plays only the first channel, on both sides.
+	DualChannelFirst
+	// DualChannelSecond plays only the second channel, on both sides.
+	DualChannelSecond
+)
+
+// mapChannels selects and downmixes the interleaved stereo samples "in" for
+// the output channels. It returns "in" if nothing needs to change.
+func (plm *Player) mapChannels(in []float32, mode ChannelMode) []float32 {
+	pick := -1
+	if mode == ChannelModeDualChannel && plm.dualChannel == DualChannelFirst {
+		pick = 0
+	} else if mode == ChannelModeDualChannel && plm.dualChannel == DualChannelSecond {
+		pick = 1
+	}
+	if plm.outputChannels == 2 && pick < 0 {
+		return in
+	}
+	out := plm.mixed[:0]
+	for i := 0; i+1 < len(in); i += 2 {
+		s := (in[i] + in[i+1]) / 2
+		if pick >= 0 {
+			s = in[i+pick]
+		}
+		if plm.outputChannels == 2 {
+			out = append(out, s, s)
+		} else {
+			out = append(out, s)
+		}
+	}
+	plm.mixed = out
+	return out
+}
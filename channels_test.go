@@ -0,0 +1,99 @@
+package mpg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMapChannels(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Interleaved left and right samples that differ.
+	in := []float32{0.5, -0.25, 1, 0, -1, 0.75}
+	for _, c := range []struct {
+		channels int
+		mode     ChannelMode
+		dual     DualChannel
+		want     []float32
+	}{
+		{2, ChannelModeStereo, DualChannelBoth, in},
+		{2, ChannelModeStereo, DualChannelFirst, in},
+		{2, ChannelModeDualChannel, DualChannelBoth, in},
+		{2, ChannelModeDualChannel, DualChannelFirst, []float32{0.5, 0.5, 1, 1, -1, -1}},
+		{2, ChannelModeDualChannel, DualChannelSecond, []float32{-0.25, -0.25, 0, 0, 0.75, 0.75}},
+		{1, ChannelModeStereo, DualChannelBoth, []float32{0.125, 0.5, -0.125}},
+		{1, ChannelModeJointStereo, DualChannelSecond, []float32{0.125, 0.5, -0.125}},
+		{1, ChannelModeDualChannel, DualChannelBoth, []float32{0.125, 0.5, -0.125}},
+		{1, ChannelModeDualChannel, DualChannelFirst, []float32{0.5, 1, -1}},
+		{1, ChannelModeDualChannel, DualChannelSecond, []float32{-0.25, 0, 0.75}},
+	} {
+		plm.SetOutputChannels(c.channels)
+		plm.SetDualChannel(c.dual)
+		// Unknown values are ignored.
+		plm.SetOutputChannels(3)
+		plm.SetDualChannel(DualChannelSecond + 1)
+		if plm.OutputChannels() != c.channels || plm.DualChannel() != c.dual {
+			t.Fatalf("output channels and dual channel are %d and %d, want %d and %d",
+				plm.OutputChannels(), plm.DualChannel(), c.channels, c.dual)
+		}
+		got := plm.mapChannels(in, c.mode)
+		if len(got) != len(c.want) {
+			t.Errorf("%d channels of %v with %d: got %v, want %v", c.channels, c.mode, c.dual, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%d channels of %v with %d: got %v, want %v", c.channels, c.mode, c.dual, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestPlayerMonoDownmix(t *testing.T) {
+	data := readTestFile(t, testFile)
+	decode := func(channels int, dual DualChannel) []float32 {
+		plm, err := NewPlayerFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plm.Channels() != 2 || plm.ChannelMode() != ChannelModeStereo {
+			t.Fatalf("audio has %d channels in %v mode, want 2 in stereo mode", plm.Channels(), plm.ChannelMode())
+		}
+		plm.SetSampleFormat(SampleF32LE)
+		plm.SetOutputChannels(channels)
+		plm.SetDualChannel(dual)
+		plm.SetAudioLeadTime(time.Second)
+		plm.Decode(time.Second / 2)
+		samples := make([]float32, plm.audioBuffer.Len()/4)
+		binary.Read(bytes.NewReader(plm.audioBuffer.Bytes()), binary.LittleEndian, samples)
+		return samples
+	}
+	stereo, mono := decode(2, DualChannelBoth), decode(1, DualChannelBoth)
+	if len(stereo) == 0 || len(mono)*2 != len(stereo) {
+		t.Fatalf("decoded %d mono and %d stereo samples", len(mono), len(stereo))
+	}
+	distinct := false
+	for i, s := range mono {
+		left, right := stereo[i*2], stereo[i*2+1]
+		if s != (left+right)/2 {
+			t.Fatalf("mono sample %d is %v, want the average of %v and %v", i, s, left, right)
+		}
+		distinct = distinct || math.Abs(float64(left-right)) > 0.1
+	}
+	if !distinct {
+		t.Error("left and right channel of the test audio are the same")
+	}
+	// Only dual channel streams are affected by "SetDualChannel".
+	first := decode(2, DualChannelFirst)
+	for i := range stereo {
+		if first[i] != stereo[i] {
+			t.Fatalf("SetDualChannel changed sample %d of a stereo stream", i)
+		}
+	}
+}
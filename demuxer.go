@@ -0,0 +1,286 @@
+package mpg
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/gotranspile/cxgo/runtime/stdio"
+)
+
+// NoTimestamp is the time stamp of a packet that does not have one.
+const NoTimestamp time.Duration = -1
+
+// StreamType is the kind of data a stream in an MPG file holds, as given by
+// its stream ID.
+type StreamType int
+
+const (
+	// StreamVideo is MPEG1 or MPEG2 video, with stream IDs 0xE0 to 0xEF.
+	StreamVideo StreamType = iota
+	// StreamAudio is MPEG audio of any layer, with stream IDs 0xC0 to 0xDF.
+	StreamAudio
+	// StreamPrivate is data only some players understand, such as subtitles,
+	// with stream IDs 0xBD and 0xBF. Any other stream ID is reported as
+	// private as well.
+	StreamPrivate
+	// StreamPadding fills the stream up to its mux rate, with stream ID 0xBE.
+	StreamPadding
+)
+
+func (t StreamType) String() string {
+	switch t {
+	case StreamVideo:
+		return "video"
+	case StreamAudio:
+		return "audio"
+	case StreamPrivate:
+		return "private"
+	case StreamPadding:
+		return "padding"
+	}
+	return "unknown"
+}
+
+// StreamTypeOf returns the kind of stream with the stream ID "id".
+func StreamTypeOf(id byte) StreamType {
+	switch {
+	case id >= 0xE0 && id <= 0xEF:
+		return StreamVideo
+	case id >= 0xC0 && id <= 0xDF:
+		return StreamAudio
+	case id == byte(plm_demux_packet_padding):
+		return StreamPadding
+	}
+	return StreamPrivate
+}
+
+// Packet is a piece of one stream in an MPG file.
+type Packet struct {
+	// Type is the kind of stream the packet belongs to.
+	Type StreamType
+	// StreamID identifies the stream, such as 0xE0 for the first video stream
+	// and 0xC0 for the first audio stream.
+	StreamID byte
+	// PTS is the presentation time stamp of the first video frame or audio
+	// frame that starts in the packet, as written in the packet. It is
+	// "NoTimestamp" if the packet has none.
+	PTS time.Duration
+	// DTS is the decoding time stamp, which differs from "PTS" for video frames
+	// that are decoded before they are shown. It is the same as "PTS" if the
+	// packet only has a presentation time stamp.
+	DTS time.Duration
+	// Data is the payload of the packet. It points to memory owned by the
+	// demuxer, so it is only valid until the next packet is read or the
+	// demuxer seeks.
+	Data []byte
+}
+
+func newPacket(p *plm_packet_t) Packet {
+	return Packet{
+		Type:     StreamTypeOf(byte(p.Type)),
+		StreamID: byte(p.Type),
+		PTS:      packetTime(p.Pts),
+		DTS:      packetTime(p.Dts),
+		Data:     unsafe.Slice(p.Data, p.Length),
+	}
+}
+
+func packetTime(t float64) time.Duration {
+	if t == -1 {
+		return NoTimestamp
+	}
+	return floatToSecs(t)
+}
+
+// Demuxer splits an MPG file into the packets of its streams without decoding
+// them, such as to count them or to copy a stream to another file. The packets
+// of a transport stream are reassembled from its 188 byte transport packets,
+// and only those of the video and audio streams of its first program are read.
+//
+// A demuxer is safe to use from multiple goroutines.
+type Demuxer struct {
+	mu       sync.Mutex
+	demux    *plm_demux_t
+	seekable bool
+	reader   *readerSource
+	lastErr  error
+	// timesID is the stream the demuxer's cached start time and duration
+	// were found for.
+	timesID int64
+}
+
+func newDemuxer(buffer *plm_buffer_t, src *readerSource) (*Demuxer, error) {
+	demux := plm_demux_create(buffer, _true)
+	if plm_demux_has_headers(demux) != _true {
+		plm_demux_destroy(demux)
+		return nil, ExpectedHeader{}
+	}
+	return &Demuxer{demux: demux, seekable: src == nil, reader: src}, nil
+}
+
+// NewDemuxerFromFile creates a new demuxer from a given file. The file is not
+// closed when the demuxer is closed.
+func NewDemuxerFromFile(f *os.File) (*Demuxer, error) {
+	return newDemuxer(plm_buffer_create_with_file(stdio.OpenFrom(f), _false), nil)
+}
+
+// NewDemuxerFromFilename creates a new demuxer from a given filename.
+func NewDemuxerFromFilename(file string) (*Demuxer, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	return newDemuxer(plm_buffer_create_with_file(stdio.OpenFrom(f), _true), nil)
+}
+
+// NewDemuxerFromBytes creates a new demuxer from a list of raw bytes. "data"
+// must not be modified while the demuxer is in use.
+func NewDemuxerFromBytes(data []byte) (*Demuxer, error) {
+	if len(data) == 0 {
+		return nil, ExpectedHeader{}
+	}
+	return newDemuxer(plm_buffer_create_with_memory(&data[0], uint64(len(data)), _false), nil)
+}
+
+// NewDemuxerFromReader creates a new demuxer that reads from "r". Like
+// "NewPlayerFromReader", it can only read forward once if "r" can not seek.
+//
+// If "r" is an "io.Closer", it is not closed when the demuxer is closed.
+func NewDemuxerFromReader(r io.Reader) (*Demuxer, error) {
+	return newDemuxer(newReaderBuffer(r))
+}
+
+// Close closes the demuxer and discards data.
+func (d *Demuxer) Close() {
+	defer d.lock()()
+	plm_demux_destroy(d.demux)
+	d.demux = nil
+}
+
+// lock locks the demuxer and returns the function that unlocks it.
+func (d *Demuxer) lock() (unlock func()) {
+	d.mu.Lock()
+	return d.mu.Unlock
+}
+
+// NumVideoStreams returns the number of video streams listed in the system
+// header, or in the program map table of a transport stream.
+func (d *Demuxer) NumVideoStreams() int {
+	defer d.lock()()
+	return int(plm_demux_get_num_video_streams(d.demux))
+}
+
+// NumAudioStreams returns the number of audio streams listed in the system
+// header, or in the program map table of a transport stream.
+func (d *Demuxer) NumAudioStreams() int {
+	defer d.lock()()
+	return int(plm_demux_get_num_audio_streams(d.demux))
+}
+
+// StreamIDs returns the IDs of the streams listed in the system header. The
+// streams of a transport stream have no IDs of their own, so they are numbered
+// in the order of the program map table, from 0xE0 for video and from 0xC0 for
+// audio.
+func (d *Demuxer) StreamIDs() []byte {
+	defer d.lock()()
+	return append([]byte(nil), d.demux.Stream_ids[:d.demux.Num_stream_ids]...)
+}
+
+// NextPacket reads the next packet of any stream. It returns "io.EOF" once the
+// file has ended.
+func (d *Demuxer) NextPacket() (Packet, error) {
+	defer d.lock()()
+	p := plm_demux_decode(d.demux)
+	d.collectErrors()
+	if p == nil {
+		return Packet{}, io.EOF
+	}
+	return newPacket(p), nil
+}
+
+// Seekable returns true if the demuxer is able to seek and rewind.
+func (d *Demuxer) Seekable() bool { return d.seekable }
+
+// Rewind moves to the beginning. This does nothing if the demuxer can not
+// seek.
+func (d *Demuxer) Rewind() {
+	defer d.lock()()
+	if d.seekable {
+		plm_demux_rewind(d.demux)
+	}
+}
+
+// StartTime is the presentation time stamp of the first packet of the stream
+// "streamID". It is "NoTimestamp" if the demuxer can not seek or the stream has
+// no time stamps.
+func (d *Demuxer) StartTime(streamID byte) time.Duration {
+	defer d.lock()()
+	if !d.seekable {
+		return NoTimestamp
+	}
+	d.useTimesOf(streamID)
+	return packetTime(plm_demux_get_start_time(d.demux, int64(streamID)))
+}
+
+// Duration is the time from the first to the last presentation time stamp of
+// the stream "streamID". This is "UnknownDuration" if the demuxer can not seek
+// or the stream has no time stamps.
+func (d *Demuxer) Duration(streamID byte) time.Duration {
+	defer d.lock()()
+	if !d.seekable {
+		return UnknownDuration
+	}
+	d.useTimesOf(streamID)
+	if plm_demux_get_start_time(d.demux, int64(streamID)) == -1 {
+		return UnknownDuration
+	}
+	return floatToSecs(plm_demux_get_duration(d.demux, int64(streamID)))
+}
+
+// useTimesOf makes the demuxer find its start time and duration from the
+// stream "streamID", as it only keeps them for one stream.
+func (d *Demuxer) useTimesOf(streamID byte) {
+	if d.timesID != int64(streamID) {
+		d.timesID = int64(streamID)
+		d.demux.Start_time = -1
+		d.demux.Duration = -1
+	}
+}
+
+// Seek moves to the time "elapsed" after the start of the stream "streamID",
+// and returns the first packet of that stream at that time. Seeking a video
+// stream finds a packet with an intra frame, so decoding can start from it.
+// "NextPacket" continues after the returned packet.
+//
+// Seek returns "ErrNotSeekable" if the demuxer can not seek, and
+// "ErrSeekFailed" if no packet was found.
+func (d *Demuxer) Seek(elapsed time.Duration, streamID byte) (Packet, error) {
+	defer d.lock()()
+	if !d.seekable {
+		return Packet{}, ErrNotSeekable
+	}
+	d.useTimesOf(streamID)
+	if plm_demux_get_start_time(d.demux, int64(streamID)) == -1 {
+		return Packet{}, ErrSeekFailed
+	}
+	forceIntra := boolToInt(StreamTypeOf(streamID) == StreamVideo)
+	p := plm_demux_seek(d.demux, elapsed.Seconds(), int64(streamID), forceIntra)
+	d.collectErrors()
+	if p == nil {
+		return Packet{}, ErrSeekFailed
+	}
+	return newPacket(p), nil
+}
+
+// LastError returns the last "*DecodeError" found while demuxing, or nil if
+// the data read so far was valid.
+func (d *Demuxer) LastError() error { defer d.lock()(); return d.lastErr }
+
+func (d *Demuxer) collectErrors() {
+	if d.demux.Error != plm_error_none {
+		d.lastErr = newDecodeError(StageDemux, &d.demux.Error, int64(d.demux.Error_offset), d.reader)
+	}
+}
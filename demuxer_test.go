@@ -0,0 +1,200 @@
+package mpg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// appendTestTimestamp appends a 33 bit time stamp in 90 kHz units as it is
+// coded in a PES header, after the 4 bit "prefix".
+func appendTestTimestamp(b []byte, prefix byte, ts int64) []byte {
+	return append(b,
+		prefix<<4|byte(ts>>29)&0x0E|1,
+		byte(ts>>22), byte(ts>>14)|1,
+		byte(ts>>7), byte(ts<<1)|1)
+}
+
+// testPacket is a packet of a hand-built program stream.
+type testPacket struct {
+	id       byte
+	pts, dts int64 // -1 if not coded
+	data     []byte
+}
+
+// buildProgramStream returns an MPEG1 program stream with a pack header, a
+// system header listing "ids" and the packets "packets".
+func buildProgramStream(ids []byte, packets []testPacket) []byte {
+	// Pack header with an SCR of 0 and a mux rate of 1000 * 50 bytes/s.
+	ps := []byte{0x00, 0x00, 0x01, 0xBA, 0x21, 0x00, 0x01, 0x00, 0x01, 0x80, 0x07, 0xD1}
+	ps = append(ps, 0x00, 0x00, 0x01, 0xBB, 0x00, byte(6+3*len(ids)),
+		0x80, 0x07, 0xD1, 0x04, 0xE1, 0xFF)
+	for _, id := range ids {
+		ps = append(ps, id, 0xE0, 0x20)
+	}
+	for _, p := range packets {
+		var header []byte
+		switch {
+		case p.id == 0xBE || p.id == 0xBF:
+			// Padding and private stream 2 packets have no header.
+		case p.pts < 0:
+			header = []byte{0x0F}
+		case p.dts < 0:
+			header = appendTestTimestamp(nil, 0x2, p.pts)
+		default:
+			// Stuffing and an STD buffer size before the time stamps.
+			header = appendTestTimestamp(appendTestTimestamp([]byte{0xFF, 0xFF, 0x60, 0x20}, 0x3, p.pts), 0x1, p.dts)
+		}
+		n := len(header) + len(p.data)
+		ps = append(ps, 0x00, 0x00, 0x01, p.id, byte(n>>8), byte(n))
+		ps = append(append(ps, header...), p.data...)
+	}
+	return append(ps, 0x00, 0x00, 0x01, 0xB9)
+}
+
+func TestStreamTypeOf(t *testing.T) {
+	for _, tc := range []struct {
+		id   byte
+		want StreamType
+	}{
+		{0xBD, StreamPrivate}, {0xBE, StreamPadding}, {0xBF, StreamPrivate},
+		{0xC0, StreamAudio}, {0xDF, StreamAudio},
+		{0xE0, StreamVideo}, {0xEF, StreamVideo},
+		{0xF0, StreamPrivate}, {0xFF, StreamPrivate},
+	} {
+		if got := StreamTypeOf(tc.id); got != tc.want {
+			t.Errorf("StreamTypeOf(0x%02X) is %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestDemuxerPackets(t *testing.T) {
+	packets := []testPacket{
+		{0xE0, 45000, 41400, []byte("first video")},
+		{0xC0, 45000, -1, []byte("first audio")},
+		{0xBD, -1, -1, []byte("private")},
+		{0xBE, -1, -1, []byte{0xFF, 0xFF, 0xFF, 0xFF}},
+		{0xBF, -1, -1, []byte("private 2")},
+		{0xEF, 1 << 32, -1, []byte("last video")},
+		{0xDF, -1, -1, []byte("last audio")},
+	}
+	ids := []byte{0xE0, 0xEF, 0xC0, 0xDF, 0xBD}
+	data := buildProgramStream(ids, packets)
+	packetTime := func(ts int64) time.Duration {
+		if ts < 0 {
+			return NoTimestamp
+		}
+		return time.Duration(ts) * time.Second / 90000
+	}
+
+	for _, tc := range []struct {
+		name string
+		open func() (*Demuxer, error)
+	}{
+		{"bytes", func() (*Demuxer, error) { return NewDemuxerFromBytes(data) }},
+		{"reader", func() (*Demuxer, error) { return NewDemuxerFromReader(bytes.NewReader(data)) }},
+	} {
+		d, err := tc.open()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !bytes.Equal(d.StreamIDs(), ids) || d.NumVideoStreams() != 1 || d.NumAudioStreams() != 1 {
+			t.Errorf("%s: stream IDs are % X with %d video and %d audio streams", tc.name,
+				d.StreamIDs(), d.NumVideoStreams(), d.NumAudioStreams())
+		}
+		for _, want := range packets {
+			p, err := d.NextPacket()
+			if err != nil {
+				t.Fatalf("%s: packet 0x%02X: %v", tc.name, want.id, err)
+			}
+			dts := want.dts
+			if dts < 0 {
+				dts = want.pts
+			}
+			if p.StreamID != want.id || p.Type != StreamTypeOf(want.id) || !bytes.Equal(p.Data, want.data) ||
+				p.PTS != packetTime(want.pts) || p.DTS != packetTime(dts) {
+				t.Errorf("%s: packet is 0x%02X (%v) with PTS %v, DTS %v and data %q, want 0x%02X with PTS %v, DTS %v and data %q",
+					tc.name, p.StreamID, p.Type, p.PTS, p.DTS, p.Data, want.id, packetTime(want.pts), packetTime(dts), want.data)
+			}
+		}
+		if _, err := d.NextPacket(); err != io.EOF {
+			t.Errorf("%s: read after the last packet returned %v, want io.EOF", tc.name, err)
+		}
+		if d.LastError() != nil {
+			t.Errorf("%s: %v", tc.name, d.LastError())
+		}
+		d.Close()
+	}
+}
+
+func TestDemuxer(t *testing.T) {
+	data := readTestFile(t, testFile)
+	d, err := NewDemuxerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// The payloads of each stream add up to the stream the player decodes.
+	streams := map[byte][]byte{}
+	for {
+		p, err := d.NextPacket()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams[p.StreamID] = append(streams[p.StreamID], p.Data...)
+	}
+	for _, id := range []byte{0xE0, 0xC0, 0xC1} {
+		if !bytes.Equal(streams[id], demuxTestStream(data, int64(id))) {
+			t.Errorf("payload of stream 0x%02X differs", id)
+		}
+	}
+
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.StartTime(0xE0) != plm.Info().StartTime || d.Duration(0xE0) != plm.Duration() {
+		t.Errorf("start time and duration are %v and %v, want %v and %v",
+			d.StartTime(0xE0), d.Duration(0xE0), plm.Info().StartTime, plm.Duration())
+	}
+
+	// Seeking video finds the packet with the intra frame at 1s, the start of
+	// the GOP that holds the frame at 1.1s.
+	for _, elapsed := range []time.Duration{time.Second, 1100 * time.Millisecond} {
+		p, err := d.Seek(elapsed, 0xE0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picture := bytes.Index(p.Data, []byte{0x00, 0x00, 0x01, 0x00})
+		if p.StreamID != 0xE0 || picture < 0 || readBits(p.Data, picture+4, 10, 3) != 1 {
+			t.Errorf("seeking to %v did not return a packet with an intra frame", elapsed)
+		}
+		if want := d.StartTime(0xE0) + time.Second; p.PTS != want {
+			t.Errorf("seeking to %v returned a packet with PTS %v, want %v", elapsed, p.PTS, want)
+		}
+	}
+	p, err := d.Seek(time.Second, 0xC1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target := d.StartTime(0xC1) + time.Second; p.StreamID != 0xC1 || p.PTS > target || p.PTS < target-time.Second/10 {
+		t.Errorf("seeking audio returned stream 0x%02X at %v", p.StreamID, p.PTS)
+	}
+
+	r, err := NewDemuxerFromReader(io.MultiReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := r.Seek(time.Second, 0xE0); err != ErrNotSeekable {
+		t.Errorf("seeking a reader returned %v, want ErrNotSeekable", err)
+	}
+	if _, err := d.Seek(time.Second, 0xE5); err != ErrSeekFailed {
+		t.Errorf("seeking a missing stream returned %v, want ErrSeekFailed", err)
+	}
+}
@@ -0,0 +1,133 @@
+package mpg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBufferSize is returned when a buffer passed to the player is too
+	// small or does not match the size of the frame.
+	ErrBufferSize = errors.New("mpg: buffer size does not match the frame")
+	// ErrPixelFormat is returned by "ReadPixels" for an unknown pixel format.
+	ErrPixelFormat = errors.New("mpg: unknown pixel format")
+	// ErrNoVideo is returned when video is requested from a player without
+	// video, or with video disabled.
+	ErrNoVideo = errors.New("mpg: no video stream")
+	// ErrNoAudio is returned when audio is requested from a player without
+	// audio, or with audio disabled.
+	ErrNoAudio = errors.New("mpg: no audio stream")
+	// ErrAudioStream is returned by "SetAudioStream" for an audio stream that
+	// does not exist.
+	ErrAudioStream = errors.New("mpg: audio stream out of range")
+	// ErrNotSeekable is returned when seeking a player that can not seek.
+	ErrNotSeekable = errors.New("mpg: player can not seek")
+	// ErrSeekFailed is returned when no frame could be found at the time that
+	// was seeked to.
+	ErrSeekFailed = errors.New("mpg: seek failed")
+	// ErrInvalidOptions is returned when creating a muxer or an encoder with
+	// options that are out of range.
+	ErrInvalidOptions = errors.New("mpg: invalid options")
+	// ErrMuxerClosed is returned when writing to a "Muxer" that was closed.
+	ErrMuxerClosed = errors.New("mpg: muxer is closed")
+	// ErrInvalidIndex is returned when loading a "KeyframeIndex" that is
+	// corrupt or was built for a different file.
+	ErrInvalidIndex = errors.New("mpg: invalid keyframe index")
+)
+
+// DecodeStage is the part of the decoder that found an error.
+type DecodeStage int
+
+const (
+	// StageDemux is the splitting of the file into video and audio packets.
+	StageDemux DecodeStage = iota
+	// StageVideo is the MPEG1 or MPEG2 video decoder.
+	StageVideo
+	// StageAudio is the MPEG audio decoder.
+	StageAudio
+)
+
+func (stage DecodeStage) String() string {
+	switch stage {
+	case StageDemux:
+		return "demux"
+	case StageVideo:
+		return "video"
+	case StageAudio:
+		return "audio"
+	}
+	return "unknown"
+}
+
+// DecodeError describes corrupt or unsupported data that the decoder skipped.
+// Decoding continues after such errors, so they are not returned by "Decode"
+// but can be queried with "LastError".
+type DecodeError struct {
+	// Offset is the position in the source, in bytes, that the demuxer had
+	// reached when the error was found. For demux errors, this is where the
+	// corrupt data is. Video and audio are decoded from packets read ahead of
+	// time, so for them the corrupt data is at or before it. For a
+	// "VideoDecoder" or "AudioDecoder", it is the position the decoder had
+	// reached.
+	Offset int64
+	// Stage is the part of the decoder that found the error.
+	Stage DecodeStage
+	// Reason describes the error.
+	Reason string
+}
+
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("mpg: %s error at offset %d: %s", err.Stage, err.Offset, err.Reason)
+}
+
+var decodeErrorReasons = map[int64]string{
+	plm_error_demux_packet_header:      "invalid packet header",
+	plm_error_video_sequence_header:    "invalid sequence header",
+	plm_error_video_picture_type:       "invalid picture type",
+	plm_error_video_motion_code:        "invalid motion vector code",
+	plm_error_video_macroblock_address: "macroblock address out of range",
+	plm_error_audio_unsupported:        "unsupported audio version or layer",
+	plm_error_audio_header:             "invalid frame header",
+	plm_error_audio_header_changed:     "frame header changed within the stream",
+	plm_error_video_unsupported:        "unsupported video profile or chroma format",
+	plm_error_demux_packet_lost:        "packets missing from the transport stream",
+}
+
+// collectErrors moves errors found by the decoders since the last call into
+// "lastErr". It is called after anything that decodes.
+func (plm *Player) collectErrors() {
+	p := plm.plm
+	// The demuxer records where it found an error, since it may have read on
+	// to the next packet since. The decoders read packets the demuxer already
+	// passed, so for them the current position is the closest there is.
+	offset := int64(plm_buffer_tell(p.Demux.Buffer))
+	if p.Demux.Error != plm_error_none {
+		plm.setError(StageDemux, &p.Demux.Error, int64(p.Demux.Error_offset))
+	}
+	if p.Video_decoder != nil && p.Video_decoder.Error != plm_error_none {
+		plm.setError(StageVideo, &p.Video_decoder.Error, offset)
+	}
+	if p.Audio_decoder != nil && p.Audio_decoder.Error != plm_error_none {
+		plm.setError(StageAudio, &p.Audio_decoder.Error, offset)
+	}
+}
+
+func (plm *Player) setError(stage DecodeStage, code *int64, offset int64) {
+	plm.lastErr = newDecodeError(stage, code, offset, plm.reader)
+}
+
+// newDecodeError describes the error "*code" found at "offset" and clears it.
+// "src" is the reader feeding the buffer "offset" is in, if any.
+func newDecodeError(stage DecodeStage, code *int64, offset int64, src *readerSource) error {
+	if src != nil {
+		offset += src.discarded
+	}
+	err := &DecodeError{offset, stage, decodeErrorReasons[*code]}
+	*code = plm_error_none
+	return err
+}
+
+// LastError returns the last "*DecodeError" found while decoding, or nil if the
+// data decoded so far was valid. Corrupt data is skipped, so this is the only
+// way to learn about it.
+func (plm *Player) LastError() error { defer plm.lock()(); return plm.lastErr }
@@ -0,0 +1,151 @@
+package mpg
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestPlayerErrors(t *testing.T) {
+	if _, err := NewPlayerFromBytes(nil); !errors.As(err, &ExpectedHeader{}) {
+		t.Errorf("NewPlayerFromBytes without data returned %v, want ExpectedHeader", err)
+	}
+
+	plm, err := NewPlayerFromBytes(readTestFile(t, testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plm.NextFrame(); err != nil {
+		t.Fatal(err)
+	}
+	short := make([]byte, testWidth*testHeight*4-1)
+	if err := plm.ReadRGBA(short); err != ErrBufferSize {
+		t.Errorf("ReadRGBA with a short buffer returned %v, want ErrBufferSize", err)
+	}
+	if err := plm.ReadRGBAAt(short, 0, false); err != ErrBufferSize {
+		t.Errorf("ReadRGBAAt with a short buffer returned %v, want ErrBufferSize", err)
+	}
+	if err := plm.ReadRGBAAt(make([]byte, testWidth*testHeight*4+4), 0, false); err != ErrBufferSize {
+		t.Errorf("ReadRGBAAt with a long buffer returned %v, want ErrBufferSize", err)
+	}
+	// Seeking past the end lands on the last frame.
+	last := (testFrames - 1) * time.Second / testFrameRate
+	if err := plm.Seek(10*testFrames*time.Second/testFrameRate, true); err != nil {
+		t.Errorf("seeking past the end returned %v", err)
+	} else if plm.Frame().Time != last {
+		t.Errorf("seeking past the end landed at %v, want the last frame at %v", plm.Frame().Time, last)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, testWidth, testHeight))
+	if err := plm.DrawFrameAt(img, 10*testFrames*time.Second/testFrameRate, true); err != nil {
+		t.Errorf("DrawFrameAt past the end returned %v", err)
+	}
+	if err := plm.LastError(); err != nil {
+		t.Errorf("decoding valid data returned %v", err)
+	}
+
+	plm, err = NewPlayerFromBytes(readTestFile(t, "testdata/novideo.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plm.Seek(0, false); err != ErrSeekFailed {
+		t.Errorf("seeking without video returned %v, want ErrSeekFailed", err)
+	}
+	if err := plm.DrawFrameAt(img, 0, false); err != ErrNoVideo {
+		t.Errorf("DrawFrameAt without video returned %v, want ErrNoVideo", err)
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	data := readTestFile(t, testFile)
+	// Corrupt the header of an audio packet, so its PTS flags are invalid.
+	packet := bytes.Index(data[len(data)/2:], []byte{0x00, 0x00, 0x01, 0xC0}) + len(data)/2
+	header := packet + 6
+	if data[header]&0xF0 != 0x20 {
+		t.Fatalf("audio packet at %d does not start with a PTS", packet)
+	}
+	data[header] = data[header]&0x0F | 0x10
+
+	// Offsets count from the start of the data, also when a player dropped
+	// data it already decoded.
+	decodeAll := func(plm *Player) (n int) {
+		for {
+			if _, err := plm.NextFrame(); err != nil {
+				return n
+			}
+			n++
+		}
+	}
+	for _, c := range []struct {
+		name   string
+		decode func() (*Player, int)
+	}{
+		{"NewPlayerFromBytes", func() (*Player, int) {
+			plm, err := NewPlayerFromBytes(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return plm, decodeAll(plm)
+		}},
+		{"NewPlayerFromReader", func() (*Player, int) {
+			plm, err := NewPlayerFromReader(struct{ io.Reader }{bytes.NewReader(data)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return plm, decodeAll(plm)
+		}},
+		{"NewLiveStreamPlayer", func() (*Player, int) {
+			plm := NewLiveStreamPlayer()
+			n := 0
+			for i := 0; i < len(data); i += 4096 {
+				end := i + 4096
+				if end > len(data) {
+					end = len(data)
+				}
+				plm.Write(data[i:end])
+				n += decodeAll(plm.Player)
+			}
+			plm.CloseWrite()
+			return plm.Player, n + decodeAll(plm.Player)
+		}},
+	} {
+		plm, n := c.decode()
+		var decodeErr *DecodeError
+		if !errors.As(plm.LastError(), &decodeErr) {
+			t.Errorf("%s: LastError returned %v, want a *DecodeError", c.name, plm.LastError())
+			continue
+		}
+		if decodeErr.Stage != StageDemux || decodeErr.Offset != int64(header) {
+			t.Errorf("%s: got a %v error at offset %d, want a demux error at offset %d",
+				c.name, decodeErr.Stage, decodeErr.Offset, header)
+		}
+		// Only the corrupt audio packet is skipped.
+		if n != testFrames {
+			t.Errorf("%s: decoded %d frames, want %d", c.name, n, testFrames)
+		}
+	}
+
+	// A corrupt picture type is found by the video decoder, once the demuxer
+	// has read past it.
+	data = readTestFile(t, testFile)
+	picture := bytes.Index(data, []byte{0x00, 0x00, 0x01, 0x00})
+	data[picture+5] &^= 0x38
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for plm.LastError() == nil {
+		if _, err := plm.NextFrame(); err != nil {
+			break
+		}
+	}
+	var decodeErr *DecodeError
+	if !errors.As(plm.LastError(), &decodeErr) {
+		t.Fatalf("LastError returned %v, want a *DecodeError", plm.LastError())
+	}
+	if decodeErr.Stage != StageVideo || decodeErr.Offset <= int64(picture) {
+		t.Errorf("got a %v error at offset %d, want a video error after offset %d", decodeErr.Stage, decodeErr.Offset, picture)
+	}
+}
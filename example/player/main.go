@@ -84,6 +84,10 @@ func main() {
 	if g.loop {
 		g.player.SetLoop(true)
 	}
+	// Find intra frames up front so scrubbing can jump straight to them.
+	if g.player.HasVideo() {
+		g.player.BuildIndex()
+	}
 
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
@@ -100,7 +104,9 @@ func main() {
 				"\tFrameRate:   %00.2f\n\n",
 			vStreams, width, height, framerate,
 		)
-		ebiten.SetWindowSize(width, height)
+		// The window is sized to the display aspect ratio, so anamorphic
+		// video is stretched to look right.
+		ebiten.SetWindowSize(g.player.DisplaySize())
 
 		g.videoFrame = image.NewRGBA(image.Rectangle{
 			image.Point{},
@@ -139,7 +145,10 @@ func main() {
 				"\tSampleRate:  %d\n\n",
 			aStreams, samplerate,
 		)
-		g.ctx = audio.NewContext(samplerate)
+		// Ebiten allows only one audio context, so audio is resampled to
+		// the context's rate instead of creating a context per video.
+		g.player.SetOutputSampleRate(contextSampleRate)
+		g.ctx = audio.NewContext(contextSampleRate)
 		g.p, _ = g.ctx.NewPlayer(g.player)
 		g.p.SetBufferSize(g.player.AudioLeadTime())
 		g.p.Play()
@@ -201,8 +210,8 @@ func (g *Game) Update() error {
 		g.playheadPos = float64(mouseX) / width
 		if mouseX != g.pmouseX {
 			// If mouse moved, grab a preview frame at specific time and draw it above the playhead.
-			ok := g.player.ReadRGBAAt(g.previewFrame.Pix, time.Duration(float64(g.player.Duration())*float64(mouseX)/width), false)
-			if ok {
+			err := g.player.ReadRGBAAt(g.previewFrame.Pix, time.Duration(float64(g.player.Duration())*float64(mouseX)/width), false)
+			if err == nil {
 				g.previewImage.ReplacePixels(g.previewFrame.Pix)
 				g.previewPos.GeoM.Reset()
 				g.previewPos.GeoM.Scale(0.25, 0.25)
@@ -279,6 +288,10 @@ func (g *Game) Layout(width, height int) (int, int) {
 
 var done = Done{}
 
+// The sample rate of the audio context. Videos with other sample rates are
+// resampled to it.
+const contextSampleRate = 48000
+
 // Returned when Ebiten is finished.
 type Done struct{}
 
@@ -0,0 +1,174 @@
+package mpg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+	"unsafe"
+)
+
+// PictureType is how a frame was coded in the video stream.
+type PictureType int
+
+const (
+	// PictureIntra frames are coded on their own. Seeking always lands on
+	// them.
+	PictureIntra PictureType = 1
+	// PicturePredictive frames are predicted from the previous intra or
+	// predictive frame.
+	PicturePredictive PictureType = 2
+	// PictureBidirectional frames are predicted from the intra or predictive
+	// frames before and after them.
+	PictureBidirectional PictureType = 3
+)
+
+func (t PictureType) String() string {
+	switch t {
+	case PictureIntra:
+		return "I"
+	case PicturePredictive:
+		return "P"
+	case PictureBidirectional:
+		return "B"
+	}
+	return "?"
+}
+
+// Frame is a decoded video frame. It implements "image.Image", and its planes
+// can be used directly through "YCbCr" without converting them to RGB.
+//
+// A frame points to memory owned by the decoder, so it is only valid until the
+// next frame is decoded, the player seeks or the player is closed.
+type Frame struct {
+	// Time is the presentation time of the frame.
+	Time time.Duration
+	// Type is how the frame was coded.
+	Type PictureType
+	// Width and Height are the size of the picture. Planes are padded to a
+	// multiple of 16 pixels, so they may be larger.
+	Width, Height int
+	// Y holds the luma plane, one byte per pixel.
+	Y []byte
+	// Cb and Cr hold the chroma planes, one byte per 2x2 pixels.
+	Cb, Cr []byte
+	// YStride and CStride are the number of bytes between rows of the luma
+	// and chroma planes.
+	YStride, CStride int
+
+	plm plm_frame_t
+}
+
+func newFrame(f *plm_frame_t) *Frame {
+	// The decoder reuses "f" for other frames, so the frame keeps a copy.
+	return &Frame{
+		Time:    floatToSecs(f.Time),
+		Type:    PictureType(f.Picture_type),
+		Width:   int(f.Width),
+		Height:  int(f.Height),
+		Y:       planeBytes(f.Y),
+		Cb:      planeBytes(f.Cb),
+		Cr:      planeBytes(f.Cr),
+		YStride: int(f.Y.Width),
+		CStride: int(f.Cb.Width),
+		plm:     *f,
+	}
+}
+
+func planeBytes(p plm_plane_t) []byte {
+	return unsafe.Slice(p.Data, p.Width*p.Height)
+}
+
+// YCbCr returns a view of the frame as an "*image.YCbCr" using
+// "image.YCbCrSubsampleRatio420". No pixels are copied, so it shares the
+// frame's memory and is only valid as long as the frame is.
+//
+// Colors of an "*image.YCbCr" are converted as full range JPEG colors, while
+// "At", "ReadRGBA" and "DrawTo" expand the studio range of MPEG video, so its
+// colors are less saturated.
+func (f *Frame) YCbCr() *image.YCbCr {
+	return &image.YCbCr{
+		Y:              f.Y,
+		Cb:             f.Cb,
+		Cr:             f.Cr,
+		YStride:        f.YStride,
+		CStride:        f.CStride,
+		SubsampleRatio: image.YCbCrSubsampleRatio420,
+		Rect:           f.Bounds(),
+	}
+}
+
+func (f *Frame) ColorModel() color.Model { return color.RGBAModel }
+
+func (f *Frame) Bounds() image.Rectangle { return image.Rect(0, 0, f.Width, f.Height) }
+
+var black = color.RGBA{A: 0xFF}
+
+func (f *Frame) At(x, y int) color.Color {
+	if x < 0 || y < 0 || x >= f.Width || y >= f.Height {
+		return black
+	}
+	r, g, b := f.rgb(x, y)
+	return color.RGBA{r, g, b, 0xFF}
+}
+
+// rgb converts the pixel at "x" and "y" the same way pl_mpeg does.
+func (f *Frame) rgb(x, y int) (r, g, b uint8) {
+	cIndex := x/2 + (y/2)*f.CStride
+	return ycbcrToRGB(f.Y[x+y*f.YStride], f.Cb[cIndex], f.Cr[cIndex])
+}
+
+// ycbcrToRGB converts a color the same way pl_mpeg does, expanding the studio
+// range of MPEG video to full range.
+func ycbcrToRGB(y, cb, cr uint8) (r, g, b uint8) {
+	luma := ((int64(y) - 16) * 76309) >> 16
+	cbDiff, crDiff := int64(cb)-128, int64(cr)-128
+	r = plm_clamp(luma + (crDiff*0x19895)>>16)
+	g = plm_clamp(luma - (cbDiff*0x644A+crDiff*0xD01E)>>16)
+	b = plm_clamp(luma + (cbDiff*0x20469)>>16)
+	return
+}
+
+// readRGBA converts the frame to RGBA and writes it to "dst", where each row
+// starts "stride" bytes after the previous one. Alpha is left unchanged.
+func (f *Frame) readRGBA(dst []byte, stride int) {
+	plm_frame_to_rgba(&f.plm, bytesToUintPtr(dst), int64(stride))
+	f.convertOddEdges(dst, PixelRGBA, stride)
+}
+
+// draw draws the frame to "img". An "*image.RGBA" is written to directly.
+func (f *Frame) draw(img draw.Image) {
+	rgba, ok := img.(*image.RGBA)
+	if !ok || !f.Bounds().In(rgba.Rect) {
+		draw.Draw(img, f.Bounds(), f, image.Point{}, draw.Src)
+		return
+	}
+	pix := rgba.Pix[rgba.PixOffset(0, 0):]
+	f.readRGBA(pix, rgba.Stride)
+	for y := 0; y < f.Height; y++ {
+		row := pix[y*rgba.Stride : y*rgba.Stride+f.Width*4]
+		for i := 3; i < len(row); i += 4 {
+			row[i] = 0xFF
+		}
+	}
+}
+
+// Samples is a decoded audio frame returned by "NextSamples". Frames hold 1152
+// samples per channel, or 384 for Layer I audio.
+//
+// Samples point to memory owned by the decoder, so they are only valid until
+// the next audio frame is decoded or the player seeks.
+type Samples struct {
+	// Time is the presentation time of the first sample.
+	Time time.Duration
+	// Interleaved holds the samples of the left and right channel interleaved,
+	// ranging from -1 to 1. Mono audio is decoded to both channels.
+	Interleaved []float32
+}
+
+func newSamples(s *plm_samples_t) *Samples {
+	return &Samples{
+		Time:        floatToSecs(s.Time),
+		Interleaved: s.Interleaved[:s.Count*2],
+	}
+}
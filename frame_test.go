@@ -0,0 +1,217 @@
+package mpg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestNextFrame(t *testing.T) {
+	data := readTestFile(t, testFile)
+	want, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFrames := decodeFrames(t, want)
+
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, hash := range wantFrames {
+		f, err := plm.NextFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		if frameHash(f) != hash {
+			t.Fatalf("frame %d differs", i)
+		}
+		if want := time.Duration(i) * time.Second / testFrameRate; f.Time != want {
+			t.Errorf("frame %d is at %v, want %v", i, f.Time, want)
+		}
+		if !plm.HasNewFrame() {
+			t.Errorf("frame %d is not the current frame", i)
+		}
+	}
+	if len(wantFrames) != testFrames {
+		t.Errorf("decoded %d frames, want %d", len(wantFrames), testFrames)
+	}
+	for i := 0; i < 2; i++ {
+		if f, err := plm.NextFrame(); f != nil || err != io.EOF {
+			t.Errorf("NextFrame at the end returned %v, %v, want io.EOF", f, err)
+		}
+	}
+
+	// A looping video starts over instead of ending.
+	plm.Rewind()
+	plm.SetLoop(true)
+	for i := 0; i < testFrames; i++ {
+		if _, err := plm.NextFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := plm.NextFrame()
+	if err != nil || frameHash(f) != wantFrames[0] {
+		t.Errorf("looping video did not start over: %v", err)
+	}
+}
+
+func TestNextSamples(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frameTime := float64(plm_audio_samples_per_frame) / float64(plm.SampleRate())
+	n := 0
+	for ; ; n++ {
+		s, err := plm.NextSamples()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("samples %d: %v", n, err)
+		}
+		if len(s.Interleaved) != plm_audio_samples_per_frame*2 {
+			t.Fatalf("samples %d has %d values, want %d", n, len(s.Interleaved), plm_audio_samples_per_frame*2)
+		}
+		if want := floatToSecs(float64(n) * frameTime); s.Time < want-time.Millisecond || s.Time > want+time.Millisecond {
+			t.Errorf("samples %d are at %v, want %v", n, s.Time, want)
+		}
+	}
+	// The audio is as long as the video, rounded up to whole MP2 frames.
+	if want := int(float64(testFrames)/testFrameRate/frameTime) + 1; n != want {
+		t.Errorf("decoded %d MP2 frames, want %d", n, want)
+	}
+	if s, err := plm.NextSamples(); s != nil || err != io.EOF {
+		t.Errorf("NextSamples at the end returned %v, %v, want io.EOF", s, err)
+	}
+}
+
+func TestNextWithoutStream(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, "testdata/novideo.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plm.HasVideo() {
+		t.Fatal("testdata/novideo.mpg has video")
+	}
+	if f, err := plm.NextFrame(); f != nil || err != ErrNoVideo {
+		t.Errorf("NextFrame without video returned %v, %v, want ErrNoVideo", f, err)
+	}
+
+	plm, err = NewPlayerFromBytes(readTestFile(t, "testdata/odd.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plm.HasAudio() {
+		t.Fatal("testdata/odd.mpg has audio")
+	}
+	if s, err := plm.NextSamples(); s != nil || err != ErrNoAudio {
+		t.Errorf("NextSamples without audio returned %v, %v, want ErrNoAudio", s, err)
+	}
+}
+
+func TestFrameYCbCr(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, "testdata/odd.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const width, height = 35, 27
+	// Planes are padded to whole macroblocks.
+	const yStride, cStride, paddedHeight = 48, 24, 32
+	rgba := make([]byte, width*height*4)
+	for i := 0; i < 5; i++ {
+		f, err := plm.NextFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Width != width || f.Height != height || f.YStride != yStride || f.CStride != cStride {
+			t.Fatalf("frame is %dx%d with strides %d and %d, want %dx%d with strides %d and %d",
+				f.Width, f.Height, f.YStride, f.CStride, width, height, yStride, cStride)
+		}
+		if len(f.Y) != yStride*paddedHeight || len(f.Cb) != cStride*paddedHeight/2 || len(f.Cr) != len(f.Cb) {
+			t.Fatalf("planes have %d, %d and %d bytes", len(f.Y), len(f.Cb), len(f.Cr))
+		}
+
+		// The planes are not copied, they are those of the decoder.
+		v := plm.plm.Video_decoder
+		shared := false
+		for _, d := range []*plm_frame_t{&v.Frame_current, &v.Frame_forward, &v.Frame_backward} {
+			if &f.Y[0] == d.Y.Data && &f.Cb[0] == d.Cb.Data && &f.Cr[0] == d.Cr.Data {
+				shared = true
+			}
+		}
+		if !shared {
+			t.Errorf("frame %d does not share the planes of the decoder", i)
+		}
+		img := f.YCbCr()
+		if &img.Y[0] != &f.Y[0] || &img.Cb[0] != &f.Cb[0] || &img.Cr[0] != &f.Cr[0] {
+			t.Errorf("YCbCr of frame %d does not share the planes of the frame", i)
+		}
+		if img.YStride != yStride || img.CStride != cStride || img.SubsampleRatio != image.YCbCrSubsampleRatio420 ||
+			img.Rect != image.Rect(0, 0, width, height) {
+			t.Errorf("YCbCr of frame %d has strides %d and %d, ratio %v and bounds %v",
+				i, img.YStride, img.CStride, img.SubsampleRatio, img.Rect)
+		}
+
+		plm.ReadRGBA(rgba)
+		for _, p := range []image.Point{
+			{0, 0}, {width / 2, height / 2}, {17, 3}, {width - 1, 0}, {0, height - 1}, {width - 1, height - 1},
+		} {
+			o := (p.X + p.Y*width) * 4
+			want := color.RGBA{rgba[o], rgba[o+1], rgba[o+2], 0xFF}
+			if c := f.At(p.X, p.Y); c != want {
+				t.Errorf("frame %d: At%v is %v, ReadRGBA has %v", i, p, c, want)
+			}
+			want2 := color.YCbCr{f.Y[p.X+p.Y*yStride], f.Cb[p.X/2+p.Y/2*cStride], f.Cr[p.X/2+p.Y/2*cStride]}
+			if c := img.YCbCrAt(p.X, p.Y); c != want2 {
+				t.Errorf("frame %d: YCbCrAt%v is %v, want %v", i, p, c, want2)
+			}
+		}
+	}
+	f := plm.Frame()
+	if c := f.At(-1, 0); c != black {
+		t.Errorf("At outside of the frame is %v, want black", c)
+	}
+	if c := f.At(width, height-1); c != black {
+		t.Errorf("At outside of the frame is %v, want black", c)
+	}
+}
+
+func TestDrawTo(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, "testdata/odd.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plm.NextFrame(); err != nil {
+		t.Fatal(err)
+	}
+	want := image.NewRGBA(plm.Frame().Bounds())
+	plm.ReadRGBA(want.Pix)
+	for i := range want.Pix {
+		if i%4 == 3 {
+			want.Pix[i] = 0xFF
+		}
+	}
+	for _, img := range []draw.Image{
+		image.NewRGBA(want.Rect),
+		// A larger image, drawn to through a stride different from the
+		// width of the frame.
+		image.NewRGBA(image.Rect(-3, -2, 40, 30)),
+		image.NewNRGBA(want.Rect),
+	} {
+		plm.DrawTo(img)
+		for y := 0; y < want.Rect.Dy(); y++ {
+			for x := 0; x < want.Rect.Dx(); x++ {
+				if c := color.RGBAModel.Convert(img.At(x, y)); c != want.At(x, y) {
+					t.Fatalf("%T%v: pixel (%d,%d) is %v, want %v", img, img.Bounds(), x, y, c, want.At(x, y))
+				}
+			}
+		}
+		if c := img.At(img.Bounds().Max.X-1, img.Bounds().Max.Y-1); img.Bounds() != want.Rect && c != (color.RGBA{}) {
+			t.Errorf("%T%v: pixel outside of the frame was drawn to", img, img.Bounds())
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package mpg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// Keyframe is an intra frame of the video, which decoding can start from.
+type Keyframe struct {
+	// Frame is the number of the frame in display order, counting from 0.
+	Frame int
+	// Time is the presentation time of the frame, relative to the start of
+	// the video like "Player.Time".
+	Time time.Duration
+	// Offset is the position in bytes of the packet the frame starts in.
+	Offset int64
+}
+
+// KeyframeIndex maps the intra frames of a video to their position in the
+// file, so seeking can go straight to the right group of pictures instead of
+// estimating the position from the bitrate.
+//
+// Building an index reads the whole file, so it can be saved with
+// "MarshalBinary" and loaded again with "UnmarshalBinary" and
+// "Player.SetIndex".
+type KeyframeIndex struct {
+	// FileSize is the size of the file the index was built for.
+	FileSize int64
+	// Frames is the number of frames in the video.
+	Frames int
+	// Keyframes are sorted by frame number.
+	Keyframes []Keyframe
+}
+
+// indexMagic starts a marshaled index, followed by its version.
+const indexMagic = "MPGI\x01"
+
+// find returns the last keyframe at or before frame "n", or the first one if
+// "n" is before all of them.
+func (idx *KeyframeIndex) find(n int) (Keyframe, bool) {
+	if len(idx.Keyframes) == 0 {
+		return Keyframe{}, false
+	}
+	i := sort.Search(len(idx.Keyframes), func(i int) bool { return idx.Keyframes[i].Frame > n }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return idx.Keyframes[i], true
+}
+
+// findTime returns the last keyframe shown at or before "t", or the first one
+// if "t" is before all of them.
+func (idx *KeyframeIndex) findTime(t time.Duration) (Keyframe, bool) {
+	if len(idx.Keyframes) == 0 {
+		return Keyframe{}, false
+	}
+	i := sort.Search(len(idx.Keyframes), func(i int) bool { return idx.Keyframes[i].Time > t }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return idx.Keyframes[i], true
+}
+
+// MarshalBinary encodes the index in a compact binary form.
+func (idx *KeyframeIndex) MarshalBinary() ([]byte, error) {
+	data := append([]byte(nil), indexMagic...)
+	var tmp [binary.MaxVarintLen64]byte
+	put := func(v int64) { data = append(data, tmp[:binary.PutVarint(tmp[:], v)]...) }
+	put(idx.FileSize)
+	put(int64(idx.Frames))
+	put(int64(len(idx.Keyframes)))
+	var last Keyframe
+	for _, k := range idx.Keyframes {
+		put(int64(k.Frame - last.Frame))
+		put(int64(k.Time - last.Time))
+		put(k.Offset - last.Offset)
+		last = k
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes an index encoded by "MarshalBinary". It returns
+// "ErrInvalidIndex" if "data" is not a valid index.
+func (idx *KeyframeIndex) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(indexMagic)) {
+		return ErrInvalidIndex
+	}
+	r := bytes.NewReader(data[len(indexMagic):])
+	var header [3]int64
+	for i := range header {
+		v, err := binary.ReadVarint(r)
+		if err != nil || v < 0 {
+			return ErrInvalidIndex
+		}
+		header[i] = v
+	}
+	size, frames, count := header[0], header[1], header[2]
+	// Every keyframe takes at least three bytes.
+	if count > int64(r.Len()/3) {
+		return ErrInvalidIndex
+	}
+	keyframes := make([]Keyframe, count)
+	var last Keyframe
+	for i := range keyframes {
+		var delta [3]int64
+		for j := range delta {
+			v, err := binary.ReadVarint(r)
+			if err != nil {
+				return ErrInvalidIndex
+			}
+			delta[j] = v
+		}
+		last = Keyframe{last.Frame + int(delta[0]), last.Time + time.Duration(delta[1]), last.Offset + delta[2]}
+		if delta[0] < 0 || int64(last.Frame) >= frames || last.Offset < 0 || last.Offset >= size {
+			return ErrInvalidIndex
+		}
+		keyframes[i] = last
+	}
+	idx.FileSize, idx.Frames, idx.Keyframes = size, int(frames), keyframes
+	return nil
+}
+
+// BuildIndex reads the whole file once to count its frames and find its intra
+// frames, and uses the resulting index for seeking from then on. Playback
+// continues where it was afterwards.
+//
+// BuildIndex returns "ErrNotSeekable" if the player can not seek, and
+// "ErrNoVideo" if there is no video.
+func (plm *Player) BuildIndex() (*KeyframeIndex, error) {
+	defer plm.lock()()
+	return plm.buildIndex()
+}
+
+func (plm *Player) buildIndex() (*KeyframeIndex, error) {
+	if !plm.seekable {
+		return nil, ErrNotSeekable
+	}
+	if !plm.hasVideo() {
+		return nil, ErrNoVideo
+	}
+	demux := plm.plm.Demux
+	buffer := demux.Buffer
+	framerate := plm.plm.Video_decoder.Framerate
+	pos, startCode := plm_buffer_tell(buffer), demux.Start_code
+	current, next := demux.Current_packet, demux.Next_packet
+	ts := plm_demux_ts_copy(demux.Ts)
+
+	scanner := videoScanner{framerate: framerate}
+	var (
+		// code holds the last bytes of the video stream, to find start codes
+		// that are split between packets.
+		code                   = ^uint32(0)
+		header                 uint64
+		headerBytes            int
+		extension              bool
+		offset, previousOffset int64
+		pictureOffset          int64
+	)
+	plm_demux_buffer_seek(demux, 0)
+	for demux.Ts != nil || plm_buffer_find_start_code(buffer, plm_demux_packet_video_1) != -1 {
+		var p *plm_packet_t
+		if demux.Ts != nil {
+			// Packets of a transport stream are reassembled, and start at the
+			// transport packet holding their header.
+			if p = plm_demux_ts_decode(demux, plm_demux_packet_video_1); p == nil {
+				break
+			}
+			offset = int64(demux.Ts.Packet_start)
+		} else {
+			offset = int64(plm_buffer_tell(buffer)) - 4
+			if p = plm_demux_decode_packet(demux, plm_demux_packet_video_1); p == nil {
+				continue
+			}
+			// Skip the payload, which may hold bytes that look like a start
+			// code.
+			plm_buffer_skip(buffer, p.Length<<3)
+		}
+		data := unsafe.Slice(p.Data, p.Length)
+		for i, b := range data {
+			if headerBytes > 0 {
+				header = header<<8 | uint64(b)
+				if headerBytes--; headerBytes == 0 {
+					if extension {
+						scanner.extension(header)
+					} else {
+						// The first 13 bits of a picture header are its
+						// temporal reference and its type.
+						scanner.picture(int64(header>>3&0x07), pictureOffset)
+					}
+				}
+			}
+			code = code<<8 | uint32(b)
+			if code&0xFFFFFF00 != 0x00000100 {
+				continue
+			}
+			switch int64(code & 0xFF) {
+			case plm_start_picture:
+				pictureOffset = offset
+				if i < 3 {
+					pictureOffset = previousOffset
+				}
+				header, headerBytes, extension = 0, 2, false
+			case plm_start_extension:
+				header, headerBytes, extension = 0, 5, true
+			}
+		}
+		previousOffset = offset
+	}
+	scanner.finish()
+	idx := &KeyframeIndex{FileSize: int64(plm_buffer_get_size(buffer)), Frames: scanner.frames, Keyframes: scanner.keyframes}
+
+	plm_demux_buffer_seek(demux, pos)
+	demux.Start_code, demux.Current_packet, demux.Next_packet = startCode, current, next
+	plm_demux_ts_restore(demux, ts)
+	plm.index = idx
+	return idx, nil
+}
+
+// SetIndex sets the index used for seeking, such as one built by "BuildIndex"
+// earlier and loaded from a cache. It must not be modified while it is in use.
+// A nil index goes back to estimating positions from the bitrate.
+//
+// SetIndex returns "ErrNotSeekable" if the player can not seek, and
+// "ErrInvalidIndex" if the index was built for a file of a different size.
+func (plm *Player) SetIndex(idx *KeyframeIndex) error {
+	defer plm.lock()()
+	if !plm.seekable {
+		return ErrNotSeekable
+	}
+	if idx != nil && idx.FileSize != int64(plm_buffer_get_size(plm.plm.Demux.Buffer)) {
+		return ErrInvalidIndex
+	}
+	plm.index = idx
+	return nil
+}
+
+// Index returns the index used for seeking, or nil if there is none.
+func (plm *Player) Index() *KeyframeIndex { defer plm.lock()(); return plm.index }
+
+// SetLazyIndex sets whether the index is built by the first seek when there is
+// none yet, rather than only by "BuildIndex". This makes that first seek
+// slower and later ones faster, which suits scrubbing through a video.
+func (plm *Player) SetLazyIndex(lazy bool) { defer plm.lock()(); plm.lazyIndex = lazy }
+
+// LazyIndex returns true if the index is built by the first seek.
+func (plm *Player) LazyIndex() bool { defer plm.lock()(); return plm.lazyIndex }
+
+// prepareIndex builds the index before seeking if it should be built lazily.
+func (plm *Player) prepareIndex() {
+	if plm.lazyIndex && plm.index == nil && plm.hasVideo() {
+		plm.buildIndex()
+	}
+}
+
+// SeekFrame moves to frame "n" in display order, counting from 0, so that it
+// is the current frame. Unlike "Seek", this does not round times, so it always
+// lands on that exact frame, even if B frames are shown in a different order
+// than they are stored. The index is built first if the player has none.
+//
+// SeekFrame returns "ErrNotSeekable" if the player can not seek, "ErrNoVideo"
+// if there is no video, and "ErrSeekFailed" if "n" is not a frame of the
+// video.
+func (plm *Player) SeekFrame(n int) error {
+	defer plm.lock()()
+	if !plm.seekable {
+		return ErrNotSeekable
+	}
+	if !plm.hasVideo() || !plm.videoEnabled() {
+		return ErrNoVideo
+	}
+	if plm.index == nil {
+		if _, err := plm.buildIndex(); err != nil {
+			return err
+		}
+	}
+	if n < 0 || n >= plm.index.Frames {
+		return ErrSeekFailed
+	}
+	plm.resetResampler()
+	f, err := plm.seekIndexedFrame(n)
+	plm.collectErrors()
+	if err != nil {
+		return err
+	}
+	plm_seek_resume(plm.plm, f)
+	return nil
+}
+
+// CurrentFrameNumber returns the number of the current frame in display order,
+// counting from 0, or -1 if no frame was decoded yet. It is exact during
+// playback from the start and after seeking with an index, and estimated from
+// the time after seeking without one.
+func (plm *Player) CurrentFrameNumber() int {
+	defer plm.lock()()
+	video := plm.plm.Video_decoder
+	if video == nil {
+		return -1
+	}
+	return int(video.Frames_decoded) - 1
+}
+
+// FrameCount returns the number of frames in the video. The index is built
+// first if the player has none. FrameCount returns -1 if the player can not
+// seek or has no video.
+func (plm *Player) FrameCount() int {
+	defer plm.lock()()
+	if plm.index == nil {
+		if _, err := plm.buildIndex(); err != nil {
+			return -1
+		}
+	}
+	return plm.index.Frames
+}
+
+// seekIndexedFrame decodes frame "n" by starting from the last intra frame at
+// or before it in the index.
+func (plm *Player) seekIndexedFrame(n int) (*plm_frame_t, error) {
+	keyframe, ok := plm.index.find(n)
+	if !ok {
+		return nil, ErrSeekFailed
+	}
+	return plm.seekIndexed(keyframe, func(number int, frame *plm_frame_t) bool { return number >= n })
+}
+
+// seekIndexedTime decodes the frame "Seek" should land on for "t", by starting
+// from the last intra frame shown at or before "t" in the index. That is the
+// first frame shown at or after "t" if "exact" is true, and that intra frame
+// otherwise.
+func (plm *Player) seekIndexedTime(t time.Duration, exact bool) (*plm_frame_t, error) {
+	keyframe, ok := plm.index.findTime(t)
+	if !ok {
+		return nil, ErrSeekFailed
+	}
+	return plm.seekIndexed(keyframe, func(number int, frame *plm_frame_t) bool {
+		return !exact || floatToSecs(frame.Time) >= t
+	})
+}
+
+// seekIndexed decodes from "keyframe" until "done" returns true for a frame,
+// and numbers frames as it goes instead of deriving them from timestamps.
+func (plm *Player) seekIndexed(keyframe Keyframe, done func(number int, frame *plm_frame_t) bool) (*plm_frame_t, error) {
+	p := plm.plm
+	video := p.Video_decoder
+	packet := plm_demux_seek_to(p.Demux, uint64(keyframe.Offset), p.Video_packet_type)
+	if packet == nil {
+		return nil, ErrSeekFailed
+	}
+	audioPacketType := p.Audio_packet_type
+	p.Audio_packet_type = 0
+	defer func() { p.Audio_packet_type = audioPacketType }()
+	plm_video_rewind(video)
+	plm_buffer_write(p.Video_buffer, packet.Data, packet.Length)
+	if !findIntraPicture(video) {
+		return nil, ErrSeekFailed
+	}
+	frame := decodeFromKeyframe(video, keyframe, done)
+	if frame == nil {
+		return nil, ErrSeekFailed
+	}
+	p.Time = frame.Time
+	p.Has_ended = _false
+	return frame, nil
+}
+
+// findIntraPicture moves the video decoder to the first intra picture in its
+// buffer, skipping the end of any picture the packet starts with.
+func findIntraPicture(video *plm_video_t) bool {
+	buffer := video.Buffer
+	for plm_buffer_find_start_code(buffer, plm_start_picture) != -1 {
+		if plm_buffer_has(buffer, 13) != _true {
+			return false
+		}
+		pos := buffer.Bit_index
+		plm_buffer_skip(buffer, 10)
+		intra := plm_buffer_read(buffer, 3) == plm_video_picture_type_intra
+		buffer.Bit_index = pos
+		if intra {
+			video.Start_code = plm_start_picture
+			return true
+		}
+	}
+	return false
+}
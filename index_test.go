@@ -0,0 +1,289 @@
+package mpg
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"image"
+	"testing"
+	"time"
+)
+
+// loadTestIndex builds the index of "data", and loads its marshaled form into
+// a new player.
+func loadTestIndex(t testing.TB, data []byte) *Player {
+	t.Helper()
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := plm.BuildIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Keyframes) != testFrames/testGOPSize {
+		t.Fatalf("index has %d keyframes, want %d", len(idx.Keyframes), testFrames/testGOPSize)
+	}
+	saved, err := idx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded KeyframeIndex
+	if err := loaded.UnmarshalBinary(saved); err != nil {
+		t.Fatal(err)
+	}
+	if plm, err = NewPlayerFromBytes(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := plm.SetIndex(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	return plm
+}
+
+func TestKeyframeIndexSeek(t *testing.T) {
+	data := readTestFile(t, testFile)
+	indexed := loadTestIndex(t, data)
+	plain, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := decodeFrames(t, plain)
+	for _, n := range []int{0, 5, 7, 10, 23, 41, 44, 49} {
+		at := time.Duration(n) * time.Second / testFrameRate
+		if err := indexed.Seek(at, true); err != nil {
+			t.Fatalf("seeking to %v with the index: %v", at, err)
+		}
+		if err := plain.Seek(at, true); err != nil {
+			t.Fatalf("seeking to %v without the index: %v", at, err)
+		}
+		withIndex, without := indexed.Frame(), plain.Frame()
+		if withIndex == nil || frameHash(withIndex) != frames[n] || withIndex.Time != at {
+			t.Errorf("seeking to %v with the index did not land on frame %d", at, n)
+		}
+		if without == nil || frameHash(without) != frames[n] || without.Time != at {
+			t.Errorf("seeking to %v without the index did not land on frame %d", at, n)
+		}
+	}
+	if plain.Index() != nil {
+		t.Error("seeking built an index, but lazy indexing is off")
+	}
+}
+
+func TestKeyframeIndexInvalid(t *testing.T) {
+	data := readTestFile(t, testFile)
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := plm.BuildIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := idx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved[:5]) != "MPGI\x01" {
+		t.Errorf("saved index starts with %q, want \"MPGI\\x01\"", saved[:5])
+	}
+	for _, bad := range [][]byte{
+		nil,
+		saved[:3],
+		saved[:len(saved)-1],
+		append([]byte("MPGI\x02"), saved[5:]...),
+		append([]byte("MPEG\x01"), saved[5:]...),
+	} {
+		var loaded KeyframeIndex
+		if err := loaded.UnmarshalBinary(bad); err != ErrInvalidIndex {
+			t.Errorf("loading % X returned %v, want %v", bad, err, ErrInvalidIndex)
+		}
+	}
+	other := *idx
+	other.FileSize++
+	if err := plm.SetIndex(&other); err != ErrInvalidIndex {
+		t.Errorf("setting an index of another file returned %v, want %v", err, ErrInvalidIndex)
+	}
+}
+
+// ibbpOrder is the coded order of the frames of a group of pictures of
+// "encodeTestIBBP", as their numbers in display order.
+var ibbpOrder = []int{0, 3, 1, 2, 6, 4, 5}
+
+// encodeTestIBBP encodes "gops" groups of pictures of "testImage" with the
+// frames I0 P3 B1 B2 P6 B4 B5 in coded order, and muxes them into an MPG file.
+// The stream ends in B pictures, so the last frame shown is a P picture that
+// was decoded before them.
+func encodeTestIBBP(t testing.TB, gops int) []byte {
+	t.Helper()
+	enc, err := NewVideoEncoder(VideoEncoderOptions{Width: testWidth, Height: testHeight, FrameRate: testFrameRate, GOPSize: 3, Quantizer: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	mux, err := NewMuxer(&out, MuxerOptions{Video: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pts time.Duration
+	for gop := 0; gop < gops; gop++ {
+		for _, tref := range ibbpOrder {
+			n := gop*len(ibbpOrder) + tref
+			var data []byte
+			if tref%3 == 0 {
+				coded, err := enc.Encode(testImage(n))
+				if err != nil {
+					t.Fatal(err)
+				}
+				// The encoder numbers its pictures in coded order.
+				data = append([]byte(nil), coded...)
+				writeBits(data, bytes.Index(data, []byte{0x00, 0x00, 0x01, 0x00})+4, 0, 10, tref)
+			} else {
+				data = encodeTestBPicture(enc, testImage(n), tref)
+			}
+			pts = time.Duration(n) * time.Second / testFrameRate
+			if err := mux.WriteVideo(data, pts); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := mux.WriteVideo(enc.Flush(), pts); err != nil {
+		t.Fatal(err)
+	}
+	if err := mux.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// encodeTestBPicture codes "img" as a B picture with the temporal reference
+// "tref". VideoEncoder does not make B pictures, so all macroblocks are intra
+// coded, which leaves the reference frames of the encoder untouched.
+func encodeTestBPicture(enc *VideoEncoder, img image.Image, tref int) []byte {
+	types := vlcEncodeTable(plm_video_macroblock_type_b[:])
+	enc.loadImage(img)
+	w := &enc.w
+	w.reset()
+	w.startCode(byte(plm_start_picture))
+	w.write(uint32(tref), 10)
+	w.write(uint32(plm_video_picture_type_b), 3)
+	w.write(0xFFFF, 16) // vbv_delay
+	w.write(0, 1)       // full_pel_forward_vector
+	w.write(1, 3)       // forward_f_code
+	w.write(0, 1)       // full_pel_backward_vector
+	w.write(1, 3)       // backward_f_code
+	w.write(0, 1)       // extra_bit_picture
+	for row := 0; row < enc.mbHeight; row++ {
+		w.startCode(byte(row + 1))
+		w.write(uint32(enc.quantizer), 5)
+		w.write(0, 1) // extra_bit_slice
+		enc.dcPredictor = [3]int{128, 128, 128}
+		for col := 0; col < enc.mbWidth; col++ {
+			enc.writeAddress(1)
+			w.writeCode(types[1])
+			enc.encodeIntra(row, col)
+		}
+	}
+	return append([]byte(nil), w.bytes()...)
+}
+
+func TestPlayerSeekFrame(t *testing.T) {
+	data := readTestFile(t, testFile)
+	plain, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := plain.CurrentFrameNumber(); n != -1 {
+		t.Errorf("current frame number before decoding is %d, want -1", n)
+	}
+	frames := decodeFrames(t, plain)
+	// "plain" builds its own index, "indexed" uses a saved one.
+	for _, plm := range []*Player{plain, loadTestIndex(t, data)} {
+		if n := plm.FrameCount(); n != testFrames {
+			t.Errorf("frame count is %d, want %d", n, testFrames)
+		}
+		for _, n := range []int{0, 7, 10, 23, 44, 49, 3} {
+			if err := plm.SeekFrame(n); err != nil {
+				t.Fatalf("seeking to frame %d: %v", n, err)
+			}
+			if f := plm.Frame(); f == nil || frameHash(f) != frames[n] {
+				t.Errorf("seeking to frame %d landed on another frame", n)
+			}
+			if got := plm.CurrentFrameNumber(); got != n {
+				t.Errorf("current frame number after seeking to frame %d is %d", n, got)
+			}
+			if f, err := plm.NextFrame(); n < testFrames-1 && (err != nil || frameHash(f) != frames[n+1]) {
+				t.Errorf("frame after seeking to frame %d is not frame %d", n, n+1)
+			}
+		}
+		for _, n := range []int{-1, testFrames} {
+			if err := plm.SeekFrame(n); err != ErrSeekFailed {
+				t.Errorf("seeking to frame %d returned %v, want %v", n, err, ErrSeekFailed)
+			}
+		}
+	}
+
+	stream, err := NewPlayerFromReader(bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.SeekFrame(0); err != ErrNotSeekable {
+		t.Errorf("seeking a player that can not seek returned %v, want %v", err, ErrNotSeekable)
+	}
+	if n := stream.FrameCount(); n != -1 {
+		t.Errorf("frame count of a player that can not seek is %d, want -1", n)
+	}
+}
+
+// TestPlayerSeekFrameIBBP seeks to every frame of a video with B frames, which
+// are shown in a different order than they are decoded.
+func TestPlayerSeekFrameIBBP(t *testing.T) {
+	const gops = 4
+	data := encodeTestIBBP(t, gops)
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decoding from the start shows every frame in display order, up to the
+	// P frame the stream ends with.
+	var frames [][sha1.Size]byte
+	for n := 0; ; n++ {
+		f, err := plm.NextFrame()
+		if err != nil {
+			break
+		}
+		want := PictureBidirectional
+		if k := n % len(ibbpOrder); k == 0 {
+			want = PictureIntra
+		} else if k%3 == 0 {
+			want = PicturePredictive
+		}
+		if f.Type != want || f.Time != time.Duration(n)*time.Second/testFrameRate {
+			t.Errorf("frame %d is a %v frame at %v, want a %v frame at %v", n, f.Type, f.Time, want, time.Duration(n)*time.Second/testFrameRate)
+		}
+		if psnr := lumaPSNR(f, n); psnr < 30 {
+			t.Errorf("frame %d does not show image %d, the luma PSNR is %.1f dB", n, n, psnr)
+		}
+		frames = append(frames, frameHash(f))
+	}
+	if len(frames) != gops*len(ibbpOrder) {
+		t.Fatalf("decoded %d frames, want %d", len(frames), gops*len(ibbpOrder))
+	}
+	if err := plm.LastError(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := plm.FrameCount(); n != len(frames) {
+		t.Errorf("frame count is %d, want %d", n, len(frames))
+	}
+	for n := range frames {
+		if err := plm.SeekFrame(n); err != nil {
+			t.Fatalf("seeking to frame %d: %v", n, err)
+		}
+		if f := plm.Frame(); f == nil || frameHash(f) != frames[n] {
+			t.Errorf("seeking to frame %d landed on another frame", n)
+		}
+		if got := plm.CurrentFrameNumber(); got != n {
+			t.Errorf("current frame number after seeking to frame %d is %d", n, got)
+		}
+	}
+}
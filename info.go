@@ -0,0 +1,129 @@
+package mpg
+
+import "time"
+
+// Emphasis is the de-emphasis an MPEG audio stream should be played back with.
+type Emphasis int
+
+const (
+	// EmphasisNone is audio without emphasis.
+	EmphasisNone Emphasis = iota
+	// Emphasis50_15 is 50/15 microsecond emphasis.
+	Emphasis50_15
+	// EmphasisReserved is a reserved value that should not be used.
+	EmphasisReserved
+	// EmphasisCCITT is CCITT J.17 emphasis.
+	EmphasisCCITT
+)
+
+func (e Emphasis) String() string {
+	switch e {
+	case EmphasisNone:
+		return "none"
+	case Emphasis50_15:
+		return "50/15 µs"
+	case EmphasisCCITT:
+		return "CCITT J.17"
+	}
+	return "reserved"
+}
+
+// Info describes the container and the streams of a video, as found in its
+// headers. Fields of a stream that is not present are left at zero.
+type Info struct {
+	// FileSize is the size of the source in bytes. It is 0 if the player can
+	// not seek, and the size of the data written so far for a
+	// "StreamPlayer".
+	FileSize int64
+	// StartTime is the presentation time stamp of the first packet. It is 0
+	// if the player can not seek.
+	StartTime time.Duration
+	// Duration is the same as "Player.Duration".
+	Duration time.Duration
+	// MuxRate is the rate of the whole stream in bytes per second, as found
+	// in the first pack header. Transport streams have none, so it is
+	// estimated from the first two clock references, and is 0 until they
+	// were read.
+	MuxRate int
+	// StreamIDs holds the IDs of the streams listed in the system header or
+	// the program map table (see "Demuxer.StreamIDs"), such as 0xE0 for
+	// video and 0xC0 to 0xC3 for audio.
+	StreamIDs []byte
+
+	// Width and Height are the size of the picture.
+	Width, Height int
+	// FrameRate is the number of frames in a second.
+	FrameRate float64
+	// PixelAspectRatio is the width of a pixel divided by its height.
+	PixelAspectRatio float64
+	// VideoBitRate is the bitrate of the video in bits per second. It is 0 if
+	// the video uses a variable bitrate.
+	VideoBitRate int
+	// VBVBufferSize is the size of the buffer the decoder needs to hold coded
+	// video, in bytes.
+	VBVBufferSize int
+
+	// SampleRate is how many samples per second are in the audio stream.
+	SampleRate int
+	// AudioBitRate is the bitrate of the audio in bits per second. MP3 audio
+	// may change its bitrate from frame to frame.
+	AudioBitRate int
+	// AudioLayer is the layer of the MPEG audio, 1 to 3. Layer II is MP2 and
+	// Layer III is MP3.
+	AudioLayer int
+	// ChannelMode is how the channels of the audio stream are coded.
+	ChannelMode ChannelMode
+	// Emphasis is the de-emphasis the audio should be played back with.
+	Emphasis Emphasis
+	// Copyright is true if the audio is copyrighted.
+	Copyright bool
+	// Original is true if the audio is an original rather than a copy.
+	Original bool
+}
+
+// Info returns the properties of the video found in its headers. It is empty
+// for a "StreamPlayer" until "HasHeaders" returns true.
+func (plm *Player) Info() Info {
+	defer plm.lock()()
+	var info Info
+	p := plm.plm
+	if plm_has_headers(p) != _true {
+		return info
+	}
+	demux := p.Demux
+	info.MuxRate = int(demux.Mux_rate) * 50
+	info.StreamIDs = append([]byte(nil), demux.Stream_ids[:demux.Num_stream_ids]...)
+	if plm.seekable {
+		info.FileSize = int64(plm_buffer_get_size(demux.Buffer))
+		startType := plm_demux_packet_video_1
+		if p.Video_decoder == nil {
+			startType = plm_demux_packet_audio_1 + p.Audio_stream_index
+		}
+		info.StartTime = floatToSecs(plm_demux_get_start_time(demux, startType))
+	}
+	info.Duration = plm.duration()
+	if plm.stream != nil && !plm.stream.closed {
+		info.Duration = UnknownDuration
+	}
+	if video := p.Video_decoder; video != nil {
+		info.Width = int(video.Width)
+		info.Height = int(video.Height)
+		info.FrameRate = video.Framerate
+		info.PixelAspectRatio = plm.pixelAspectRatio()
+		// The largest bitrate marks a variable bitrate.
+		if video.Bitrate != 0x3FFFF {
+			info.VideoBitRate = int(video.Bitrate) * 400
+		}
+		info.VBVBufferSize = int(video.Vbv_buffer_size) * 2048
+	}
+	if audio := p.Audio_decoder; audio != nil {
+		info.SampleRate = int(plm_audio_get_samplerate(audio))
+		info.AudioBitRate = int(plm_audio_get_bitrate(audio)) * 1000
+		info.AudioLayer = int(4 - audio.Layer)
+		info.ChannelMode = ChannelMode(audio.Mode)
+		info.Emphasis = Emphasis(audio.Emphasis)
+		info.Copyright = audio.Copyright == _true
+		info.Original = audio.Original == _true
+	}
+	return info
+}
@@ -0,0 +1,146 @@
+package mpg
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// readBits reads "n" bits of "data", starting "bit" bits after "offset".
+func readBits(data []byte, offset, bit, n int) (v int) {
+	for i := bit; i < bit+n; i++ {
+		v = v<<1 | int(data[offset+i/8]>>(7-i%8)&1)
+	}
+	return v
+}
+
+// writeBits overwrites "n" bits of "data", starting "bit" bits after
+// "offset", with "v".
+func writeBits(data []byte, offset, bit, n, v int) {
+	for i := bit + n - 1; i >= bit; i, v = i-1, v>>1 {
+		mask := byte(1) << (7 - i%8)
+		data[offset+i/8] = data[offset+i/8]&^mask | byte(v&1)<<(7-i%8)
+	}
+}
+
+// testHeaders holds the offsets of the headers in the test file.
+type testHeaders struct {
+	pack, system, sequence, audioFrame int
+	// videoPTS is the PTS of the first video packet.
+	videoPTS int
+}
+
+func findTestHeaders(t testing.TB, data []byte) (h testHeaders) {
+	t.Helper()
+	h.pack = bytes.Index(data, []byte{0x00, 0x00, 0x01, 0xBA})
+	h.system = bytes.Index(data, []byte{0x00, 0x00, 0x01, 0xBB})
+	h.sequence = bytes.Index(data, []byte{0x00, 0x00, 0x01, 0xB3})
+	video := bytes.Index(data, []byte{0x00, 0x00, 0x01, 0xE0})
+	h.videoPTS = readBits(data, video+6, 4, 3)<<30 | readBits(data, video+6, 8, 15)<<15 | readBits(data, video+6, 24, 15)
+	// The first audio packet starts with a frame, after a header with a PTS.
+	packet := bytes.Index(data, []byte{0x00, 0x00, 0x01, 0xC0})
+	h.audioFrame = packet + 11
+	if h.pack < 0 || h.system < 0 || h.sequence < 0 || packet < 0 || readBits(data, h.audioFrame, 0, 12) != 0xFFF {
+		t.Fatal("headers of the test file not found")
+	}
+	return h
+}
+
+func TestInfo(t *testing.T) {
+	data := readTestFile(t, testFile)
+	h := findTestHeaders(t, data)
+	// Fields as they are coded in the headers.
+	muxRate := readBits(data, h.pack+4, 41, 22)
+	bitRate := readBits(data, h.sequence+4, 32, 18)
+	vbvSize := readBits(data, h.sequence+4, 51, 10)
+	layer := readBits(data, h.audioFrame, 13, 2)
+	var streamIDs []byte
+	for i := h.system + 12; data[i]&0x80 != 0; i += 3 {
+		streamIDs = append(streamIDs, data[i])
+	}
+
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := plm.Info()
+	if info.MuxRate != muxRate*50 || info.MuxRate == 0 {
+		t.Errorf("mux rate is %d, want %d", info.MuxRate, muxRate*50)
+	}
+	if !bytes.Equal(info.StreamIDs, []byte{0xE0, 0xC0, 0xC1}) || !bytes.Equal(info.StreamIDs, streamIDs) {
+		t.Errorf("stream IDs are % X, want % X", info.StreamIDs, streamIDs)
+	}
+	startTime := time.Duration(h.videoPTS) * time.Second / 90000
+	if info.FileSize != int64(len(data)) || info.StartTime != startTime || info.Duration != plm.Duration() {
+		t.Errorf("file size, start time and duration are %d, %v and %v, want %d, %v and %v",
+			info.FileSize, info.StartTime, info.Duration, len(data), startTime, plm.Duration())
+	}
+	if info.Width != testWidth || info.Height != testHeight || info.FrameRate != testFrameRate || info.PixelAspectRatio != 1 {
+		t.Errorf("video is %dx%d at %v frames per second with a pixel aspect ratio of %v",
+			info.Width, info.Height, info.FrameRate, info.PixelAspectRatio)
+	}
+	// The encoder marks its bitrate as variable.
+	if bitRate != 0x3FFFF || info.VideoBitRate != 0 {
+		t.Errorf("video bitrate is %d for a field of %#x, want 0 for 0x3FFFF", info.VideoBitRate, bitRate)
+	}
+	if info.VBVBufferSize != vbvSize*2048 || info.VBVBufferSize == 0 {
+		t.Errorf("VBV buffer size is %d, want %d", info.VBVBufferSize, vbvSize*2048)
+	}
+	if info.SampleRate != 44100 || info.AudioBitRate != 192000 || info.ChannelMode != ChannelModeStereo {
+		t.Errorf("audio is %d Hz at %d bits per second in %v mode, want 44100 Hz at 192000 bits per second in stereo mode",
+			info.SampleRate, info.AudioBitRate, info.ChannelMode)
+	}
+	if info.AudioLayer != 4-layer || info.AudioLayer != 2 {
+		t.Errorf("audio layer is %d for a field of %d, want %d", info.AudioLayer, layer, 4-layer)
+	}
+	if info.Emphasis != EmphasisNone || info.Copyright || !info.Original {
+		t.Errorf("audio has emphasis %v, copyright %v and original %v, want none, false and true",
+			info.Emphasis, info.Copyright, info.Original)
+	}
+
+	// Change the fields, and check that they are read from the headers.
+	writeBits(data, h.pack+4, 41, 22, 1234)
+	writeBits(data, h.sequence+4, 32, 18, 5000)
+	writeBits(data, h.sequence+4, 51, 10, 7)
+	// Copyright, original and emphasis
+	writeBits(data, h.audioFrame, 28, 4, 0xB)
+	// Layer I
+	writeBits(data, h.audioFrame, 13, 2, 3)
+	plm, err = NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info = plm.Info()
+	if info.MuxRate != 1234*50 || info.VideoBitRate != 5000*400 || info.VBVBufferSize != 7*2048 {
+		t.Errorf("mux rate, video bitrate and VBV buffer size are %d, %d and %d, want %d, %d and %d",
+			info.MuxRate, info.VideoBitRate, info.VBVBufferSize, 1234*50, 5000*400, 7*2048)
+	}
+	if info.AudioLayer != 1 {
+		t.Errorf("audio layer is %d for a field of 3, want 1", info.AudioLayer)
+	}
+	if info.Emphasis != EmphasisCCITT || !info.Copyright || info.Original {
+		t.Errorf("audio has emphasis %v, copyright %v and original %v, want CCITT J.17, true and false",
+			info.Emphasis, info.Copyright, info.Original)
+	}
+
+	plm, err = NewPlayerFromBytes(readTestFile(t, "testdata/novideo.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info := plm.Info(); info.Width != 0 || info.VBVBufferSize != 0 || info.SampleRate == 0 {
+		t.Errorf("info of a file without video is %+v", info)
+	}
+}
+
+func TestInfoStreamPlayer(t *testing.T) {
+	data := readTestFile(t, testFile)
+	plm := NewStreamPlayer()
+	if info := plm.Info(); info.Width != 0 || info.StreamIDs != nil {
+		t.Errorf("info before any data was written is %+v", info)
+	}
+	plm.Write(data[:len(data)/2])
+	if info := plm.Info(); info.Width != testWidth || info.FileSize != int64(len(data)/2) || info.Duration != UnknownDuration {
+		t.Errorf("info of half the data is %dx%d of %d bytes and %v, want %dx%d of %d bytes and UnknownDuration",
+			info.Width, info.Height, info.FileSize, info.Duration, testWidth, testHeight, len(data)/2)
+	}
+}
@@ -4,10 +4,11 @@ package mpg
 
 import (
 	"bytes"
-	"image"
-	"image/color"
 	"image/draw"
+	"io"
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -19,17 +20,44 @@ type ExpectedHeader struct{}
 
 func (err ExpectedHeader) Error() string { return "Unable to find header" }
 
-// Player processes and decodes video and audio in MPG format (MPEG1 video
-// encoding and MP2 audio encoding)
+// Player processes and decodes video and audio in MPG format (MPEG1 or MPEG2
+// video encoding and MP2 audio encoding). Both program streams and transport
+// streams are read, and which one a source holds is detected when it is opened.
+//
+// A player is safe to use from multiple goroutines, such as an audio goroutine
+// calling "Read" while the game loop calls "Decode" and "Seek".
 type Player struct {
-	plm *plm_t
-
-	frame       frame
-	hasNewFrame bool
-
+	// mu guards the decoder and everything decoded by it.
+	mu       sync.Mutex
+	plm      *plm_t
+	seekable bool
+	stream   *StreamPlayer
+	reader   *readerSource
+	lastErr  error
+
+	frame            *Frame
+	hasNewFrame      int32
+	aspectCorrection bool
+
+	// audioMu guards the audio buffer, so "Read" does not wait for decoding.
+	// It is locked after "mu" when both are needed.
+	audioMu         sync.Mutex
 	audioBuffer     *bytes.Buffer
-	byteDepth       int
+	sampleFormat    SampleFormat
 	maxSampleFrames int
+	// outputRate is 0 if audio is not resampled. It is written while holding
+	// both "mu" and "audioMu".
+	outputRate int
+	quality    ResampleQuality
+	resampler  resampler
+
+	outputChannels int
+	dualChannel    DualChannel
+	// mixed is reused by "mapChannels".
+	mixed []float32
+
+	index     *KeyframeIndex
+	lazyIndex bool
 }
 
 func newPlayer(p *plm_t) (*Player, error) {
@@ -37,14 +65,22 @@ func newPlayer(p *plm_t) (*Player, error) {
 		plm_destroy(p)
 		return nil, ExpectedHeader{}
 	}
+	return initPlayer(p), nil
+}
+
+// initPlayer wraps "p" in a new player, whether headers were found or not.
+func initPlayer(p *plm_t) *Player {
 	plm := new(Player)
 	plm.plm = p
+	plm.seekable = true
 	plm.audioBuffer = new(bytes.Buffer)
-	plm.byteDepth = 2
+	plm.sampleFormat = SampleS16LE
+	plm.quality = ResampleSinc
+	plm.outputChannels = 2
 	plm.SetAudioLeadTime(45 * time.Millisecond)
 	plm_set_video_decode_callback(plm.plm, videoCallback, unsafe.Pointer(plm))
 	plm_set_audio_decode_callback(plm.plm, audioCallback, unsafe.Pointer(plm))
-	return plm, nil
+	return plm
 }
 
 // NewPlayerFromFile creates a new player from a given file. The file is not
@@ -66,57 +102,124 @@ func NewPlayerFromFilename(file string) (*Player, error) {
 
 // NewPlayerFromBytes creates a new player from a list of raw bytes.
 func NewPlayerFromBytes(data []byte) (*Player, error) {
+	if len(data) == 0 {
+		return nil, ExpectedHeader{}
+	}
 	p := plm_create_with_memory(&data[0], uint64(len(data)), _false)
 	return newPlayer(p)
 }
 
 // Close closes the internal player and discards data.
 func (plm *Player) Close() {
-	plm.frame = frame{}
-	plm.audioBuffer.Reset()
+	defer plm.lock()()
+	plm.frame = nil
+	plm.ClearAudioBuffer()
 	plm_destroy(plm.plm)
 	plm.plm = nil
 }
 
+// lock locks the decoder and returns the function that unlocks it.
+func (plm *Player) lock() (unlock func()) {
+	plm.mu.Lock()
+	return plm.mu.Unlock
+}
+
 // *** Video ***
 
 func videoCallback(p *plm_t, f *plm_frame_t, u unsafe.Pointer) {
 	plm := (*Player)(u)
-	plm.frame = frame{f}
-	plm.hasNewFrame = true
+	plm.frame = newFrame(f)
+	plm.setNewFrame(true)
 }
 
 // HasVideo returns true if this file contains video.
-func (plm *Player) HasVideo() bool { return plm_get_num_video_streams(plm.plm) > 0 }
+func (plm *Player) HasVideo() bool { defer plm.lock()(); return plm.hasVideo() }
+
+func (plm *Player) hasVideo() bool { return plm_get_num_video_streams(plm.plm) > 0 }
 
 // NumVideoStreams returns the number of video channels present in the file.
 // (0-1)
-func (plm *Player) NumVideoStreams() int { return int(plm_get_num_video_streams(plm.plm)) }
+func (plm *Player) NumVideoStreams() int {
+	defer plm.lock()()
+	return int(plm_get_num_video_streams(plm.plm))
+}
 
 // SetVideoEnabled sets whether video should be decododed.
-func (plm *Player) SetVideoEnabled(enabled bool) { plm_set_video_enabled(plm.plm, boolToInt(enabled)) }
+func (plm *Player) SetVideoEnabled(enabled bool) {
+	defer plm.lock()()
+	plm_set_video_enabled(plm.plm, boolToInt(enabled))
+}
 
 // VideoEnabled returns true if decoding video is enabled.
-func (plm *Player) VideoEnabled() bool { return plm_get_video_enabled(plm.plm) == _true }
+func (plm *Player) VideoEnabled() bool { defer plm.lock()(); return plm.videoEnabled() }
+
+func (plm *Player) videoEnabled() bool { return plm_get_video_enabled(plm.plm) == _true }
 
 // HasNewFrame returns true if a new frame has been decoded. Drawing the current
 // frame sets this to false
-func (plm *Player) HasNewFrame() bool { return plm.hasNewFrame }
+func (plm *Player) HasNewFrame() bool { return atomic.LoadInt32(&plm.hasNewFrame) != 0 }
+
+func (plm *Player) setNewFrame(set bool) {
+	if set {
+		atomic.StoreInt32(&plm.hasNewFrame, 1)
+	} else {
+		atomic.StoreInt32(&plm.hasNewFrame, 0)
+	}
+}
 
 // FrameRate is the number of frames in a second.
-func (plm *Player) FrameRate() float64 { return plm_get_framerate(plm.plm) }
+func (plm *Player) FrameRate() float64 { defer plm.lock()(); return plm_get_framerate(plm.plm) }
 
 // Width is the width of the video
-func (plm *Player) Width() int { return int(plm_get_width(plm.plm)) }
+func (plm *Player) Width() int { defer plm.lock()(); return int(plm_get_width(plm.plm)) }
 
 // Height is the height of the video.
-func (plm *Player) Height() int { return int(plm_get_height(plm.plm)) }
+func (plm *Player) Height() int { defer plm.lock()(); return int(plm_get_height(plm.plm)) }
+
+// Frame returns the current frame, or nil if no frame was decoded yet.
+func (plm *Player) Frame() *Frame { defer plm.lock()(); return plm.frame }
+
+// NextFrame decodes and returns exactly one frame, regardless of the time. It
+// returns "io.EOF" once the video has ended, or "ErrNoVideo" if there is no
+// video to decode.
+//
+// The returned frame also becomes the current frame used by "DrawTo" and
+// "ReadRGBA". Audio is still buffered while frames are decoded, so disable it
+// with "SetAudioEnabled" if it is not needed.
+func (plm *Player) NextFrame() (*Frame, error) {
+	defer plm.lock()()
+	if !plm.hasVideo() || !plm.videoEnabled() {
+		return nil, ErrNoVideo
+	}
+	f := plm_decode_video(plm.plm)
+	if f == nil && plm.loop() {
+		// The video just looped back to the start.
+		f = plm_decode_video(plm.plm)
+	}
+	plm.collectErrors()
+	if f == nil {
+		return nil, plm.nextError()
+	}
+	plm.frame = newFrame(f)
+	plm.setNewFrame(true)
+	return plm.frame, nil
+}
 
 // *** Audio ***
 
 func audioCallback(p *plm_t, samples *plm_samples_t, u unsafe.Pointer) {
 	plm := (*Player)(u)
-	if l, max := plm.audioBuffer.Len(), plm.maxSampleFrames*plm.byteDepth; max > 0 && l > max*4 {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	in := samples.Interleaved[:samples.Count*2]
+	if rate := int(plm_get_samplerate(p)); plm.outputRate > 0 && rate != plm.outputRate {
+		plm.resampler.configure(plm.quality, rate, plm.outputRate)
+		in = plm.resampler.resample(in)
+	}
+	in = plm.mapChannels(in, ChannelMode(p.Audio_decoder.Mode))
+	bytesPerSample := plm.sampleFormat.BytesPerSample()
+	frameBytes := bytesPerSample * plm.outputChannels
+	if l, max := plm.audioBuffer.Len(), plm.maxSampleFrames*frameBytes; max > 0 && l > max*2 {
 		l -= max
 		var discard [16]byte
 		for l > 16 {
@@ -125,101 +228,296 @@ func audioCallback(p *plm_t, samples *plm_samples_t, u unsafe.Pointer) {
 		}
 		plm.audioBuffer.Read(discard[:l])
 	}
-	plm.audioBuffer.Grow(plm_audio_samples_per_frame * plm.byteDepth * 2)
-	switch plm.byteDepth {
-	case 1:
-		for i := 0; i < len(samples.Interleaved); i++ {
-			b := int8(samples.Interleaved[i] * 0x7F)
-			plm.audioBuffer.WriteByte(byte(b))
-		}
-	case 2:
-		for i := 0; i < len(samples.Interleaved); i++ {
-			b := int16(samples.Interleaved[i] * 0x7FFF)
-			plm.audioBuffer.WriteByte(byte(b))
-			plm.audioBuffer.WriteByte(byte(b >> 8))
-		}
-	case 4:
-		for i := 0; i < len(samples.Interleaved); i++ {
-			b := int32(samples.Interleaved[i] * 0x7FFFFFFF)
-			plm.audioBuffer.WriteByte(byte(b))
-			plm.audioBuffer.WriteByte(byte(b >> 8))
-			plm.audioBuffer.WriteByte(byte(b >> 16))
-			plm.audioBuffer.WriteByte(byte(b >> 24))
-		}
-	}
+	plm.audioBuffer.Grow(len(in) * bytesPerSample)
+	plm.sampleFormat.writeSamples(plm.audioBuffer, in)
 }
 
 // HasAudio returns true if this file contains audio.
-func (plm *Player) HasAudio() bool { return plm_get_num_audio_streams(plm.plm) > 0 }
+func (plm *Player) HasAudio() bool { defer plm.lock()(); return plm.hasAudio() }
+
+func (plm *Player) hasAudio() bool { return plm_get_num_audio_streams(plm.plm) > 0 }
 
 // NumAudioStreams returns the number of audio channels that are present in the
 // file. (0-4)
-func (plm *Player) NumAudioStreams() int { return int(plm_get_num_audio_streams(plm.plm)) }
+func (plm *Player) NumAudioStreams() int {
+	defer plm.lock()()
+	return int(plm_get_num_audio_streams(plm.plm))
+}
 
 // SetAudioEnabled sets whether audio should be decoded.
-func (plm *Player) SetAudioEnabled(enabled bool) { plm_set_audio_enabled(plm.plm, boolToInt(enabled)) }
+func (plm *Player) SetAudioEnabled(enabled bool) {
+	defer plm.lock()()
+	plm_set_audio_enabled(plm.plm, boolToInt(enabled))
+}
+
+// AudioEnabled returns true if decoding audio is enabled.
+func (plm *Player) AudioEnabled() bool { defer plm.lock()(); return plm.audioEnabled() }
 
-//AudioEnabled returns true if decoding audio is enabled.
-func (plm *Player) AudioEnabled() bool { return plm_get_audio_enabled(plm.plm) == _true }
+func (plm *Player) audioEnabled() bool { return plm_get_audio_enabled(plm.plm) == _true }
 
 // HasNewAudio returns true if the audio buffer still contains unread data.
-func (plm *Player) HasNewAudio() bool { return plm.audioBuffer.Len() > 0 }
+func (plm *Player) HasNewAudio() bool {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	return plm.audioBuffer.Len() > 0
+}
 
 // ClearAudioBuffer clears the audio buffer.
-func (plm *Player) ClearAudioBuffer() { plm.audioBuffer.Reset() }
+func (plm *Player) ClearAudioBuffer() {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	plm.audioBuffer.Reset()
+	plm.resampler.reset()
+}
+
+// resetResampler discards audio kept by the resampler, so it does not blend
+// into audio after a seek.
+func (plm *Player) resetResampler() {
+	plm.audioMu.Lock()
+	plm.resampler.reset()
+	plm.audioMu.Unlock()
+}
+
+// Channels is the number of channels in the audio stream, 1 for mono and 2
+// otherwise. It is 0 if there is no audio.
+func (plm *Player) Channels() int {
+	defer plm.lock()()
+	if plm_get_samplerate(plm.plm) == 0 {
+		return 0
+	}
+	if ChannelMode(plm.plm.Audio_decoder.Mode) == ChannelModeMono {
+		return 1
+	}
+	return 2
+}
+
+// ChannelMode is how the channels of the audio stream are coded. It is
+// "ChannelModeStereo" if there is no audio.
+func (plm *Player) ChannelMode() ChannelMode {
+	defer plm.lock()()
+	if plm_get_samplerate(plm.plm) == 0 {
+		return ChannelModeStereo
+	}
+	return ChannelMode(plm.plm.Audio_decoder.Mode)
+}
+
+// OutputChannels is the number of channels written to the audio buffer read
+// by "Read". This is 2 by default, even for mono audio.
+func (plm *Player) OutputChannels() int {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	return plm.outputChannels
+}
+
+// SetOutputChannels sets the number of channels written to the audio buffer
+// and clears the audio buffer. With 1 channel, stereo audio is downmixed by
+// averaging the left and right channel. Values other than 1 and 2 are ignored.
+func (plm *Player) SetOutputChannels(channels int) {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	if channels == 1 || channels == 2 {
+		plm.audioBuffer.Reset()
+		plm.outputChannels = channels
+	}
+}
+
+// DualChannel is which channels of a "ChannelModeDualChannel" stream are
+// played. This is "DualChannelBoth" by default.
+func (plm *Player) DualChannel() DualChannel {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	return plm.dualChannel
+}
+
+// SetDualChannel selects which channels of a "ChannelModeDualChannel" stream
+// are played, such as one language of a bilingual broadcast. It has no effect
+// on other streams. Unknown values are ignored.
+func (plm *Player) SetDualChannel(dual DualChannel) {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	if dual >= DualChannelBoth && dual <= DualChannelSecond {
+		plm.dualChannel = dual
+	}
+}
 
 // SampleRate is how many samples per second in the audio stream.
-func (plm *Player) SampleRate() int { return int(plm_get_samplerate(plm.plm)) }
+func (plm *Player) SampleRate() int { defer plm.lock()(); return int(plm_get_samplerate(plm.plm)) }
+
+// OutputSampleRate is how many samples per second are written to the audio
+// buffer read by "Read". This is "SampleRate" unless set with
+// "SetOutputSampleRate".
+func (plm *Player) OutputSampleRate() int { defer plm.lock()(); return plm.outputSampleRate() }
+
+func (plm *Player) outputSampleRate() int {
+	if plm.outputRate > 0 {
+		return plm.outputRate
+	}
+	return int(plm_get_samplerate(plm.plm))
+}
+
+// SetOutputSampleRate resamples audio written to the audio buffer to "rate"
+// samples per second, so players of videos with different sample rates can
+// share one audio context. A rate of 0 turns resampling off. This clears the
+// audio buffer.
+func (plm *Player) SetOutputSampleRate(rate int) {
+	if rate < 0 {
+		return
+	}
+	defer plm.lock()()
+	plm.audioMu.Lock()
+	plm.outputRate = rate
+	plm.audioBuffer.Reset()
+	plm.resampler.reset()
+	plm.audioMu.Unlock()
+	// The audio buffer size depends on the sample rate.
+	plm.setAudioLeadTime(plm.audioLeadTime())
+}
+
+// ResampleQuality is the algorithm used by "SetOutputSampleRate". This is
+// "ResampleSinc" by default.
+func (plm *Player) ResampleQuality() ResampleQuality {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	return plm.quality
+}
+
+// SetResampleQuality sets the algorithm used by "SetOutputSampleRate". Unknown
+// algorithms are ignored.
+func (plm *Player) SetResampleQuality(quality ResampleQuality) {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	if quality == ResampleLinear || quality == ResampleSinc {
+		plm.quality = quality
+	}
+}
 
 // SetAudioLeadTime sets how long the audio is decoded in advance or behind the
 // video decode time. this is typically set to the duration of the buffer of
 // your audio API.
 func (plm *Player) SetAudioLeadTime(time time.Duration) {
-	plm.maxSampleFrames = int(time.Seconds() * float64(plm.SampleRate()) * 1.2)
+	defer plm.lock()()
+	plm.setAudioLeadTime(time)
+}
+
+func (plm *Player) setAudioLeadTime(time time.Duration) {
+	plm.audioMu.Lock()
+	plm.maxSampleFrames = int(time.Seconds() * float64(plm.outputSampleRate()) * 1.2)
+	plm.audioMu.Unlock()
 	plm_set_audio_lead_time(plm.plm, time.Seconds())
 }
 
 // AudioLeadTime is how long the audio is decoded in advance or behind the video
 // decode time.
-func (plm *Player) AudioLeadTime() time.Duration {
+func (plm *Player) AudioLeadTime() time.Duration { defer plm.lock()(); return plm.audioLeadTime() }
+
+func (plm *Player) audioLeadTime() time.Duration {
 	return floatToSecs(plm_get_audio_lead_time(plm.plm))
 }
 
-// ByteDepth is how many bytes are in each.
-func (plm *Player) ByteDepth() int { return plm.byteDepth }
+// SampleFormat is the format audio is decoded as. This is "SampleS16LE" by
+// default.
+func (plm *Player) SampleFormat() SampleFormat {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	return plm.sampleFormat
+}
 
-// SetByteDepth sets how many bytes per sample audio is decoded as. Currently
-// the values 1 (8-bit), 2 (16-bit), and 4 (32-bit) are supported.
-func (plm *Player) SetByteDepth(depth int) {
-	if depth == 1 || depth == 2 || depth == 4 {
+// SetSampleFormat sets the format audio is decoded as and clears the audio
+// buffer. Unknown formats are ignored.
+func (plm *Player) SetSampleFormat(format SampleFormat) {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
+	if format >= SampleF32LE && format <= SampleU8 {
 		plm.audioBuffer.Reset()
-		plm.byteDepth = depth
+		plm.sampleFormat = format
+	}
+}
+
+// NextSamples decodes and returns exactly one frame of audio, regardless of the
+// time. Decoded samples are not written to the audio buffer read by "Read".
+// It returns "io.EOF" once the video has ended, or "ErrNoAudio" if there is no
+// audio to decode.
+//
+// Video is still buffered while audio is decoded, so disable it with
+// "SetVideoEnabled" if it is not needed.
+func (plm *Player) NextSamples() (*Samples, error) {
+	defer plm.lock()()
+	if !plm.hasAudio() || !plm.audioEnabled() {
+		return nil, ErrNoAudio
+	}
+	s := plm_decode_audio(plm.plm)
+	if s == nil && plm.loop() {
+		s = plm_decode_audio(plm.plm)
+	}
+	plm.collectErrors()
+	if s == nil {
+		return nil, plm.nextError()
 	}
+	return newSamples(s), nil
 }
 
 // *** Both ***
 
 // Time is how far the video has progressed.
-func (plm *Player) Time() time.Duration { return floatToSecs(plm_get_time(plm.plm)) }
+func (plm *Player) Time() time.Duration {
+	defer plm.lock()()
+	return floatToSecs(plm_get_time(plm.plm))
+}
+
+// Duration is how long the entire video is. This is "UnknownDuration" if the
+// player can not seek.
+func (plm *Player) Duration() time.Duration { defer plm.lock()(); return plm.duration() }
+
+func (plm *Player) duration() time.Duration {
+	if !plm.seekable {
+		return UnknownDuration
+	}
+	return floatToSecs(plm_get_duration(plm.plm))
+}
 
-// Duration is how long the entire video is.
-func (plm *Player) Duration() time.Duration { return floatToSecs(plm_get_duration(plm.plm)) }
+// Seekable returns true if the player is able to seek, rewind and loop. This is
+// false for players created from readers that can not seek.
+func (plm *Player) Seekable() bool { return plm.seekable }
 
-// Rewind moves to the beginning.
-func (plm *Player) Rewind() { plm_rewind(plm.plm) }
+// Rewind moves to the beginning. This does nothing if the player can not seek.
+func (plm *Player) Rewind() {
+	defer plm.lock()()
+	if plm.seekable {
+		plm.resetResampler()
+		plm_rewind(plm.plm)
+	}
+}
 
 // Loop returns true if the video was set to loop when finished.
-func (plm *Player) Loop() bool { return plm_get_loop(plm.plm) == _true }
+func (plm *Player) Loop() bool { defer plm.lock()(); return plm.loop() }
+
+func (plm *Player) loop() bool { return plm_get_loop(plm.plm) == _true }
 
-// SetLoop sets whether the video should loop when finished.
-func (plm *Player) SetLoop(set bool) { plm_set_loop(plm.plm, boolToInt(set)) }
+// SetLoop sets whether the video should loop when finished. Looping can not be
+// enabled if the player can not seek.
+func (plm *Player) SetLoop(set bool) {
+	defer plm.lock()()
+	if plm.seekable {
+		plm_set_loop(plm.plm, boolToInt(set))
+	}
+}
 
 // Finished returns true when the video has ended. This is always false when
 // looping.
-func (plm *Player) Finished() bool { return plm_has_ended(plm.plm) == _true }
+func (plm *Player) Finished() bool { defer plm.lock()(); return plm.finished() }
+
+func (plm *Player) finished() bool { return plm_has_ended(plm.plm) == _true }
 
 // Decode processes the video accordingly to the duration "elapsed".
-func (plm *Player) Decode(elapsed time.Duration) { plm_decode(plm.plm, elapsed.Seconds()) }
+func (plm *Player) Decode(elapsed time.Duration) {
+	defer plm.lock()()
+	plm.decode(elapsed)
+}
+
+func (plm *Player) decode(elapsed time.Duration) {
+	plm_decode(plm.plm, elapsed.Seconds())
+	plm.collectErrors()
+}
 
 // Seek to the specified time.
 //
@@ -227,9 +525,68 @@ func (plm *Player) Decode(elapsed time.Duration) { plm_decode(plm.plm, elapsed.S
 // If "exact" is true, this will seek to the exact time. this can be slower
 // as each frame since the last intra frame would need to be decoded.
 //
-// Seek returns true when successful.
-func (plm *Player) Seek(time time.Duration, exact bool) bool {
-	return plm_seek(plm.plm, time.Seconds(), boolToInt(exact)) == _true
+// The intra frame is found through the index if the player has one (see
+// "BuildIndex"), and estimated from the bitrate otherwise. With an index,
+// frames are also counted from that intra frame instead of derived from
+// timestamps, like in "SeekFrame". This applies to "DrawFrameAt" and
+// "ReadRGBAAt" too.
+//
+// Seek returns "ErrNotSeekable" if the player can not seek, and
+// "ErrSeekFailed" if no frame was found.
+func (plm *Player) Seek(time time.Duration, exact bool) error {
+	defer plm.lock()()
+	if !plm.seekable {
+		return ErrNotSeekable
+	}
+	plm.resetResampler()
+	plm.prepareIndex()
+	if plm.index != nil && plm.hasVideo() && plm.videoEnabled() {
+		f, err := plm.seekIndexedTime(time, exact)
+		plm.collectErrors()
+		if err != nil {
+			return err
+		}
+		plm_seek_resume(plm.plm, f)
+		return nil
+	}
+	ok := plm_seek(plm.plm, time.Seconds(), boolToInt(exact)) == _true
+	plm.collectErrors()
+	if !ok {
+		return ErrSeekFailed
+	}
+	return nil
+}
+
+// seekFrame seeks like "Seek" and returns the frame at the new position.
+func (plm *Player) seekFrame(elapsed time.Duration, exact bool) (*plm_frame_t, error) {
+	if !plm.seekable {
+		return nil, ErrNotSeekable
+	}
+	if !plm.hasVideo() || !plm.videoEnabled() {
+		return nil, ErrNoVideo
+	}
+	plm.resetResampler()
+	plm.prepareIndex()
+	if plm.index != nil {
+		f, err := plm.seekIndexedTime(elapsed, exact)
+		plm.collectErrors()
+		return f, err
+	}
+	f := plm_seek_frame(plm.plm, elapsed.Seconds(), boolToInt(exact))
+	plm.collectErrors()
+	if f == nil {
+		return nil, ErrSeekFailed
+	}
+	return f, nil
+}
+
+// nextError is returned by "NextFrame" and "NextSamples" when nothing could be
+// decoded.
+func (plm *Player) nextError() error {
+	if plm.stream != nil && !plm.stream.closed && !plm.finished() {
+		return ErrNeedMoreData
+	}
+	return io.EOF
 }
 
 // Read consumes and reads data from the audio buffer. If the audio buffer does
@@ -238,6 +595,8 @@ func (plm *Player) Seek(time time.Duration, exact bool) bool {
 // This is intended to make it easy to create an Ebiten or Oto player straight
 // from this *Player
 func (plm *Player) Read(buf []byte) (n int, err error) {
+	plm.audioMu.Lock()
+	defer plm.audioMu.Unlock()
 	n, _ = plm.audioBuffer.Read(buf)
 	if n == 0 {
 		n = len(buf)
@@ -250,42 +609,18 @@ func (plm *Player) Read(buf []byte) (n int, err error) {
 
 // *** frame ***
 
-// frame makes it easier to use and draw "plm_frame_t" as an "image.Image"
-type frame struct {
-	*plm_frame_t
-}
-
-func (frame frame) ColorModel() color.Model { return color.YCbCrModel }
-
-func (frame frame) Bounds() image.Rectangle {
-	return image.Rectangle{
-		image.Point{},
-		image.Point{int(frame.plm_frame_t.Width), int(frame.plm_frame_t.Height)},
-	}
-}
-
-var ycbcrBlack = color.YCbCrModel.Convert(color.Black)
-
-func (frame frame) At(x, y int) color.Color {
-	width, height := int(frame.plm_frame_t.Width), int(frame.plm_frame_t.Height)
-	if x+y*width < width*height {
-		yIndex := x + y*width
-		cIndex := x/2 + (y/2)*(width/2)
-		return color.YCbCr{
-			Y:  index(frame.Y.Data, yIndex),
-			Cr: index(frame.Cr.Data, cIndex),
-			Cb: index(frame.Cb.Data, cIndex),
-		}
-	}
-	return ycbcrBlack
-}
-
-// DrawTo draws the current frame to the image in "img".
+// DrawTo draws the current frame to the image in "img". If
+// "SetAspectCorrection" is enabled, the frame is scaled to fit "img" at its
+// display aspect ratio.
 func (plm *Player) DrawTo(img draw.Image) {
-	if plm.frame.plm_frame_t != nil {
-		draw.Draw(img, plm.frame.Bounds(), plm.frame, image.Point{}, draw.Src)
+	defer plm.lock()()
+	if plm.frame != nil && plm.aspectCorrection {
+		width, height := plm.displaySize()
+		plm.frame.drawScaled(img, fitRect(img.Bounds(), width, height))
+	} else if plm.frame != nil {
+		plm.frame.draw(img)
 	}
-	plm.hasNewFrame = false
+	plm.setNewFrame(false)
 }
 
 // ReadRGBA overwrites the passed byte array in "data" in RGBA format.
@@ -293,17 +628,19 @@ func (plm *Player) DrawTo(img draw.Image) {
 //
 // Alpha channels remain unchanged.
 //
-// ReadRGBA panics if the size of data does not match the size of the
-// frame (width * height * 4).
-func (plm *Player) ReadRGBA(data []byte) {
-	if plm.frame.plm_frame_t != nil {
-		width, height := plm.Width(), plm.Height()
+// ReadRGBA returns "ErrBufferSize" if the size of data does not match the size
+// of the frame (width * height * 4).
+func (plm *Player) ReadRGBA(data []byte) error {
+	defer plm.lock()()
+	if plm.frame != nil {
+		width, height := plm.frame.Width, plm.frame.Height
 		if len(data) != width*height*4 {
-			panic("data should be the same size as Player")
+			return ErrBufferSize
 		}
-		plm_frame_to_rgba(plm.frame.plm_frame_t, bytesToUintPtr(data), int64(width)*4)
+		plm.frame.readRGBA(data, width*4)
 	}
-	plm.hasNewFrame = false
+	plm.setNewFrame(false)
+	return nil
 }
 
 // DrawFrameAt seeks to the specified time and draws the current frame to the
@@ -313,15 +650,16 @@ func (plm *Player) ReadRGBA(data []byte) {
 // If "exact" is true, this will seek to the exact time. this can be slower as
 // each frame since the last intra frame would need to be decoded.
 //
-// DrawFrameAt returns true when successful.
-func (plm *Player) DrawFrameAt(img draw.Image, elapsed time.Duration, exact bool) bool {
-	f := plm_seek_frame(plm.plm, elapsed.Seconds(), boolToInt(exact))
-	if f == nil {
-		return false
+// DrawFrameAt returns "ErrNotSeekable" if the player can not seek,
+// "ErrNoVideo" if there is no video and "ErrSeekFailed" if no frame was found.
+func (plm *Player) DrawFrameAt(img draw.Image, elapsed time.Duration, exact bool) error {
+	defer plm.lock()()
+	f, err := plm.seekFrame(elapsed, exact)
+	if err != nil {
+		return err
 	}
-	src := frame{f}
-	draw.Draw(img, img.Bounds(), src, image.Point{}, draw.Src)
-	return true
+	newFrame(f).draw(img)
+	return nil
 }
 
 // ReadRGBAAt seeks to the specified time and overwrites the passed
@@ -332,21 +670,21 @@ func (plm *Player) DrawFrameAt(img draw.Image, elapsed time.Duration, exact bool
 // If "exact" is true, this will seek to the exact time. this can be slower as
 // each frame since the last intra frame would need to be decoded.
 //
-// ReadRGBAAt returns true when successful.
-//
 // Alpha channels remain unchanged.
 //
-// ReadRGBAAt panics if the size of data does not match the size of the
-// frame (width * height * 4).
-func (plm *Player) ReadRGBAAt(data []byte, elapsed time.Duration, exact bool) bool {
-	width, height := plm.Width(), plm.Height()
+// ReadRGBAAt returns "ErrBufferSize" if the size of data does not match the
+// size of the frame (width * height * 4). Otherwise it fails like
+// "DrawFrameAt".
+func (plm *Player) ReadRGBAAt(data []byte, elapsed time.Duration, exact bool) error {
+	defer plm.lock()()
+	width, height := int(plm_get_width(plm.plm)), int(plm_get_height(plm.plm))
 	if len(data) != width*height*4 {
-		panic("image.RGBA should be the same size as Player")
+		return ErrBufferSize
 	}
-	f := plm_seek_frame(plm.plm, elapsed.Seconds(), boolToInt(exact))
-	if f == nil {
-		return false
+	f, err := plm.seekFrame(elapsed, exact)
+	if err != nil {
+		return err
 	}
-	plm_frame_to_rgba(f, bytesToUintPtr(data), int64(width*4))
-	return true
+	newFrame(f).readRGBA(data, width*4)
+	return nil
 }
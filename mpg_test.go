@@ -0,0 +1,179 @@
+package mpg
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// testdata/test.mpg is a 64x48 MPG file of 50 frames at 25 frames per second,
+// with an intra frame every 5 frames. It has two MP2 audio streams at 44100 Hz.
+const (
+	testFile      = "testdata/test.mpg"
+	testWidth     = 64
+	testHeight    = 48
+	testFrameRate = 25
+	testFrames    = 50
+	testGOPSize   = 5
+)
+
+// testdata/novideo.mpg only has an MP2 audio stream, and testdata/odd.mpg is a
+// 35x27 video of 5 frames without audio.
+//
+// All test files were made by the encoders and the muxer of this package, as
+// "TestFixtures" checks.
+
+// testImage returns frame "n" of a test pattern: a gradient with a square that
+// moves by a few pixels every frame, so every frame looks different.
+func testImage(n int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, testWidth, testHeight))
+	x0, y0 := (n*3)%(testWidth-16), (n*2)%(testHeight-16)
+	for y := 0; y < testHeight; y++ {
+		for x := 0; x < testWidth; x++ {
+			c := color.RGBA{uint8(x * 4), uint8(y * 5), uint8(128 + n), 255}
+			if x >= x0 && x < x0+16 && y >= y0 && y < y0+16 {
+				c = color.RGBA{240, 240, 32, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// testTone returns "count" interleaved stereo samples of a sine of "freq" Hz
+// on the left channel and of 1.5 times "freq" on the right channel.
+func testTone(freq float64, rate, start, count int) []float32 {
+	samples := make([]float32, 0, count*2)
+	for i := start; i < start+count; i++ {
+		t := float64(i) / float64(rate)
+		samples = append(samples,
+			float32(0.4*math.Sin(2*math.Pi*freq*t)),
+			float32(0.3*math.Sin(2*math.Pi*freq*1.5*t)))
+	}
+	return samples
+}
+
+// encodeTestVideo encodes "frames" frames of "testImage" at the given size and,
+// for every audio stream, a tone of "testTone" with a different frequency, and
+// muxes them into an MPG file.
+func encodeTestVideo(t testing.TB, width, height, frames, audioStreams int) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	mux, err := NewMuxer(&out, MuxerOptions{Video: true, AudioStreams: audioStreams})
+	if err != nil {
+		t.Fatal(err)
+	}
+	video, err := NewVideoEncoder(VideoEncoderOptions{Width: width, Height: height, FrameRate: testFrameRate, GOPSize: testGOPSize, Quantizer: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio := make([]*AudioEncoder, audioStreams)
+	for i := range audio {
+		if audio[i], err = NewAudioEncoder(AudioEncoderOptions{SampleRate: 44100, BitRate: 192000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samplesPerFrame := 44100 / testFrameRate
+	for n := 0; n < frames; n++ {
+		data, err := video.Encode(testImage(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mux.WriteVideo(data, time.Duration(n)*time.Second/testFrameRate); err != nil {
+			t.Fatal(err)
+		}
+		for i, enc := range audio {
+			pts := time.Duration(enc.Frames()) * time.Second * 1152 / 44100
+			data := enc.EncodeFloat32(testTone(440*float64(i+1), 44100, n*samplesPerFrame, samplesPerFrame))
+			if err := mux.WriteAudio(i, data, pts); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for i, enc := range audio {
+		pts := time.Duration(enc.Frames()) * time.Second * 1152 / 44100
+		if err := mux.WriteAudio(i, enc.Flush(), pts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mux.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// encodeTestAudio encodes "frames" frames of "testTone" as an MP2 stream.
+func encodeTestAudio(t testing.TB, frames int) []byte {
+	t.Helper()
+	enc, err := NewAudioEncoder(AudioEncoderOptions{SampleRate: 44100, BitRate: 192000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte(nil), enc.EncodeFloat32(testTone(440, 44100, 0, frames*1152))...)
+	return append(data, enc.Flush()...)
+}
+
+// TestFixtures checks that the test files are what the encoders and the muxer
+// produce, so they can be made again after changing them.
+func TestFixtures(t *testing.T) {
+	mp2 := encodeTestAudio(t, 10)
+	var novideo bytes.Buffer
+	mux, err := NewMuxer(&novideo, MuxerOptions{AudioStreams: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mux.WriteAudio(0, mp2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mux.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		testFile:               encodeTestVideo(t, testWidth, testHeight, testFrames, 2),
+		"testdata/odd.mpg":     encodeTestVideo(t, 35, 27, 5, 0),
+		"testdata/test.mp2":    mp2,
+		"testdata/novideo.mpg": novideo.Bytes(),
+	} {
+		if !bytes.Equal(readTestFile(t, name), data) {
+			t.Errorf("%s differs from the output of the encoders", name)
+		}
+	}
+}
+
+// readTestFile returns the contents of a file in testdata.
+func readTestFile(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// frameHash identifies the picture of a frame.
+func frameHash(f *Frame) [sha1.Size]byte {
+	h := sha1.New()
+	for _, plane := range [][]byte{f.Y, f.Cb, f.Cr} {
+		h.Write(plane)
+	}
+	var sum [sha1.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// decodeFrames decodes all frames of "plm" and returns their hashes.
+func decodeFrames(t testing.TB, plm *Player) (hashes [][sha1.Size]byte) {
+	t.Helper()
+	for {
+		f := plm_decode_video(plm.plm)
+		if f == nil {
+			return hashes
+		}
+		hashes = append(hashes, frameHash(newFrame(f)))
+	}
+}
@@ -0,0 +1,289 @@
+package mpg
+
+import (
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// MuxerOptions configures a "Muxer".
+type MuxerOptions struct {
+	// Video enables the video stream.
+	Video bool
+	// AudioStreams is the number of audio streams, up to 4.
+	AudioStreams int
+	// MuxRate is the rate at which the decoder is expected to receive data in
+	// bytes per second. It is used for the system clock reference written to
+	// every pack. Defaults to 1 MiB per second.
+	MuxRate int
+	// PackSize is the maximum size of a pack in bytes. Defaults to 2048, the
+	// sector size of Video CDs.
+	PackSize int
+}
+
+// muxerDelay is how far presentation time stamps lead the system clock
+// reference of the first pack, so the decoder can buffer data before it has to
+// show it.
+const muxerDelay = 500 * time.Millisecond
+
+const (
+	muxPESHeaderSize   = 6
+	muxTimestampSize   = 5
+	muxMaxAudioStreams = 4
+)
+
+// muxChunk is an access unit waiting to be written.
+type muxChunk struct {
+	stream int
+	data   []byte
+	pts    time.Duration
+}
+
+// Muxer writes one MPEG1 video stream and up to 4 MP2 audio streams to an MPG
+// file (an MPEG1 program stream) that can be played by "Player".
+//
+// Chunks passed to "WriteVideo" and "WriteAudio" are interleaved by their
+// presentation time, so all streams should be written at a similar pace.
+// Chunks are held back until every stream has data, or the muxer is closed.
+//
+// A muxer is safe to use from multiple goroutines, such as one encoding video
+// and one encoding audio.
+type Muxer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	opts    MuxerOptions
+	streams []byte
+	queues  [][]muxChunk
+	written int64
+	packs   int
+	closed  bool
+	err     error
+	lastSCR time.Duration
+	header  []byte
+}
+
+// NewMuxer creates a new muxer writing to "w". It returns "ErrInvalidOptions"
+// if there are no streams, more than 4 audio streams, or the mux rate or pack
+// size is out of range.
+func NewMuxer(w io.Writer, opts MuxerOptions) (*Muxer, error) {
+	if opts.AudioStreams < 0 || opts.AudioStreams > muxMaxAudioStreams || (!opts.Video && opts.AudioStreams == 0) {
+		return nil, ErrInvalidOptions
+	}
+	if opts.MuxRate == 0 {
+		opts.MuxRate = 1 << 20
+	}
+	if opts.PackSize == 0 {
+		opts.PackSize = 2048
+	}
+	if opts.MuxRate < 50 || opts.PackSize < 256 || opts.PackSize > 65535 {
+		return nil, ErrInvalidOptions
+	}
+	m := &Muxer{w: w, opts: opts}
+	if opts.Video {
+		m.streams = append(m.streams, byte(plm_demux_packet_video_1))
+	}
+	for i := 0; i < opts.AudioStreams; i++ {
+		m.streams = append(m.streams, byte(plm_demux_packet_audio_1+int64(i)))
+	}
+	m.queues = make([][]muxChunk, len(m.streams))
+	return m, nil
+}
+
+// lock locks the muxer and returns the function that unlocks it.
+func (m *Muxer) lock() (unlock func()) {
+	m.mu.Lock()
+	return m.mu.Unlock
+}
+
+// WriteVideo queues an access unit of the video stream, usually a coded
+// picture returned by "VideoEncoder.Encode". "pts" is the presentation time of
+// the picture. It returns "ErrNoVideo" if the muxer has no video stream.
+func (m *Muxer) WriteVideo(data []byte, pts time.Duration) error {
+	defer m.lock()()
+	if !m.opts.Video {
+		return ErrNoVideo
+	}
+	return m.write(0, data, pts)
+}
+
+// WriteAudio queues one or more audio frames of the audio stream "stream",
+// usually returned by "AudioEncoder". "pts" is the presentation time of the
+// first frame. It returns "ErrAudioStream" if there is no such stream.
+func (m *Muxer) WriteAudio(stream int, data []byte, pts time.Duration) error {
+	defer m.lock()()
+	if stream < 0 || stream >= m.opts.AudioStreams {
+		return ErrAudioStream
+	}
+	if m.opts.Video {
+		stream++
+	}
+	return m.write(stream, data, pts)
+}
+
+func (m *Muxer) write(stream int, data []byte, pts time.Duration) error {
+	if m.closed {
+		return ErrMuxerClosed
+	}
+	if m.err != nil {
+		return m.err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	m.queues[stream] = append(m.queues[stream], muxChunk{stream, append([]byte(nil), data...), pts})
+	return m.flush(false)
+}
+
+// Close writes all queued chunks and the program end code. It does not close
+// the underlying writer.
+func (m *Muxer) Close() error {
+	defer m.lock()()
+	if m.closed {
+		return m.err
+	}
+	if err := m.flush(true); err != nil {
+		return err
+	}
+	m.closed = true
+	_, m.err = m.w.Write([]byte{0x00, 0x00, 0x01, byte(plm_start_end)})
+	return m.err
+}
+
+// flush writes queued chunks in order of their presentation time, for as long
+// as every stream has a chunk queued, or until all queues are empty if "all"
+// is set.
+func (m *Muxer) flush(all bool) error {
+	for {
+		next := -1
+		for i, queue := range m.queues {
+			if len(queue) == 0 {
+				if all {
+					continue
+				}
+				return nil
+			}
+			if next < 0 || queue[0].pts < m.queues[next][0].pts {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		chunk := m.queues[next][0]
+		m.queues[next] = m.queues[next][1:]
+		if err := m.writeChunk(chunk); err != nil {
+			m.err = err
+			return err
+		}
+	}
+}
+
+// writeChunk splits a chunk into packs holding a single packet each. Only the
+// first packet carries the presentation time stamp.
+func (m *Muxer) writeChunk(chunk muxChunk) error {
+	pts := chunk.pts + muxerDelay
+	data := chunk.data
+	first := true
+	for len(data) > 0 || first {
+		m.header = m.header[:0]
+		m.writePackHeader(pts)
+		if m.packs == 0 {
+			m.writeSystemHeader()
+		}
+		timestamp := 1
+		if first {
+			timestamp = muxTimestampSize
+		}
+		size := m.opts.PackSize - len(m.header) - muxPESHeaderSize - timestamp
+		if size > len(data) {
+			size = len(data)
+		}
+		h := append(m.header, 0x00, 0x00, 0x01, m.streams[chunk.stream])
+		length := timestamp + size
+		h = append(h, byte(length>>8), byte(length))
+		if first {
+			h = appendTimestamp(h, 0x2, pts)
+		} else {
+			// No time stamp.
+			h = append(h, 0x0F)
+		}
+		m.header = h
+		if _, err := m.w.Write(m.header); err != nil {
+			return err
+		}
+		if _, err := m.w.Write(data[:size]); err != nil {
+			return err
+		}
+		m.written += int64(len(m.header) + size)
+		m.packs++
+		data = data[size:]
+		first = false
+	}
+	return nil
+}
+
+// writePackHeader appends a pack header. The system clock reference is derived
+// from the number of bytes written and the mux rate, but never lags more than
+// "muxerDelay" behind the presentation time of the data in the pack.
+func (m *Muxer) writePackHeader(pts time.Duration) {
+	scr := time.Duration(float64(m.written) / float64(m.opts.MuxRate) * float64(time.Second))
+	if scr < pts-muxerDelay {
+		scr = pts - muxerDelay
+	}
+	if scr < m.lastSCR {
+		scr = m.lastSCR
+	}
+	m.lastSCR = scr
+	m.header = append(m.header, 0x00, 0x00, 0x01, byte(plm_start_pack))
+	m.header = appendTimestamp(m.header, 0x2, scr)
+	rate := (m.opts.MuxRate + 49) / 50
+	m.header = append(m.header,
+		0x80|byte(rate>>15),
+		byte(rate>>7),
+		byte(rate<<1)|0x01,
+	)
+}
+
+// writeSystemHeader appends the system header that lists all streams.
+func (m *Muxer) writeSystemHeader() {
+	rate := (m.opts.MuxRate + 49) / 50
+	video := 0
+	if m.opts.Video {
+		video = 1
+	}
+	length := 6 + 3*len(m.streams)
+	h := append(m.header, 0x00, 0x00, 0x01, byte(plm_start_system), byte(length>>8), byte(length))
+	h = append(h,
+		0x80|byte(rate>>15),
+		byte(rate>>7),
+		byte(rate<<1)|0x01,
+		byte(m.opts.AudioStreams<<2), // audio_bound, fixed_flag and CSPS_flag unset
+		0xE0|byte(video),             // both locks set, marker and video_bound
+		0xFF,                         // reserved_byte
+	)
+	for _, id := range m.streams {
+		if id == byte(plm_demux_packet_video_1) {
+			// 46 units of 1024 bytes, the video buffer size of the system
+			// target decoder.
+			h = append(h, id, 0xE0, 46)
+		} else {
+			// 32 units of 128 bytes.
+			h = append(h, id, 0xC0, 32)
+		}
+	}
+	m.header = h
+}
+
+// appendTimestamp appends a 33 bit time stamp of 90kHz ticks with the 4 bit
+// prefix "prefix", as it is read by "plm_demux_decode_time".
+func appendTimestamp(data []byte, prefix byte, t time.Duration) []byte {
+	ticks := uint64(math.Round(t.Seconds()*90000)) & (1<<33 - 1)
+	return append(data,
+		prefix<<4|byte(ticks>>29)&0x0E|0x01,
+		byte(ticks>>22),
+		byte(ticks>>14)|0x01,
+		byte(ticks>>7),
+		byte(ticks<<1)|0x01,
+	)
+}
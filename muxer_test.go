@@ -0,0 +1,185 @@
+package mpg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// errWriter fails every write.
+type errWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (errWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+// testChunk is an access unit of the test file: the payload of a packet with a
+// time stamp and of the packets without one that follow it.
+type testChunk struct {
+	id   byte
+	pts  time.Duration
+	data []byte
+}
+
+// demuxChunks splits the program stream "data" into the chunks of each stream.
+func demuxChunks(t testing.TB, data []byte) (chunks []testChunk) {
+	t.Helper()
+	d, err := NewDemuxerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	last := map[byte]int{}
+	for {
+		p, err := d.NextPacket()
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i, ok := last[p.StreamID]; ok && p.PTS == NoTimestamp {
+			chunks[i].data = append(chunks[i].data, p.Data...)
+			continue
+		}
+		last[p.StreamID] = len(chunks)
+		chunks = append(chunks, testChunk{p.StreamID, p.PTS, append([]byte(nil), p.Data...)})
+	}
+}
+
+// TestMuxerRoundTrip muxes the streams of the test file again and checks that
+// the new file holds the same data at the same time stamps.
+func TestMuxerRoundTrip(t *testing.T) {
+	data := readTestFile(t, testFile)
+	chunks := demuxChunks(t, data)
+
+	var buf bytes.Buffer
+	const packSize = 512
+	m, err := NewMuxer(&buf, MuxerOptions{Video: true, AudioStreams: 2, MuxRate: 100000, PackSize: packSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The muxer delays its time stamps by muxerDelay, which is where the test
+	// file starts as well.
+	for _, c := range chunks {
+		if c.id == 0xE0 {
+			err = m.WriteVideo(c.data, c.pts-muxerDelay)
+		} else {
+			err = m.WriteAudio(int(c.id-0xC0), c.data, c.pts-muxerDelay)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	muxed := buf.Bytes()
+
+	// The chunks of each stream come back in the same order, at the same time.
+	got := demuxChunks(t, muxed)
+	for _, id := range []byte{0xE0, 0xC0, 0xC1} {
+		var want, have []testChunk
+		for _, c := range chunks {
+			if c.id == id {
+				want = append(want, c)
+			}
+		}
+		for _, c := range got {
+			if c.id == id {
+				have = append(have, c)
+			}
+		}
+		if len(have) != len(want) {
+			t.Errorf("stream 0x%02X has %d chunks, want %d", id, len(have), len(want))
+			continue
+		}
+		for i := range want {
+			if have[i].pts != want[i].pts || !bytes.Equal(have[i].data, want[i].data) {
+				t.Errorf("chunk %d of stream 0x%02X differs", i, id)
+			}
+		}
+	}
+
+	// Every pack holds a single packet and fits the pack size.
+	packs := bytes.Split(muxed, []byte{0x00, 0x00, 0x01, 0xBA})
+	for i, pack := range packs[1:] {
+		if len(pack)+4 > packSize {
+			t.Errorf("pack %d is %d bytes, more than %d", i, len(pack)+4, packSize)
+		}
+	}
+
+	want, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plm, err := NewPlayerFromBytes(muxed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := plm.Info()
+	if !bytes.Equal(info.StreamIDs, []byte{0xE0, 0xC0, 0xC1}) || info.MuxRate != 100000 {
+		t.Errorf("stream IDs are % X with a mux rate of %d", info.StreamIDs, info.MuxRate)
+	}
+	if info.StartTime != want.Info().StartTime || plm.Duration() != want.Duration() {
+		t.Errorf("start time and duration are %v and %v, want %v and %v",
+			info.StartTime, plm.Duration(), want.Info().StartTime, want.Duration())
+	}
+	frames, wantFrames := decodeFrames(t, plm), decodeFrames(t, want)
+	if len(frames) != len(wantFrames) {
+		t.Fatalf("decoded %d frames, want %d", len(frames), len(wantFrames))
+	}
+	for i := range frames {
+		if frames[i] != wantFrames[i] {
+			t.Errorf("frame %d differs", i)
+		}
+	}
+	if err := plm.LastError(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMuxerErrors(t *testing.T) {
+	for _, opts := range []MuxerOptions{
+		{},
+		{Video: true, AudioStreams: 5},
+		{AudioStreams: -1},
+		{Video: true, MuxRate: 10},
+		{Video: true, PackSize: 100},
+		{Video: true, PackSize: 1 << 16},
+	} {
+		if _, err := NewMuxer(io.Discard, opts); err != ErrInvalidOptions {
+			t.Errorf("%+v: returned %v, want ErrInvalidOptions", opts, err)
+		}
+	}
+
+	m, err := NewMuxer(io.Discard, MuxerOptions{AudioStreams: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteVideo([]byte{0}, 0); err != ErrNoVideo {
+		t.Errorf("writing video returned %v, want ErrNoVideo", err)
+	}
+	if err := m.WriteAudio(1, []byte{0}, 0); err != ErrAudioStream {
+		t.Errorf("writing to audio stream 1 returned %v, want ErrAudioStream", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteAudio(0, []byte{0}, 0); err != ErrMuxerClosed {
+		t.Errorf("writing after closing returned %v, want ErrMuxerClosed", err)
+	}
+
+	m, err = NewMuxer(errWriter{}, MuxerOptions{AudioStreams: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteAudio(0, []byte{0}, 0); err != errWrite {
+		t.Errorf("writing returned %v, want the error of the writer", err)
+	}
+	if err := m.WriteAudio(0, []byte{0}, 0); err != errWrite {
+		t.Errorf("writing after an error returned %v, want the same error", err)
+	}
+}
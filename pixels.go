@@ -0,0 +1,119 @@
+package mpg
+
+// PixelFormat is the layout of pixels written by "ReadPixels".
+type PixelFormat int
+
+const (
+	// PixelRGB is packed 24-bit RGB.
+	PixelRGB PixelFormat = iota
+	// PixelBGR is packed 24-bit BGR.
+	PixelBGR
+	// PixelRGBA is 32-bit RGBA, the layout of "image.RGBA.Pix".
+	PixelRGBA
+	// PixelBGRA is 32-bit BGRA.
+	PixelBGRA
+	// PixelARGB is 32-bit ARGB.
+	PixelARGB
+	// PixelABGR is 32-bit ABGR.
+	PixelABGR
+)
+
+// pixelLayouts holds the byte offset of the red, green, blue and alpha
+// channels of each pixel format. Alpha is -1 if the format has none.
+var pixelLayouts = [...][4]int{
+	PixelRGB:  {0, 1, 2, -1},
+	PixelBGR:  {2, 1, 0, -1},
+	PixelRGBA: {0, 1, 2, 3},
+	PixelBGRA: {2, 1, 0, 3},
+	PixelARGB: {1, 2, 3, 0},
+	PixelABGR: {3, 2, 1, 0},
+}
+
+// BytesPerPixel is how many bytes each pixel takes up in this format.
+func (format PixelFormat) BytesPerPixel() int {
+	if format == PixelRGB || format == PixelBGR {
+		return 3
+	}
+	return 4
+}
+
+// ReadPixels converts the frame to "format" and writes it to "dst", where each
+// row starts "stride" bytes after the previous one. Bytes between the end of a
+// row and the start of the next are left unchanged. Alpha is always set to
+// 0xFF.
+//
+// ReadPixels returns "ErrPixelFormat" if "format" is unknown, and
+// "ErrBufferSize" if "stride" is shorter than a row or "dst" is too small to
+// hold the frame.
+func (f *Frame) ReadPixels(dst []byte, format PixelFormat, stride int) error {
+	if format < PixelRGB || format > PixelABGR {
+		return ErrPixelFormat
+	}
+	bpp := format.BytesPerPixel()
+	if f.Width == 0 || f.Height == 0 {
+		return nil
+	}
+	if stride < f.Width*bpp || len(dst) < (f.Height-1)*stride+f.Width*bpp {
+		return ErrBufferSize
+	}
+	ptr, s := bytesToUintPtr(dst), int64(stride)
+	switch format {
+	case PixelRGB:
+		plm_frame_to_rgb(&f.plm, ptr, s)
+	case PixelBGR:
+		plm_frame_to_bgr(&f.plm, ptr, s)
+	case PixelRGBA:
+		plm_frame_to_rgba(&f.plm, ptr, s)
+	case PixelBGRA:
+		plm_frame_to_bgra(&f.plm, ptr, s)
+	case PixelARGB:
+		plm_frame_to_argb(&f.plm, ptr, s)
+	case PixelABGR:
+		plm_frame_to_abgr(&f.plm, ptr, s)
+	}
+	f.convertOddEdges(dst, format, stride)
+	layout := pixelLayouts[format]
+	if a := layout[3]; a >= 0 {
+		for y := 0; y < f.Height; y++ {
+			row := dst[y*stride : y*stride+f.Width*bpp]
+			for i := a; i < len(row); i += bpp {
+				row[i] = 0xFF
+			}
+		}
+	}
+	return nil
+}
+
+// convertOddEdges converts an odd last column or row of the frame, which is
+// skipped by pl_mpeg as it converts 2x2 blocks of pixels.
+func (f *Frame) convertOddEdges(dst []byte, format PixelFormat, stride int) {
+	layout, bpp := pixelLayouts[format], format.BytesPerPixel()
+	if f.Width%2 == 1 {
+		for y := 0; y < f.Height; y++ {
+			f.writePixel(dst[y*stride+(f.Width-1)*bpp:], layout, f.Width-1, y)
+		}
+	}
+	if f.Height%2 == 1 {
+		for x := 0; x < f.Width; x++ {
+			f.writePixel(dst[(f.Height-1)*stride+x*bpp:], layout, x, f.Height-1)
+		}
+	}
+}
+
+// writePixel converts the pixel at "x" and "y" into "dst".
+func (f *Frame) writePixel(dst []byte, layout [4]int, x, y int) {
+	dst[layout[0]], dst[layout[1]], dst[layout[2]] = f.rgb(x, y)
+}
+
+// ReadPixels converts the current frame to "format" and writes it to "dst".
+// See "Frame.ReadPixels" for details.
+func (plm *Player) ReadPixels(dst []byte, format PixelFormat, stride int) error {
+	defer plm.lock()()
+	if plm.frame != nil {
+		if err := plm.frame.ReadPixels(dst, format, stride); err != nil {
+			return err
+		}
+	}
+	plm.setNewFrame(false)
+	return nil
+}
@@ -0,0 +1,84 @@
+package mpg
+
+import "testing"
+
+func TestReadPixels(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, "testdata/odd.mpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := plm.NextFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	width, height := f.Width, f.Height
+	if width%2 == 0 || height%2 == 0 {
+		t.Fatalf("frame is %dx%d, want an odd width and height", width, height)
+	}
+	rgba := make([]byte, width*height*4)
+	plm.ReadRGBA(rgba)
+
+	const padding, unchanged = 5, 0xAB
+	for format := PixelRGB; format <= PixelABGR; format++ {
+		bpp := format.BytesPerPixel()
+		stride := width*bpp + padding
+		dst := make([]byte, stride*height)
+		for i := range dst {
+			dst[i] = unchanged
+		}
+		if err := f.ReadPixels(dst, format, stride); err != nil {
+			t.Fatal(err)
+		}
+		layout := pixelLayouts[format]
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p, want := dst[y*stride+x*bpp:], rgba[(x+y*width)*4:]
+				if p[layout[0]] != want[0] || p[layout[1]] != want[1] || p[layout[2]] != want[2] {
+					t.Fatalf("format %d: pixel (%d,%d) is %v, ReadRGBA has %v", format, x, y, p[:bpp], want[:3])
+				}
+				if layout[3] >= 0 && p[layout[3]] != 0xFF {
+					t.Fatalf("format %d: alpha of pixel (%d,%d) is %d", format, x, y, p[layout[3]])
+				}
+			}
+			for _, b := range dst[y*stride+width*bpp : (y+1)*stride] {
+				if b != unchanged {
+					t.Fatalf("format %d: padding of row %d was written to", format, y)
+				}
+			}
+		}
+	}
+
+	// The player reads its current frame.
+	dst := make([]byte, width*height*3)
+	want := make([]byte, width*height*3)
+	if err := plm.ReadPixels(dst, PixelBGR, width*3); err != nil {
+		t.Fatal(err)
+	}
+	f.ReadPixels(want, PixelBGR, width*3)
+	if string(dst) != string(want) {
+		t.Error("ReadPixels of the player differs from ReadPixels of its frame")
+	}
+
+	for _, c := range []struct {
+		name   string
+		dst    []byte
+		format PixelFormat
+		stride int
+		want   error
+	}{
+		{"unknown pixel format", make([]byte, width*height*4), PixelABGR + 1, width * 4, ErrPixelFormat},
+		{"negative pixel format", make([]byte, width*height*4), -1, width * 4, ErrPixelFormat},
+		{"short stride", make([]byte, width*height*4), PixelRGBA, width*4 - 1, ErrBufferSize},
+		{"short buffer", make([]byte, width*height*4-1), PixelRGBA, width * 4, ErrBufferSize},
+		{"short padded buffer", make([]byte, (width*3+1)*height-2), PixelRGB, width*3 + 1, ErrBufferSize},
+		// The last row does not need padding.
+		{"unpadded last row", make([]byte, (width*3+1)*height-1), PixelRGB, width*3 + 1, nil},
+	} {
+		if err := f.ReadPixels(c.dst, c.format, c.stride); err != c.want {
+			t.Errorf("%s: Frame.ReadPixels returned %v, want %v", c.name, err, c.want)
+		}
+		if err := plm.ReadPixels(c.dst, c.format, c.stride); err != c.want {
+			t.Errorf("%s: Player.ReadPixels returned %v, want %v", c.name, err, c.want)
+		}
+	}
+}
@@ -33,6 +33,17 @@ const plm_audio_samples_per_frame = 1152
 const plm_buffer_default_size = 0x20000
 const _true = 1
 const _false = 0
+const plm_error_none = 0
+const plm_error_demux_packet_header = 1
+const plm_error_video_sequence_header = 2
+const plm_error_video_picture_type = 3
+const plm_error_video_motion_code = 4
+const plm_error_video_macroblock_address = 5
+const plm_error_audio_unsupported = 6
+const plm_error_audio_header = 7
+const plm_error_audio_header_changed = 8
+const plm_error_video_unsupported = 9
+const plm_error_demux_packet_lost = 10
 
 type plm_t struct {
 	Demux                           *plm_demux_t
@@ -86,6 +97,13 @@ type plm_demux_t struct {
 	Num_video_streams        int64
 	Current_packet           plm_packet_t
 	Next_packet              plm_packet_t
+	Error                    int64
+	Error_offset             uint64
+	Mux_rate                 int64
+	Stream_ids               [64]uint8
+	Num_stream_ids           int64
+	Has_checked_ts           int64
+	Ts                       *plm_demux_ts_t
 }
 type plm_video_t struct {
 	Framerate                float64
@@ -124,6 +142,12 @@ type plm_video_t struct {
 	Non_intra_quant_matrix   [64]uint8
 	Has_reference_frame      int64
 	Assume_no_b_frames       int64
+	Error                    int64
+	Aspect_ratio             int64
+	Bitrate                  int64
+	Vbv_buffer_size          int64
+	Extra_fields             int64
+	Mpeg2                    *plm_video_mpeg2_t
 }
 type plm_audio_t struct {
 	Time                     float64
@@ -133,6 +157,7 @@ type plm_audio_t struct {
 	Version                  int64
 	Layer                    int64
 	Mode                     int64
+	Mode_extension           int64
 	Bound                    int64
 	V_pos                    int64
 	Next_frame_data_size     int64
@@ -144,13 +169,19 @@ type plm_audio_t struct {
 	Scale_factor             [2][32][3]int64
 	Sample                   [2][32][3]int64
 	Samples                  plm_samples_t
+	Copyright                int64
+	Original                 int64
+	Emphasis                 int64
 	D                        [1024]float32
 	V                        [2][1024]float32
 	U                        [32]float32
+	Error                    int64
+	Layer3                   *plm_audio_layer3_t
 }
 type plm_packet_t struct {
 	Type   int64
 	Pts    float64
+	Dts    float64
 	Length uint64
 	Data   *uint8
 }
@@ -160,12 +191,14 @@ type plm_plane_t struct {
 	Data   *uint8
 }
 type plm_frame_t struct {
-	Time   float64
-	Width  uint64
-	Height uint64
-	Y      plm_plane_t
-	Cr     plm_plane_t
-	Cb     plm_plane_t
+	Time         float64
+	Width        uint64
+	Height       uint64
+	Y            plm_plane_t
+	Cr           plm_plane_t
+	Cb           plm_plane_t
+	Picture_type int64
+	Extra_fields int64
 }
 type plm_video_decode_callback func(self *plm_t, frame *plm_frame_t, user unsafe.Pointer)
 type plm_samples_t struct {
@@ -177,11 +210,14 @@ type plm_audio_decode_callback func(self *plm_t, samples *plm_samples_t, user un
 type plm_buffer_load_callback func(self *plm_buffer_t, user unsafe.Pointer)
 
 var plm_demux_packet_private int64 = 189
+var plm_demux_packet_padding int64 = 190
+var plm_demux_packet_private_2 int64 = 191
 var plm_demux_packet_audio_1 int64 = 192
 var plm_demux_packet_audio_2 int64 = 193
 var plm_demux_packet_audio_3 int64 = 194
-var plm_demux_packet_audio_4 int64 = 194
+var plm_demux_packet_audio_4 int64 = 195
 var plm_demux_packet_video_1 int64 = 224
+var plm_demux_packet_video_16 int64 = 239
 
 func plm_create_with_filename(filename *byte) *plm_t {
 	var buffer *plm_buffer_t = plm_buffer_create_with_filename(filename)
@@ -340,6 +376,9 @@ func plm_get_time(self *plm_t) float64 {
 	return self.Time
 }
 func plm_get_duration(self *plm_t) float64 {
+	if plm_demux_get_num_video_streams(self.Demux) == 0 {
+		return plm_demux_get_duration(self.Demux, plm_demux_packet_audio_1+self.Audio_stream_index)
+	}
 	return plm_demux_get_duration(self.Demux, plm_demux_packet_video_1)
 }
 func plm_rewind(self *plm_t) {
@@ -506,11 +545,17 @@ func plm_seek_frame(self *plm_t, time float64, seek_exact int64) *plm_frame_t {
 	var previous_audio_packet_type int64 = self.Audio_packet_type
 	self.Audio_packet_type = 0
 	plm_video_rewind(self.Video_decoder)
-	plm_video_set_time(self.Video_decoder, packet.Pts-start_time)
+	// Round the time of the packet to whole frames, like the times of decoded
+	// frames, so the error of subtracting time stamps does not place the
+	// intra frame just before "time".
+	var framerate float64 = self.Video_decoder.Framerate
+	plm_video_set_time(self.Video_decoder, math.Round((packet.Pts-start_time)*framerate)/framerate)
 	plm_buffer_write(self.Video_buffer, packet.Data, packet.Length)
 	var frame *plm_frame_t = plm_video_decode(self.Video_decoder)
 	if seek_exact != 0 {
-		for frame != nil && frame.Time < time {
+		// Allow for a 90kHz clock tick of rounding error between frame times
+		// and "time".
+		for frame != nil && frame.Time < time-1.0/90000 {
 			frame = plm_video_decode(self.Video_decoder)
 		}
 	}
@@ -526,11 +571,15 @@ func plm_seek(self *plm_t, time float64, seek_exact int64) int64 {
 	if frame == nil {
 		return _false
 	}
+	plm_seek_resume(self, frame)
+	return _true
+}
+func plm_seek_resume(self *plm_t, frame *plm_frame_t) {
 	if self.Video_decode_callback != nil {
 		self.Video_decode_callback(self, frame, self.Video_decode_callback_user_data)
 	}
 	if self.Audio_packet_type == 0 {
-		return _true
+		return
 	}
 	var start_time float64 = plm_demux_get_start_time(self.Demux, self.Video_packet_type)
 	plm_audio_rewind(self.Audio_decoder)
@@ -548,7 +597,6 @@ func plm_seek(self *plm_t, time float64, seek_exact int64) int64 {
 			break
 		}
 	}
-	return _true
 }
 
 type plm_buffer_mode int64
@@ -726,11 +774,15 @@ func plm_buffer_has(self *plm_buffer_t, count uint64) int64 {
 	if ((self.Length << 3) - self.Bit_index) >= count {
 		return _true
 	}
-	if self.Load_callback != nil {
+	for self.Load_callback != nil && self.Has_ended == 0 {
+		var previous_available uint64 = (self.Length << 3) - self.Bit_index
 		self.Load_callback(self, self.Load_callback_user_data)
 		if ((self.Length << 3) - self.Bit_index) >= count {
 			return _true
 		}
+		if ((self.Length<<3)-self.Bit_index) <= previous_available || self.Length == self.Capacity {
+			break
+		}
 	}
 	if self.Total_size != 0 && self.Length == self.Total_size {
 		self.Has_ended = _true
@@ -858,22 +910,36 @@ func plm_demux_has_headers(self *plm_demux_t) int64 {
 	if self.Has_headers != 0 {
 		return _true
 	}
+	if self.Has_checked_ts == 0 {
+		var is_ts int64 = plm_demux_ts_detect(self)
+		if is_ts == -1 {
+			return _false
+		}
+		self.Has_checked_ts = _true
+	}
+	if self.Ts != nil {
+		return plm_demux_ts_has_headers(self)
+	}
 	if self.Has_pack_header == 0 {
 		if self.Start_code != plm_start_pack && plm_buffer_find_start_code(self.Buffer, plm_start_pack) == -1 {
 			return _false
 		}
 		self.Start_code = plm_start_pack
-		if plm_buffer_has(self.Buffer, 64) == 0 {
+		if plm_buffer_has(self.Buffer, 80) == 0 {
 			return _false
 		}
 		self.Start_code = -1
-		if plm_buffer_read(self.Buffer, 4) != 2 {
+		var marker int64 = plm_buffer_read(self.Buffer, 2)
+		if marker == 1 {
+			plm_demux_decode_mpeg2_pack_header(self)
+		} else if marker != 0 || plm_buffer_read(self.Buffer, 2) != 2 {
 			return _false
+		} else {
+			self.System_clock_ref = plm_demux_decode_time(self)
+			plm_buffer_skip(self.Buffer, 1)
+			self.Mux_rate = plm_buffer_read(self.Buffer, 22)
+			plm_buffer_skip(self.Buffer, 1)
 		}
-		self.System_clock_ref = plm_demux_decode_time(self)
-		plm_buffer_skip(self.Buffer, 1)
-		plm_buffer_skip(self.Buffer, 22)
-		plm_buffer_skip(self.Buffer, 1)
 		self.Has_pack_header = _true
 	}
 	if self.Has_system_header == 0 {
@@ -884,12 +950,26 @@ func plm_demux_has_headers(self *plm_demux_t) int64 {
 		if plm_buffer_has(self.Buffer, 56) == 0 {
 			return _false
 		}
+		var header_length int64 = int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), self.Buffer.Bit_index>>3)))<<8 | int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), (self.Buffer.Bit_index>>3)+1)))
+		if plm_buffer_has(self.Buffer, uint64(header_length+2)<<3) == 0 && plm_buffer_has_ended(self.Buffer) == 0 {
+			return _false
+		}
 		self.Start_code = -1
 		plm_buffer_skip(self.Buffer, 16)
 		plm_buffer_skip(self.Buffer, 24)
 		self.Num_audio_streams = plm_buffer_read(self.Buffer, 6)
 		plm_buffer_skip(self.Buffer, 5)
 		self.Num_video_streams = plm_buffer_read(self.Buffer, 5)
+		plm_buffer_skip(self.Buffer, 8)
+		for i := int64(6); i+3 <= header_length && self.Num_stream_ids < int64(len(self.Stream_ids)); i += 3 {
+			var stream_id int64 = plm_buffer_read(self.Buffer, 8)
+			if (stream_id & 128) == 0 {
+				break
+			}
+			self.Stream_ids[self.Num_stream_ids] = uint8(stream_id)
+			self.Num_stream_ids++
+			plm_buffer_skip(self.Buffer, 16)
+		}
 		self.Has_system_header = _true
 	}
 	self.Has_headers = _true
@@ -912,6 +992,9 @@ func plm_demux_rewind(self *plm_demux_t) {
 	self.Current_packet.Length = 0
 	self.Next_packet.Length = 0
 	self.Start_code = -1
+	if self.Ts != nil {
+		plm_demux_ts_reset(self)
+	}
 }
 func plm_demux_has_ended(self *plm_demux_t) int64 {
 	return plm_buffer_has_ended(self.Buffer)
@@ -921,6 +1004,9 @@ func plm_demux_buffer_seek(self *plm_demux_t, pos uint64) {
 	self.Current_packet.Length = 0
 	self.Next_packet.Length = 0
 	self.Start_code = -1
+	if self.Ts != nil {
+		plm_demux_ts_reset(self)
+	}
 }
 func plm_demux_get_start_time(self *plm_demux_t, type_ int64) float64 {
 	if self.Start_time != float64(-1) {
@@ -928,6 +1014,8 @@ func plm_demux_get_start_time(self *plm_demux_t, type_ int64) float64 {
 	}
 	var previous_pos int64 = int64(plm_buffer_tell(self.Buffer))
 	var previous_start_code int64 = self.Start_code
+	var previous_current_packet plm_packet_t = self.Current_packet
+	var previous_ts *plm_demux_ts_t = plm_demux_ts_copy(self.Ts)
 	plm_demux_rewind(self)
 	for {
 		{
@@ -945,6 +1033,10 @@ func plm_demux_get_start_time(self *plm_demux_t, type_ int64) float64 {
 	}
 	plm_demux_buffer_seek(self, uint64(previous_pos))
 	self.Start_code = previous_start_code
+	if previous_ts != nil {
+		self.Current_packet = previous_current_packet
+		plm_demux_ts_restore(self, previous_ts)
+	}
 	return self.Start_time
 }
 func plm_demux_get_duration(self *plm_demux_t, type_ int64) float64 {
@@ -954,6 +1046,8 @@ func plm_demux_get_duration(self *plm_demux_t, type_ int64) float64 {
 	}
 	var previous_pos uint64 = plm_buffer_tell(self.Buffer)
 	var previous_start_code int64 = self.Start_code
+	var previous_current_packet plm_packet_t = self.Current_packet
+	var previous_ts *plm_demux_ts_t = plm_demux_ts_copy(self.Ts)
 	var start_range int64 = 64 * 1024
 	var max_range int64 = 4096 * 1024
 	for range_ := int64(start_range); range_ <= max_range; range_ *= 2 {
@@ -981,6 +1075,10 @@ func plm_demux_get_duration(self *plm_demux_t, type_ int64) float64 {
 	}
 	plm_demux_buffer_seek(self, previous_pos)
 	self.Start_code = previous_start_code
+	if previous_ts != nil {
+		self.Current_packet = previous_current_packet
+		plm_demux_ts_restore(self, previous_ts)
+	}
 	self.Last_file_size = file_size
 	return self.Duration
 }
@@ -1015,11 +1113,19 @@ func plm_demux_seek(self *plm_demux_t, seek_time float64, type_ int64, force_int
 			seek_pos = file_size - 256
 		}
 		plm_demux_buffer_seek(self, uint64(seek_pos))
-		for plm_buffer_find_start_code(self.Buffer, type_) != -1 {
+		for self.Ts != nil || plm_buffer_find_start_code(self.Buffer, type_) != -1 {
 			var (
-				packet_start int64         = int64(plm_buffer_tell(self.Buffer))
-				packet       *plm_packet_t = plm_demux_decode_packet(self, type_)
+				packet_start int64 = int64(plm_buffer_tell(self.Buffer))
+				packet       *plm_packet_t
 			)
+			if self.Ts != nil {
+				if packet = plm_demux_ts_decode(self, type_); packet == nil {
+					break
+				}
+				packet_start = int64(self.Ts.Packet_start)
+			} else {
+				packet = plm_demux_decode_packet(self, type_)
+			}
 			if packet == nil || packet.Pts == float64(-1) {
 				continue
 			}
@@ -1048,6 +1154,9 @@ func plm_demux_seek(self *plm_demux_t, seek_time float64, type_ int64, force_int
 		}
 		if last_valid_packet_start != -1 {
 			plm_demux_buffer_seek(self, uint64(last_valid_packet_start))
+			if self.Ts != nil {
+				return plm_demux_ts_decode(self, type_)
+			}
 			return plm_demux_decode_packet(self, type_)
 		} else if found_packet_in_range != 0 {
 			scan_span *= 2
@@ -1059,10 +1168,26 @@ func plm_demux_seek(self *plm_demux_t, seek_time float64, type_ int64, force_int
 	}
 	return nil
 }
+func plm_demux_seek_to(self *plm_demux_t, pos uint64, type_ int64) *plm_packet_t {
+	if plm_demux_has_headers(self) == 0 {
+		return nil
+	}
+	plm_demux_buffer_seek(self, pos)
+	if self.Ts != nil {
+		return plm_demux_ts_decode(self, type_)
+	}
+	if plm_buffer_find_start_code(self.Buffer, type_) == -1 {
+		return nil
+	}
+	return plm_demux_decode_packet(self, type_)
+}
 func plm_demux_decode(self *plm_demux_t) *plm_packet_t {
 	if plm_demux_has_headers(self) == 0 {
 		return nil
 	}
+	if self.Ts != nil {
+		return plm_demux_ts_decode(self, 0)
+	}
 	if self.Current_packet.Length != 0 {
 		var bits_till_next_packet uint64 = self.Current_packet.Length << 3
 		if plm_buffer_has(self.Buffer, bits_till_next_packet) == 0 {
@@ -1079,7 +1204,7 @@ func plm_demux_decode(self *plm_demux_t) *plm_packet_t {
 	}
 	for {
 		self.Start_code = plm_buffer_next_start_code(self.Buffer)
-		if self.Start_code == plm_demux_packet_video_1 || self.Start_code == plm_demux_packet_private || self.Start_code >= plm_demux_packet_audio_1 && self.Start_code <= plm_demux_packet_audio_4 {
+		if self.Start_code >= plm_demux_packet_private && self.Start_code <= plm_demux_packet_video_16 {
 			return plm_demux_decode_packet(self, self.Start_code)
 		}
 		if self.Start_code == -1 {
@@ -1104,8 +1229,17 @@ func plm_demux_decode_packet(self *plm_demux_t, type_ int64) *plm_packet_t {
 	self.Start_code = -1
 	self.Next_packet.Type = type_
 	self.Next_packet.Length = uint64(plm_buffer_read(self.Buffer, 16))
+	if type_ == plm_demux_packet_padding || type_ == plm_demux_packet_private_2 {
+		self.Next_packet.Pts = float64(-1)
+		self.Next_packet.Dts = float64(-1)
+		return plm_demux_get_packet(self)
+	}
 	self.Next_packet.Length -= uint64(plm_buffer_skip_bytes(self.Buffer, math.MaxUint8))
-	if plm_buffer_read(self.Buffer, 2) == 1 {
+	var marker int64 = plm_buffer_read(self.Buffer, 2)
+	if marker == 2 {
+		return plm_demux_decode_mpeg2_packet(self)
+	}
+	if marker == 1 {
 		plm_buffer_skip(self.Buffer, 16)
 		self.Next_packet.Length -= 2
 	}
@@ -1113,18 +1247,25 @@ func plm_demux_decode_packet(self *plm_demux_t, type_ int64) *plm_packet_t {
 	if pts_dts_marker == 3 {
 		self.Next_packet.Pts = plm_demux_decode_time(self)
 		self.Last_decoded_pts = self.Next_packet.Pts
-		plm_buffer_skip(self.Buffer, 40)
+		plm_buffer_skip(self.Buffer, 4)
+		self.Next_packet.Dts = plm_demux_decode_time(self)
 		self.Next_packet.Length -= 10
 	} else if pts_dts_marker == 2 {
 		self.Next_packet.Pts = plm_demux_decode_time(self)
 		self.Last_decoded_pts = self.Next_packet.Pts
+		self.Next_packet.Dts = self.Next_packet.Pts
 		self.Next_packet.Length -= 5
 	} else if pts_dts_marker == 0 {
 		self.Next_packet.Pts = float64(-1)
+		self.Next_packet.Dts = float64(-1)
 		plm_buffer_skip(self.Buffer, 4)
 		self.Next_packet.Length -= 1
 	} else {
-		return nil
+		self.Error = plm_error_demux_packet_header
+		self.Error_offset = plm_buffer_tell(self.Buffer)
+		// Skip the corrupt packet and continue with the next one.
+		self.Next_packet.Length = 0
+		return plm_demux_decode(self)
 	}
 	return plm_demux_get_packet(self)
 }
@@ -1136,6 +1277,7 @@ func plm_demux_get_packet(self *plm_demux_t) *plm_packet_t {
 	self.Current_packet.Length = self.Next_packet.Length
 	self.Current_packet.Type = self.Next_packet.Type
 	self.Current_packet.Pts = self.Next_packet.Pts
+	self.Current_packet.Dts = self.Next_packet.Dts
 	self.Next_packet.Length = 0
 	return &self.Current_packet
 }
@@ -1159,10 +1301,10 @@ var plm_video_macroblock_type_intra [4]plm_vlc_t = [4]plm_vlc_t{{Index: 1 << 1,
 var plm_video_macroblock_type_predictive [14]plm_vlc_t = [14]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 0, Value: 10}, {Index: 2 << 1, Value: 0}, {Index: 0, Value: 2}, {Index: 3 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 26}, {Index: 0, Value: 1}, {Index: -1, Value: 0}, {Index: 0, Value: 17}}
 var plm_video_macroblock_type_b [22]plm_vlc_t = [22]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 12}, {Index: 0, Value: 14}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 4}, {Index: 0, Value: 6}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: 0, Value: 10}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 30}, {Index: 0, Value: 1}, {Index: -1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 22}, {Index: 0, Value: 26}}
 var plm_video_macroblock_type [4]*plm_vlc_t = [4]*plm_vlc_t{nil, &plm_video_macroblock_type_intra[0], &plm_video_macroblock_type_predictive[0], &plm_video_macroblock_type_b[0]}
-var plm_video_code_block_pattern [126]plm_vlc_t = [126]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 0, Value: 60}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 0, Value: 32}, {Index: 0, Value: 16}, {Index: 0, Value: 8}, {Index: 0, Value: 4}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 0, Value: 62}, {Index: 0, Value: 2}, {Index: 0, Value: 61}, {Index: 0, Value: 1}, {Index: 0, Value: 56}, {Index: 0, Value: 52}, {Index: 0, Value: 44}, {Index: 0, Value: 28}, {Index: 0, Value: 40}, {Index: 0, Value: 20}, {Index: 0, Value: 48}, {Index: 0, Value: 12}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 0, Value: 63}, {Index: 0, Value: 3}, {Index: 0, Value: 36}, {Index: 0, Value: 24}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 0, Value: 18}, {Index: 0, Value: 10}, {Index: 0, Value: 6}, {Index: 0, Value: 33}, {Index: 0, Value: 17}, {Index: 0, Value: 9}, {Index: 0, Value: 5}, {Index: -1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: 0, Value: 58}, {Index: 0, Value: 54}, {Index: 0, Value: 46}, {Index: 0, Value: 30}, {Index: 0, Value: 57}, {Index: 0, Value: 53}, {Index: 0, Value: 45}, {Index: 0, Value: 29}, {Index: 0, Value: 38}, {Index: 0, Value: 26}, {Index: 0, Value: 37}, {Index: 0, Value: 25}, {Index: 0, Value: 43}, {Index: 0, Value: 23}, {Index: 0, Value: 51}, {Index: 0, Value: 15}, {Index: 0, Value: 42}, {Index: 0, Value: 22}, {Index: 0, Value: 50}, {Index: 0, Value: 14}, {Index: 0, Value: 41}, {Index: 0, Value: 21}, {Index: 0, Value: 49}, {Index: 0, Value: 13}, {Index: 0, Value: 35}, {Index: 0, Value: 19}, {Index: 0, Value: 11}, {Index: 0, Value: 7}, {Index: 0, Value: 39}, {Index: 0, Value: 27}, {Index: 0, Value: 59}, {Index: 0, Value: 55}, {Index: 0, Value: 47}, {Index: 0, Value: 31}}
+var plm_video_code_block_pattern [128]plm_vlc_t = [128]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 0, Value: 60}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 0, Value: 32}, {Index: 0, Value: 16}, {Index: 0, Value: 8}, {Index: 0, Value: 4}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 0, Value: 62}, {Index: 0, Value: 2}, {Index: 0, Value: 61}, {Index: 0, Value: 1}, {Index: 0, Value: 56}, {Index: 0, Value: 52}, {Index: 0, Value: 44}, {Index: 0, Value: 28}, {Index: 0, Value: 40}, {Index: 0, Value: 20}, {Index: 0, Value: 48}, {Index: 0, Value: 12}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 0, Value: 63}, {Index: 0, Value: 3}, {Index: 0, Value: 36}, {Index: 0, Value: 24}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 0, Value: 18}, {Index: 0, Value: 10}, {Index: 0, Value: 6}, {Index: 0, Value: 33}, {Index: 0, Value: 17}, {Index: 0, Value: 9}, {Index: 0, Value: 5}, {Index: 63 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: 0, Value: 58}, {Index: 0, Value: 54}, {Index: 0, Value: 46}, {Index: 0, Value: 30}, {Index: 0, Value: 57}, {Index: 0, Value: 53}, {Index: 0, Value: 45}, {Index: 0, Value: 29}, {Index: 0, Value: 38}, {Index: 0, Value: 26}, {Index: 0, Value: 37}, {Index: 0, Value: 25}, {Index: 0, Value: 43}, {Index: 0, Value: 23}, {Index: 0, Value: 51}, {Index: 0, Value: 15}, {Index: 0, Value: 42}, {Index: 0, Value: 22}, {Index: 0, Value: 50}, {Index: 0, Value: 14}, {Index: 0, Value: 41}, {Index: 0, Value: 21}, {Index: 0, Value: 49}, {Index: 0, Value: 13}, {Index: 0, Value: 35}, {Index: 0, Value: 19}, {Index: 0, Value: 11}, {Index: 0, Value: 7}, {Index: 0, Value: 39}, {Index: 0, Value: 27}, {Index: 0, Value: 59}, {Index: 0, Value: 55}, {Index: 0, Value: 47}, {Index: 0, Value: 31}, {Index: -1, Value: 0}, {Index: 0, Value: 0}}
 var plm_video_motion [68]plm_vlc_t = [68]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: -1}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 2}, {Index: 0, Value: -2}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 0, Value: 3}, {Index: 0, Value: -3}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: -1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 0, Value: 4}, {Index: 0, Value: -4}, {Index: -1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 0, Value: -7}, {Index: 0, Value: 6}, {Index: 0, Value: -6}, {Index: 0, Value: 5}, {Index: 0, Value: -5}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 0, Value: 10}, {Index: 0, Value: -10}, {Index: 0, Value: 9}, {Index: 0, Value: -9}, {Index: 0, Value: 8}, {Index: 0, Value: -8}, {Index: 0, Value: 16}, {Index: 0, Value: -16}, {Index: 0, Value: 15}, {Index: 0, Value: -15}, {Index: 0, Value: 14}, {Index: 0, Value: -14}, {Index: 0, Value: 13}, {Index: 0, Value: -13}, {Index: 0, Value: 12}, {Index: 0, Value: -12}, {Index: 0, Value: 11}, {Index: 0, Value: -11}}
-var plm_video_dct_size_luminance [18]plm_vlc_t = [18]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 2}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {}, {Index: 0, Value: 3}, {Index: 0, Value: 4}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 5}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 6}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: -1, Value: 0}}
-var plm_video_dct_size_chrominance [18]plm_vlc_t = [18]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {}, {Index: 0, Value: 1}, {Index: 0, Value: 2}, {Index: 3 << 1, Value: 0}, {Index: 0, Value: 3}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 4}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 5}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 6}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: -1, Value: 0}}
+var plm_video_dct_size_luminance [22]plm_vlc_t = [22]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 2}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {}, {Index: 0, Value: 3}, {Index: 0, Value: 4}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 5}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 6}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: 9 << 1, Value: 0}, {Index: 0, Value: 9}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 10}, {Index: 0, Value: 11}}
+var plm_video_dct_size_chrominance [22]plm_vlc_t = [22]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {}, {Index: 0, Value: 1}, {Index: 0, Value: 2}, {Index: 3 << 1, Value: 0}, {Index: 0, Value: 3}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 4}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 5}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 6}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: 9 << 1, Value: 0}, {Index: 0, Value: 9}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 10}, {Index: 0, Value: 11}}
 var plm_video_dct_size [3]*plm_vlc_t = [3]*plm_vlc_t{&plm_video_dct_size_luminance[0], &plm_video_dct_size_chrominance[0], &plm_video_dct_size_chrominance[0]}
 var plm_video_dct_coeff [224]plm_vlc_uint_t = [224]plm_vlc_uint_t{{Index: 1 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 257}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 2}, {Index: 0, Value: 513}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 0, Value: 3}, {Index: 0, Value: 1025}, {Index: 0, Value: 769}, {Index: 16 << 1, Value: 0}, {Index: 0, Value: math.MaxUint16}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 0, Value: 1793}, {Index: 0, Value: 1537}, {Index: 0, Value: 258}, {Index: 0, Value: 1281}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 0, Value: 514}, {Index: 0, Value: 2305}, {Index: 0, Value: 4}, {Index: 0, Value: 2049}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 0, Value: 3329}, {Index: 0, Value: 6}, {Index: 0, Value: 3073}, {Index: 0, Value: 2817}, {Index: 0, Value: 770}, {Index: 0, Value: 259}, {Index: 0, Value: 5}, {Index: 0, Value: 2561}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 0, Value: 4097}, {Index: 0, Value: 1282}, {Index: 0, Value: 7}, {Index: 0, Value: 515}, {Index: 0, Value: 260}, {Index: 0, Value: 3841}, {Index: 0, Value: 3585}, {Index: 0, Value: 1026}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: -1, Value: 0}, {Index: 63 << 1, Value: 0}, {Index: 64 << 1, Value: 0}, {Index: 65 << 1, Value: 0}, {Index: 66 << 1, Value: 0}, {Index: 67 << 1, Value: 0}, {Index: 68 << 1, Value: 0}, {Index: 69 << 1, Value: 0}, {Index: 70 << 1, Value: 0}, {Index: 71 << 1, Value: 0}, {Index: 72 << 1, Value: 0}, {Index: 73 << 1, Value: 0}, {Index: 74 << 1, Value: 0}, {Index: 75 << 1, Value: 0}, {Index: 76 << 1, Value: 0}, {Index: 77 << 1, Value: 0}, {Index: 0, Value: 11}, {Index: 0, Value: 2050}, {Index: 0, Value: 1027}, {Index: 0, Value: 10}, {Index: 0, Value: 516}, {Index: 0, Value: 1794}, {Index: 0, Value: 5377}, {Index: 0, Value: 5121}, {Index: 0, Value: 9}, {Index: 0, Value: 4865}, {Index: 0, Value: 4609}, {Index: 0, Value: 261}, {Index: 0, Value: 771}, {Index: 0, Value: 8}, {Index: 0, Value: 1538}, {Index: 0, Value: 4353}, {Index: 78 << 1, Value: 0}, {Index: 79 << 1, Value: 0}, {Index: 80 << 1, Value: 0}, {Index: 81 << 1, Value: 0}, {Index: 82 << 1, Value: 0}, {Index: 83 << 1, Value: 0}, {Index: 84 << 1, Value: 0}, {Index: 85 << 1, Value: 0}, {Index: 86 << 1, Value: 0}, {Index: 87 << 1, Value: 0}, {Index: 88 << 1, Value: 0}, {Index: 89 << 1, Value: 0}, {Index: 90 << 1, Value: 0}, {Index: 91 << 1, Value: 0}, {Index: 0, Value: 2562}, {Index: 0, Value: 2306}, {Index: 0, Value: 1283}, {Index: 0, Value: 772}, {Index: 0, Value: 517}, {Index: 0, Value: 263}, {Index: 0, Value: 262}, {Index: 0, Value: 15}, {Index: 0, Value: 14}, {Index: 0, Value: 13}, {Index: 0, Value: 12}, {Index: 0, Value: 6657}, {Index: 0, Value: 6401}, {Index: 0, Value: 6145}, {Index: 0, Value: 5889}, {Index: 0, Value: 5633}, {Index: 92 << 1, Value: 0}, {Index: 93 << 1, Value: 0}, {Index: 94 << 1, Value: 0}, {Index: 95 << 1, Value: 0}, {Index: 96 << 1, Value: 0}, {Index: 97 << 1, Value: 0}, {Index: 98 << 1, Value: 0}, {Index: 99 << 1, Value: 0}, {Index: 100 << 1, Value: 0}, {Index: 101 << 1, Value: 0}, {Index: 102 << 1, Value: 0}, {Index: 103 << 1, Value: 0}, {Index: 0, Value: 31}, {Index: 0, Value: 30}, {Index: 0, Value: 29}, {Index: 0, Value: 28}, {Index: 0, Value: 27}, {Index: 0, Value: 26}, {Index: 0, Value: 25}, {Index: 0, Value: 24}, {Index: 0, Value: 23}, {Index: 0, Value: 22}, {Index: 0, Value: 21}, {Index: 0, Value: 20}, {Index: 0, Value: 19}, {Index: 0, Value: 18}, {Index: 0, Value: 17}, {Index: 0, Value: 16}, {Index: 104 << 1, Value: 0}, {Index: 105 << 1, Value: 0}, {Index: 106 << 1, Value: 0}, {Index: 107 << 1, Value: 0}, {Index: 108 << 1, Value: 0}, {Index: 109 << 1, Value: 0}, {Index: 110 << 1, Value: 0}, {Index: 111 << 1, Value: 0}, {Index: 0, Value: 40}, {Index: 0, Value: 39}, {Index: 0, Value: 38}, {Index: 0, Value: 37}, {Index: 0, Value: 36}, {Index: 0, Value: 35}, {Index: 0, Value: 34}, {Index: 0, Value: 33}, {Index: 0, Value: 32}, {Index: 0, Value: 270}, {Index: 0, Value: 269}, {Index: 0, Value: 268}, {Index: 0, Value: 267}, {Index: 0, Value: 266}, {Index: 0, Value: 265}, {Index: 0, Value: 264}, {Index: 0, Value: 274}, {Index: 0, Value: 273}, {Index: 0, Value: 272}, {Index: 0, Value: 271}, {Index: 0, Value: 1539}, {Index: 0, Value: 4098}, {Index: 0, Value: 3842}, {Index: 0, Value: 3586}, {Index: 0, Value: 3330}, {Index: 0, Value: 3074}, {Index: 0, Value: 2818}, {Index: 0, Value: 7937}, {Index: 0, Value: 7681}, {Index: 0, Value: 7425}, {Index: 0, Value: 7169}, {Index: 0, Value: 6913}}
 
@@ -1227,15 +1369,20 @@ func plm_video_get_time(self *plm_video_t) float64 {
 	return self.Time
 }
 func plm_video_set_time(self *plm_video_t, time float64) {
-	self.Frames_decoded = int64(self.Framerate * time)
+	self.Frames_decoded = int64(math.Round(self.Framerate * time))
+	self.Extra_fields = 0
 	self.Time = time
 }
 func plm_video_rewind(self *plm_video_t) {
 	plm_buffer_rewind(self.Buffer)
 	self.Time = 0
 	self.Frames_decoded = 0
+	self.Extra_fields = 0
 	self.Has_reference_frame = _false
 	self.Start_code = -1
+	if self.Mpeg2 != nil {
+		self.Mpeg2.Second_field = _false
+	}
 }
 func plm_video_has_ended(self *plm_video_t) int64 {
 	return plm_buffer_has_ended(self.Buffer)
@@ -1249,7 +1396,7 @@ func plm_video_decode(self *plm_video_t) *plm_frame_t {
 		if self.Start_code != plm_start_picture {
 			self.Start_code = plm_buffer_find_start_code(self.Buffer, plm_start_picture)
 			if self.Start_code == -1 {
-				if self.Has_reference_frame != 0 && self.Assume_no_b_frames == 0 && plm_buffer_has_ended(self.Buffer) != 0 && (self.Picture_type == plm_video_picture_type_intra || self.Picture_type == plm_video_picture_type_predictive) {
+				if self.Has_reference_frame != 0 && self.Assume_no_b_frames == 0 && plm_buffer_has_ended(self.Buffer) != 0 {
 					self.Has_reference_frame = _false
 					frame = &self.Frame_backward
 					break
@@ -1260,8 +1407,13 @@ func plm_video_decode(self *plm_video_t) *plm_frame_t {
 		if plm_buffer_has_start_code(self.Buffer, plm_start_picture) == -1 && plm_buffer_has_ended(self.Buffer) == 0 {
 			return nil
 		}
-		plm_buffer_discard_read_bytes(self.Buffer)
+		if self.Buffer.Discard_read_bytes != 0 {
+			plm_buffer_discard_read_bytes(self.Buffer)
+		}
 		plm_video_decode_picture(self)
+		if self.Mpeg2 != nil && self.Mpeg2.Second_field != 0 {
+			continue
+		}
 		if self.Assume_no_b_frames != 0 {
 			frame = &self.Frame_backward
 		} else if self.Picture_type == plm_video_picture_type_b {
@@ -1277,7 +1429,8 @@ func plm_video_decode(self *plm_video_t) *plm_frame_t {
 	}
 	frame.Time = self.Time
 	self.Frames_decoded++
-	self.Time = float64(self.Frames_decoded) / self.Framerate
+	self.Extra_fields += frame.Extra_fields
+	self.Time = (float64(self.Frames_decoded) + float64(self.Extra_fields)/2) / self.Framerate
 	return frame
 }
 func plm_video_has_header(self *plm_video_t) int64 {
@@ -1296,18 +1449,22 @@ func plm_video_has_header(self *plm_video_t) int64 {
 	return _true
 }
 func plm_video_decode_sequence_header(self *plm_video_t) int64 {
-	var max_header_size int64 = 64 + 2*64*8
+	var max_header_size int64 = 64 + 2*64*8 + 112
 	if plm_buffer_has(self.Buffer, uint64(max_header_size)) == 0 {
 		return _false
 	}
 	self.Width = plm_buffer_read(self.Buffer, 12)
 	self.Height = plm_buffer_read(self.Buffer, 12)
 	if self.Width <= 0 || self.Height <= 0 {
+		self.Error = plm_error_video_sequence_header
 		return _false
 	}
-	plm_buffer_skip(self.Buffer, 4)
+	self.Aspect_ratio = plm_buffer_read(self.Buffer, 4)
 	self.Framerate = plm_video_picture_rate[plm_buffer_read(self.Buffer, 4)]
-	plm_buffer_skip(self.Buffer, 18+1+10+1)
+	self.Bitrate = plm_buffer_read(self.Buffer, 18)
+	plm_buffer_skip(self.Buffer, 1)
+	self.Vbv_buffer_size = plm_buffer_read(self.Buffer, 10)
+	plm_buffer_skip(self.Buffer, 1)
 	if plm_buffer_read(self.Buffer, 1) != 0 {
 		for i := int64(0); i < 64; i++ {
 			var idx int64 = int64(plm_video_zig_zag[i])
@@ -1324,8 +1481,14 @@ func plm_video_decode_sequence_header(self *plm_video_t) int64 {
 	} else {
 		libc.MemCpy(unsafe.Pointer(&self.Non_intra_quant_matrix[0]), unsafe.Pointer(&plm_video_non_intra_quant_matrix[0]), 64)
 	}
+	if plm_video_decode_sequence_extension(self) == 0 {
+		return _false
+	}
 	self.Mb_width = (self.Width + 15) >> 4
 	self.Mb_height = (self.Height + 15) >> 4
+	if self.Mpeg2 != nil && self.Mpeg2.Progressive_sequence == 0 {
+		self.Mb_height = ((self.Height + 31) >> 5) << 1
+	}
 	self.Mb_size = self.Mb_width * self.Mb_height
 	self.Luma_width = self.Mb_width << 4
 	self.Luma_height = self.Mb_height << 4
@@ -1363,34 +1526,44 @@ func plm_video_decode_picture(self *plm_video_t) {
 	self.Picture_type = plm_buffer_read(self.Buffer, 3)
 	plm_buffer_skip(self.Buffer, 16)
 	if self.Picture_type <= 0 || self.Picture_type > plm_video_picture_type_b {
+		self.Error = plm_error_video_picture_type
 		return
 	}
-	if self.Picture_type == plm_video_picture_type_predictive || self.Picture_type == plm_video_picture_type_b {
+	if self.Mpeg2 == nil && (self.Picture_type == plm_video_picture_type_predictive || self.Picture_type == plm_video_picture_type_b) {
 		self.Motion_forward.Full_px = plm_buffer_read(self.Buffer, 1)
 		var f_code int64 = plm_buffer_read(self.Buffer, 3)
 		if f_code == 0 {
+			self.Error = plm_error_video_motion_code
 			return
 		}
 		self.Motion_forward.R_size = f_code - 1
 	}
-	if self.Picture_type == plm_video_picture_type_b {
+	if self.Mpeg2 == nil && self.Picture_type == plm_video_picture_type_b {
 		self.Motion_backward.Full_px = plm_buffer_read(self.Buffer, 1)
 		var f_code int64 = plm_buffer_read(self.Buffer, 3)
 		if f_code == 0 {
+			self.Error = plm_error_video_motion_code
 			return
 		}
 		self.Motion_backward.R_size = f_code - 1
 	}
-	var frame_temp plm_frame_t = self.Frame_forward
-	if self.Picture_type == plm_video_picture_type_intra || self.Picture_type == plm_video_picture_type_predictive {
-		self.Frame_forward = self.Frame_backward
-	}
 	for {
 		self.Start_code = plm_buffer_next_start_code(self.Buffer)
-		if !(self.Start_code == plm_start_extension || self.Start_code == plm_start_user_data) {
+		if self.Start_code == plm_start_extension && self.Mpeg2 != nil {
+			plm_video_decode_extension(self)
+		} else if self.Start_code != plm_start_extension && self.Start_code != plm_start_user_data {
 			break
 		}
 	}
+	if self.Mpeg2 != nil {
+		plm_video_mpeg2_decode_picture(self)
+		return
+	}
+	self.Frame_current.Picture_type = self.Picture_type
+	var frame_temp plm_frame_t = self.Frame_forward
+	if self.Picture_type == plm_video_picture_type_intra || self.Picture_type == plm_video_picture_type_predictive {
+		self.Frame_forward = self.Frame_backward
+	}
 	for self.Start_code >= plm_start_slice_first && self.Start_code <= plm_start_slice_last {
 		plm_video_decode_slice(self, self.Start_code&math.MaxUint8)
 		if self.Macroblock_address >= self.Mb_size-2 {
@@ -1448,6 +1621,7 @@ func plm_video_decode_macroblock(self *plm_video_t) {
 		self.Macroblock_address += increment
 	} else {
 		if self.Macroblock_address+increment >= self.Mb_size {
+			self.Error = plm_error_video_macroblock_address
 			return
 		}
 		if increment > 1 {
@@ -1471,6 +1645,7 @@ func plm_video_decode_macroblock(self *plm_video_t) {
 	self.Mb_row = self.Macroblock_address / self.Mb_width
 	self.Mb_col = self.Macroblock_address % self.Mb_width
 	if self.Mb_col >= self.Mb_width || self.Mb_row >= self.Mb_height {
+		self.Error = plm_error_video_macroblock_address
 		return
 	}
 	var table *plm_vlc_t = plm_video_macroblock_type[self.Picture_type]
@@ -2410,6 +2585,9 @@ func plm_audio_rewind(self *plm_audio_t) {
 	self.Time = 0
 	self.Samples_decoded = 0
 	self.Next_frame_data_size = 0
+	if self.Layer3 != nil {
+		self.Layer3.Reservoir_size = 0
+	}
 }
 func plm_audio_has_ended(self *plm_audio_t) int64 {
 	return plm_buffer_has_ended(self.Buffer)
@@ -2424,22 +2602,30 @@ func plm_audio_decode(self *plm_audio_t) *plm_samples_t {
 	if self.Next_frame_data_size == 0 || plm_buffer_has(self.Buffer, uint64(self.Next_frame_data_size<<3)) == 0 {
 		return nil
 	}
-	plm_audio_decode_frame(self)
+	switch self.Layer {
+	case plm_audio_layer_i:
+		plm_audio_decode_frame_layer_i(self)
+	case plm_audio_layer_iii:
+		plm_audio_decode_frame_layer_iii(self)
+	default:
+		plm_audio_decode_frame(self)
+	}
 	self.Next_frame_data_size = 0
+	self.Samples.Count = uint64(plm_audio_get_samples_per_frame(self))
 	self.Samples.Time = self.Time
-	self.Samples_decoded += plm_audio_samples_per_frame
+	self.Samples_decoded += int64(self.Samples.Count)
 	self.Time = float64(self.Samples_decoded) / float64(plm_audio_sample_rate[self.Samplerate_index])
 	return &self.Samples
 }
 func plm_audio_find_frame_sync(self *plm_audio_t) int64 {
 	var i uint64
 	for i = self.Buffer.Bit_index >> 3; i < self.Buffer.Length-1; i++ {
-		if int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), i))) == math.MaxUint8 && (int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), i+1)))&254) == 252 {
+		if int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), i))) == math.MaxUint8 && (int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), i+1)))&240) == 240 && (int64(*(*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), i+1)))&6) != 0 {
 			self.Buffer.Bit_index = ((i + 1) << 3) + 3
 			return _true
 		}
 	}
-	self.Buffer.Bit_index = (i + 1) << 3
+	self.Buffer.Bit_index = i << 3
 	return _false
 }
 func plm_audio_decode_header(self *plm_audio_t) int64 {
@@ -2451,47 +2637,68 @@ func plm_audio_decode_header(self *plm_audio_t) int64 {
 	if sync != plm_audio_frame_sync && plm_audio_find_frame_sync(self) == 0 {
 		return 0
 	}
+	if ((self.Buffer.Length << 3) - self.Buffer.Bit_index) < 21 {
+		self.Buffer.Bit_index -= 11
+		return 0
+	}
 	self.Version = plm_buffer_read(self.Buffer, 2)
-	self.Layer = plm_buffer_read(self.Buffer, 2)
+	var layer int64 = plm_buffer_read(self.Buffer, 2)
 	var hasCRC int64 = int64(libc.BoolToInt(plm_buffer_read(self.Buffer, 1) == 0))
-	if self.Version != plm_audio_mpeg_1 || self.Layer != plm_audio_layer_ii {
+	if self.Version != plm_audio_mpeg_1 && self.Version != plm_audio_mpeg_2 || layer == 0 || self.Version == plm_audio_mpeg_2 && layer == plm_audio_layer_iii {
+		self.Error = plm_error_audio_unsupported
 		return 0
 	}
 	var bitrate_index int64 = plm_buffer_read(self.Buffer, 4) - 1
-	if bitrate_index > 13 {
+	if bitrate_index < 0 || bitrate_index > 13 {
+		self.Error = plm_error_audio_header
 		return 0
 	}
 	var samplerate_index int64 = plm_buffer_read(self.Buffer, 2)
 	if samplerate_index == 3 {
+		self.Error = plm_error_audio_header
 		return 0
 	}
+	// The low sampling frequencies of MPEG2 use the second half of the
+	// tables.
+	if self.Version == plm_audio_mpeg_2 {
+		bitrate_index += 14
+		samplerate_index += 4
+	}
 	var padding int64 = plm_buffer_read(self.Buffer, 1)
 	plm_buffer_skip(self.Buffer, 1)
 	var mode int64 = plm_buffer_read(self.Buffer, 2)
-	if self.Has_header != 0 && (self.Bitrate_index != bitrate_index || self.Samplerate_index != samplerate_index || self.Mode != mode) {
+	// MP3 streams often switch bitrates from frame to frame.
+	if self.Has_header != 0 && (self.Layer != layer || layer != plm_audio_layer_iii && self.Bitrate_index != bitrate_index || self.Samplerate_index != samplerate_index || self.Mode != mode) {
+		self.Error = plm_error_audio_header_changed
 		return 0
 	}
+	self.Layer = layer
 	self.Bitrate_index = bitrate_index
 	self.Samplerate_index = samplerate_index
 	self.Mode = mode
 	self.Has_header = _true
+	self.Mode_extension = plm_buffer_read(self.Buffer, 2)
 	if mode == plm_audio_mode_joint_stereo {
-		self.Bound = (plm_buffer_read(self.Buffer, 2) + 1) << 2
+		self.Bound = (self.Mode_extension + 1) << 2
 	} else {
-		plm_buffer_skip(self.Buffer, 2)
 		if mode == plm_audio_mode_mono {
 			self.Bound = 0
 		} else {
 			self.Bound = 32
 		}
 	}
-	plm_buffer_skip(self.Buffer, 4)
+	self.Copyright = plm_buffer_read(self.Buffer, 1)
+	self.Original = plm_buffer_read(self.Buffer, 1)
+	self.Emphasis = plm_buffer_read(self.Buffer, 2)
 	if hasCRC != 0 {
 		plm_buffer_skip(self.Buffer, 16)
 	}
-	var bitrate int64 = int64(plm_audio_bit_rate[self.Bitrate_index])
+	var bitrate int64 = plm_audio_get_bitrate(self)
 	var samplerate int64 = int64(plm_audio_sample_rate[self.Samplerate_index])
 	var frame_size int64 = (bitrate * 144000 / samplerate) + padding
+	if layer == plm_audio_layer_i {
+		frame_size = ((bitrate * 12000 / samplerate) + padding) * 4
+	}
 	return frame_size - (func() int64 {
 		if hasCRC != 0 {
 			return 6
@@ -2505,15 +2712,20 @@ func plm_audio_decode_frame(self *plm_audio_t) {
 		sblimit int64 = 0
 		tab1    int64
 	)
-	if self.Mode == plm_audio_mode_mono {
-		tab1 = 0
+	if self.Version == plm_audio_mpeg_2 {
+		tab3 = 2
+		sblimit = 30
 	} else {
-		tab1 = 1
+		if self.Mode == plm_audio_mode_mono {
+			tab1 = 0
+		} else {
+			tab1 = 1
+		}
+		var tab2 int64 = int64(plm_audio_quant_lut_step_1[tab1][self.Bitrate_index])
+		tab3 = int64(quant_lut_step_2[tab2][self.Samplerate_index])
+		sblimit = tab3 & 63
+		tab3 >>= 6
 	}
-	var tab2 int64 = int64(plm_audio_quant_lut_step_1[tab1][self.Bitrate_index])
-	tab3 = int64(quant_lut_step_2[tab2][self.Samplerate_index])
-	sblimit = tab3 & 63
-	tab3 >>= 6
 	if self.Bound > sblimit {
 		self.Bound = sblimit
 	}
@@ -2607,58 +2819,66 @@ func plm_audio_decode_frame(self *plm_audio_t) {
 				self.Sample[1][sb][1] = 0
 				self.Sample[1][sb][2] = 0
 			}
-			for p := int64(0); p < 3; p++ {
-				self.V_pos = (self.V_pos - 64) & 1023
-				for ch := int64(0); ch < 2; ch++ {
-					plm_audio_idct36(self.Sample[ch], p, &self.V[ch][0], self.V_pos)
-					*(*[32]float32)(unsafe.Pointer(&self.U[0])) = [32]float32{}
-					var d_index int64 = 512 - (self.V_pos >> 1)
-					var v_index int64 = (self.V_pos % 128) >> 1
-					for v_index < 1024 {
-						for i := int64(0); i < 32; i++ {
-							self.U[i] += self.D[func() int64 {
-								p := &d_index
-								x := *p
-								*p++
-								return x
-							}()] * self.V[ch][func() int64 {
-								p := &v_index
-								x := *p
-								*p++
-								return x
-							}()]
-						}
-						v_index += 128 - 32
-						d_index += 64 - 32
-					}
-					d_index -= 512 - 32
-					v_index = (128 - 32 + 1024) - v_index
-					for v_index < 1024 {
-						for i := int64(0); i < 32; i++ {
-							self.U[i] += self.D[func() int64 {
-								p := &d_index
-								x := *p
-								*p++
-								return x
-							}()] * self.V[ch][func() int64 {
-								p := &v_index
-								x := *p
-								*p++
-								return x
-							}()]
-						}
-						v_index += 128 - 32
-						d_index += 64 - 32
-					}
-					for j := int64(0); j < 32; j++ {
-						self.Samples.Interleaved[((out_pos+j)<<1)+ch] = float32(float64(self.U[j]) / 2.147418112e+09)
-					}
+			plm_audio_synthesis(self, out_pos)
+			out_pos += 96
+		}
+	}
+	plm_buffer_align(self.Buffer)
+}
+
+// plm_audio_synthesis runs the synthesis filter over the three samples of each
+// subband in "Sample", and writes the 96 samples per channel it makes to
+// "Samples" from "out_pos".
+func plm_audio_synthesis(self *plm_audio_t, out_pos int64) {
+	for p := int64(0); p < 3; p++ {
+		self.V_pos = (self.V_pos - 64) & 1023
+		for ch := int64(0); ch < 2; ch++ {
+			plm_audio_idct36(self.Sample[ch], p, &self.V[ch][0], self.V_pos)
+			*(*[32]float32)(unsafe.Pointer(&self.U[0])) = [32]float32{}
+			var d_index int64 = 512 - (self.V_pos >> 1)
+			var v_index int64 = (self.V_pos % 128) >> 1
+			for v_index < 1024 {
+				for i := int64(0); i < 32; i++ {
+					self.U[i] += self.D[func() int64 {
+						p := &d_index
+						x := *p
+						*p++
+						return x
+					}()] * self.V[ch][func() int64 {
+						p := &v_index
+						x := *p
+						*p++
+						return x
+					}()]
+				}
+				v_index += 128 - 32
+				d_index += 64 - 32
+			}
+			d_index -= 512 - 32
+			v_index = (128 - 32 + 1024) - v_index
+			for v_index < 1024 {
+				for i := int64(0); i < 32; i++ {
+					self.U[i] += self.D[func() int64 {
+						p := &d_index
+						x := *p
+						*p++
+						return x
+					}()] * self.V[ch][func() int64 {
+						p := &v_index
+						x := *p
+						*p++
+						return x
+					}()]
 				}
-				out_pos += 32
+				v_index += 128 - 32
+				d_index += 64 - 32
+			}
+			for j := int64(0); j < 32; j++ {
+				self.Samples.Interleaved[((out_pos+j)<<1)+ch] = float32(float64(self.U[j]) / 2.147418112e+09)
 			}
 		}
+		out_pos += 32
 	}
-	plm_buffer_align(self.Buffer)
 }
 func plm_audio_read_allocation(self *plm_audio_t, sb int64, tab3 int64) *plm_quantizer_spec_t {
 	var (
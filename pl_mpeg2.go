@@ -0,0 +1,795 @@
+package mpg
+
+import "math"
+
+// MPEG2 video, as found on DVDs, extends the MPEG1 syntax with extensions
+// following the sequence and picture headers. The decoder in pl_mpeg.go reads
+// the headers both share and hands MPEG2 pictures to the functions here.
+//
+// Only the Main Profile is supported, which is 4:2:0 chroma without
+// scalability. Pictures may be progressive or interlaced, and interlaced
+// frames may be coded as a whole or as two field pictures.
+//
+// MPEG2 program streams, such as ".vob" files, lay out their pack and packet
+// headers differently from MPEG1 system streams. The demuxer tells them apart
+// by the first bits of each header.
+
+const plm_video_extension_sequence = 1
+const plm_video_extension_quant_matrix = 3
+const plm_video_extension_picture_coding = 8
+
+const plm_video_picture_top_field = 1
+const plm_video_picture_bottom_field = 2
+const plm_video_picture_frame = 3
+
+// Motion types. In frame pictures, type 2 predicts the whole macroblock and
+// type 1 each of its fields. In field pictures, type 1 predicts the whole
+// macroblock and type 2 its upper and lower halves.
+const plm_video_motion_field = 1
+const plm_video_motion_frame = 2
+const plm_video_motion_16x8 = 2
+const plm_video_motion_dual_prime = 3
+
+type plm_video_mpeg2_t struct {
+	Profile_and_level          int64
+	Progressive_sequence       int64
+	Chroma_format              int64
+	Low_delay                  int64
+	F_code                     [2][2]int64
+	Intra_dc_precision         int64
+	Picture_structure          int64
+	Top_field_first            int64
+	Frame_pred_frame_dct       int64
+	Concealment_motion_vectors int64
+	Q_scale_type               int64
+	Intra_vlc_format           int64
+	Alternate_scan             int64
+	Repeat_first_field         int64
+	Progressive_frame          int64
+	Second_field               int64
+	First_field_structure      int64
+	Frame_temp                 plm_frame_t
+	Motion_type                int64
+	Motion_forward             int64
+	Motion_backward            int64
+	Dct_type                   int64
+	Pmv                        [2][2][2]int64
+	Vector                     [2][2][2]int64
+	Field_select               [2][2]int64
+	Dmvector                   [2]int64
+}
+
+var plm_video_alternate_scan [64]uint8 = [64]uint8{0, 8, 16, 24, 1, 9, 2, 10, 17, 25, 32, 40, 48, 56, 57, 49, 41, 33, 26, 18, 3, 11, 4, 12, 19, 27, 34, 42, 50, 58, 35, 43, 51, 59, 20, 28, 5, 13, 6, 14, 21, 29, 36, 44, 52, 60, 37, 45, 53, 61, 22, 30, 7, 15, 23, 31, 38, 46, 54, 62, 39, 47, 55, 63}
+var plm_video_non_linear_quantizer_scale [32]int64 = [32]int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 10, 12, 14, 16, 18, 20, 22, 24, 28, 32, 36, 40, 44, 48, 52, 56, 64, 72, 80, 88, 96, 104, 112}
+
+// plm_video_dct_coeff_intra is used instead of "plm_video_dct_coeff" for
+// intra blocks when the picture sets "Intra_vlc_format". The end of block code
+// has the value 0.
+var plm_video_dct_coeff_intra [244]plm_vlc_uint_t = [244]plm_vlc_uint_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 257}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 2}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 0, Value: 0}, {Index: 0, Value: 3}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 0, Value: 513}, {Index: 0, Value: 258}, {Index: 0, Value: 769}, {Index: 0, Value: 4}, {Index: 0, Value: 5}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 0, Value: math.MaxUint16}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 0, Value: 6}, {Index: 0, Value: 1025}, {Index: 0, Value: 1281}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 0, Value: 1793}, {Index: 0, Value: 2049}, {Index: 0, Value: 1537}, {Index: 0, Value: 514}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 0, Value: 2305}, {Index: 0, Value: 259}, {Index: 0, Value: 2561}, {Index: 0, Value: 8}, {Index: 0, Value: 9}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 0, Value: 261}, {Index: 0, Value: 2817}, {Index: 0, Value: 11}, {Index: 0, Value: 10}, {Index: 0, Value: 3329}, {Index: 0, Value: 3073}, {Index: 0, Value: 770}, {Index: 0, Value: 260}, {Index: 0, Value: 12}, {Index: 0, Value: 13}, {Index: 0, Value: 515}, {Index: 0, Value: 1026}, {Index: 0, Value: 14}, {Index: 0, Value: 15}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 0, Value: 1282}, {Index: 0, Value: 3585}, {Index: 49 << 1, Value: 0}, {Index: 0, Value: 3841}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 0, Value: 516}, {Index: 0, Value: 4097}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: 63 << 1, Value: 0}, {Index: 64 << 1, Value: 0}, {Index: 65 << 1, Value: 0}, {Index: 66 << 1, Value: 0}, {Index: 67 << 1, Value: 0}, {Index: 68 << 1, Value: 0}, {Index: 69 << 1, Value: 0}, {Index: 70 << 1, Value: 0}, {Index: 71 << 1, Value: 0}, {Index: 72 << 1, Value: 0}, {Index: 73 << 1, Value: 0}, {Index: -1, Value: 0}, {Index: 74 << 1, Value: 0}, {Index: 75 << 1, Value: 0}, {Index: 76 << 1, Value: 0}, {Index: 77 << 1, Value: 0}, {Index: 78 << 1, Value: 0}, {Index: 79 << 1, Value: 0}, {Index: 80 << 1, Value: 0}, {Index: 81 << 1, Value: 0}, {Index: 82 << 1, Value: 0}, {Index: 83 << 1, Value: 0}, {Index: 84 << 1, Value: 0}, {Index: -1, Value: 0}, {Index: 85 << 1, Value: 0}, {Index: 86 << 1, Value: 0}, {Index: 87 << 1, Value: 0}, {Index: -1, Value: 0}, {Index: 0, Value: 2050}, {Index: 0, Value: 1027}, {Index: -1, Value: 0}, {Index: -1, Value: 0}, {Index: 0, Value: 1794}, {Index: 0, Value: 5377}, {Index: 0, Value: 5121}, {Index: -1, Value: 0}, {Index: 0, Value: 4865}, {Index: 0, Value: 4609}, {Index: -1, Value: 0}, {Index: 0, Value: 771}, {Index: -1, Value: 0}, {Index: 0, Value: 1538}, {Index: 0, Value: 4353}, {Index: 88 << 1, Value: 0}, {Index: 89 << 1, Value: 0}, {Index: 90 << 1, Value: 0}, {Index: 91 << 1, Value: 0}, {Index: 92 << 1, Value: 0}, {Index: 93 << 1, Value: 0}, {Index: 94 << 1, Value: 0}, {Index: 95 << 1, Value: 0}, {Index: 96 << 1, Value: 0}, {Index: 97 << 1, Value: 0}, {Index: 98 << 1, Value: 0}, {Index: 99 << 1, Value: 0}, {Index: 100 << 1, Value: 0}, {Index: 101 << 1, Value: 0}, {Index: 0, Value: 2562}, {Index: 0, Value: 2306}, {Index: 0, Value: 1283}, {Index: 0, Value: 772}, {Index: 0, Value: 517}, {Index: 0, Value: 263}, {Index: 0, Value: 262}, {Index: -1, Value: 0}, {Index: -1, Value: 0}, {Index: 0, Value: 6657}, {Index: 0, Value: 6401}, {Index: 0, Value: 6145}, {Index: 0, Value: 5889}, {Index: 0, Value: 5633}, {Index: 102 << 1, Value: 0}, {Index: 103 << 1, Value: 0}, {Index: 104 << 1, Value: 0}, {Index: 105 << 1, Value: 0}, {Index: 106 << 1, Value: 0}, {Index: 107 << 1, Value: 0}, {Index: 108 << 1, Value: 0}, {Index: 109 << 1, Value: 0}, {Index: 110 << 1, Value: 0}, {Index: 111 << 1, Value: 0}, {Index: 112 << 1, Value: 0}, {Index: 113 << 1, Value: 0}, {Index: 0, Value: 31}, {Index: 0, Value: 30}, {Index: 0, Value: 29}, {Index: 0, Value: 28}, {Index: 0, Value: 27}, {Index: 0, Value: 26}, {Index: 0, Value: 25}, {Index: 0, Value: 24}, {Index: 0, Value: 23}, {Index: 0, Value: 22}, {Index: 0, Value: 21}, {Index: 0, Value: 20}, {Index: 0, Value: 19}, {Index: 0, Value: 18}, {Index: 0, Value: 17}, {Index: 0, Value: 16}, {Index: 114 << 1, Value: 0}, {Index: 115 << 1, Value: 0}, {Index: 116 << 1, Value: 0}, {Index: 117 << 1, Value: 0}, {Index: 118 << 1, Value: 0}, {Index: 119 << 1, Value: 0}, {Index: 120 << 1, Value: 0}, {Index: 121 << 1, Value: 0}, {Index: 0, Value: 40}, {Index: 0, Value: 39}, {Index: 0, Value: 38}, {Index: 0, Value: 37}, {Index: 0, Value: 36}, {Index: 0, Value: 35}, {Index: 0, Value: 34}, {Index: 0, Value: 33}, {Index: 0, Value: 32}, {Index: 0, Value: 270}, {Index: 0, Value: 269}, {Index: 0, Value: 268}, {Index: 0, Value: 267}, {Index: 0, Value: 266}, {Index: 0, Value: 265}, {Index: 0, Value: 264}, {Index: 0, Value: 274}, {Index: 0, Value: 273}, {Index: 0, Value: 272}, {Index: 0, Value: 271}, {Index: 0, Value: 1539}, {Index: 0, Value: 4098}, {Index: 0, Value: 3842}, {Index: 0, Value: 3586}, {Index: 0, Value: 3330}, {Index: 0, Value: 3074}, {Index: 0, Value: 2818}, {Index: 0, Value: 7937}, {Index: 0, Value: 7681}, {Index: 0, Value: 7425}, {Index: 0, Value: 7169}, {Index: 0, Value: 6913}}
+
+// plm_video_decode_sequence_extension reads the extension that follows the
+// sequence header of MPEG2 video. MPEG1 video has none, and the buffer is left
+// as it was.
+func plm_video_decode_sequence_extension(self *plm_video_t) int64 {
+	var (
+		previous_bit_index          uint64 = self.Buffer.Bit_index
+		previous_discard_read_bytes int64  = self.Buffer.Discard_read_bytes
+	)
+	self.Buffer.Discard_read_bytes = _false
+	var is_extension bool = plm_buffer_next_start_code(self.Buffer) == plm_start_extension && plm_buffer_has(self.Buffer, 48) != 0 && plm_buffer_read(self.Buffer, 4) == plm_video_extension_sequence
+	self.Buffer.Discard_read_bytes = previous_discard_read_bytes
+	if !is_extension {
+		self.Buffer.Bit_index = previous_bit_index
+		return _true
+	}
+	if self.Mpeg2 == nil {
+		self.Mpeg2 = new(plm_video_mpeg2_t)
+	}
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	m.Profile_and_level = plm_buffer_read(self.Buffer, 8)
+	m.Progressive_sequence = plm_buffer_read(self.Buffer, 1)
+	m.Chroma_format = plm_buffer_read(self.Buffer, 2)
+	self.Width |= plm_buffer_read(self.Buffer, 2) << 12
+	self.Height |= plm_buffer_read(self.Buffer, 2) << 12
+	self.Bitrate |= plm_buffer_read(self.Buffer, 12) << 18
+	plm_buffer_skip(self.Buffer, 1)
+	self.Vbv_buffer_size |= plm_buffer_read(self.Buffer, 8) << 10
+	m.Low_delay = plm_buffer_read(self.Buffer, 1)
+	var (
+		frame_rate_n int64 = plm_buffer_read(self.Buffer, 2)
+		frame_rate_d int64 = plm_buffer_read(self.Buffer, 5)
+	)
+	self.Framerate = self.Framerate * float64(frame_rate_n+1) / float64(frame_rate_d+1)
+	if m.Chroma_format != 1 {
+		self.Error = plm_error_video_unsupported
+		return _false
+	}
+	return _true
+}
+
+// plm_video_decode_extension reads an extension following a picture header.
+// Extensions that do not change how the picture is decoded are skipped.
+func plm_video_decode_extension(self *plm_video_t) {
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	switch plm_buffer_read(self.Buffer, 4) {
+	case plm_video_extension_quant_matrix:
+		if plm_buffer_read(self.Buffer, 1) != 0 {
+			for i := int64(0); i < 64; i++ {
+				self.Intra_quant_matrix[plm_video_zig_zag[i]] = uint8(plm_buffer_read(self.Buffer, 8))
+			}
+		}
+		if plm_buffer_read(self.Buffer, 1) != 0 {
+			for i := int64(0); i < 64; i++ {
+				self.Non_intra_quant_matrix[plm_video_zig_zag[i]] = uint8(plm_buffer_read(self.Buffer, 8))
+			}
+		}
+	case plm_video_extension_picture_coding:
+		for s := 0; s < 2; s++ {
+			for t := 0; t < 2; t++ {
+				m.F_code[s][t] = plm_buffer_read(self.Buffer, 4)
+			}
+		}
+		m.Intra_dc_precision = plm_buffer_read(self.Buffer, 2)
+		m.Picture_structure = plm_buffer_read(self.Buffer, 2)
+		if m.Picture_structure == 0 {
+			m.Picture_structure = plm_video_picture_frame
+		}
+		m.Top_field_first = plm_buffer_read(self.Buffer, 1)
+		m.Frame_pred_frame_dct = plm_buffer_read(self.Buffer, 1)
+		m.Concealment_motion_vectors = plm_buffer_read(self.Buffer, 1)
+		m.Q_scale_type = plm_buffer_read(self.Buffer, 1)
+		m.Intra_vlc_format = plm_buffer_read(self.Buffer, 1)
+		m.Alternate_scan = plm_buffer_read(self.Buffer, 1)
+		m.Repeat_first_field = plm_buffer_read(self.Buffer, 1)
+		plm_buffer_skip(self.Buffer, 1)
+		m.Progressive_frame = plm_buffer_read(self.Buffer, 1)
+	}
+}
+
+// plm_video_mpeg2_decode_picture decodes the slices of an MPEG2 picture. The
+// reference frames only move once both fields of a frame are decoded, and
+// "Second_field" is set while the second field is still to come.
+func plm_video_mpeg2_decode_picture(self *plm_video_t) {
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	if (self.Picture_type != plm_video_picture_type_intra && (m.F_code[0][0] == 0 || m.F_code[0][1] == 0)) || (self.Picture_type == plm_video_picture_type_b && (m.F_code[1][0] == 0 || m.F_code[1][1] == 0)) {
+		self.Error = plm_error_video_motion_code
+		return
+	}
+	if m.Second_field != 0 && (m.Picture_structure == plm_video_picture_frame || m.Picture_structure == m.First_field_structure) {
+		// The second field of the last frame is missing, so that frame is
+		// kept with only one field decoded.
+		plm_video_mpeg2_end_frame(self)
+	}
+	if m.Second_field == 0 {
+		self.Frame_current.Picture_type = self.Picture_type
+		self.Frame_current.Extra_fields = 0
+		if m.Repeat_first_field != 0 {
+			if m.Progressive_sequence == 0 {
+				self.Frame_current.Extra_fields = 1
+			} else if m.Top_field_first != 0 {
+				self.Frame_current.Extra_fields = 4
+			} else {
+				self.Frame_current.Extra_fields = 2
+			}
+		}
+		if self.Picture_type == plm_video_picture_type_intra || self.Picture_type == plm_video_picture_type_predictive {
+			m.Frame_temp = self.Frame_forward
+			self.Frame_forward = self.Frame_backward
+		}
+	}
+	var mb_height int64 = self.Mb_height
+	if m.Picture_structure != plm_video_picture_frame {
+		mb_height >>= 1
+	}
+	for self.Start_code >= plm_start_slice_first && self.Start_code <= plm_start_slice_last {
+		plm_video_mpeg2_decode_slice(self, self.Start_code&math.MaxUint8, mb_height)
+		self.Start_code = plm_buffer_next_start_code(self.Buffer)
+	}
+	if m.Picture_structure != plm_video_picture_frame && m.Second_field == 0 {
+		m.Second_field = _true
+		m.First_field_structure = m.Picture_structure
+		return
+	}
+	plm_video_mpeg2_end_frame(self)
+}
+
+// plm_video_mpeg2_end_frame makes the frame that was just decoded the
+// backward reference if it is an intra or predicted frame.
+func plm_video_mpeg2_end_frame(self *plm_video_t) {
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	m.Second_field = _false
+	if self.Frame_current.Picture_type == plm_video_picture_type_intra || self.Frame_current.Picture_type == plm_video_picture_type_predictive {
+		self.Frame_backward = self.Frame_current
+		self.Frame_current = m.Frame_temp
+	}
+}
+
+func plm_video_mpeg2_decode_slice(self *plm_video_t, slice int64, mb_height int64) {
+	var (
+		m   *plm_video_mpeg2_t = self.Mpeg2
+		row int64              = slice - 1
+	)
+	if self.Height > 2800 {
+		row += plm_buffer_read(self.Buffer, 3) << 7
+	}
+	if row >= mb_height {
+		self.Error = plm_error_video_macroblock_address
+		return
+	}
+	self.Quantizer_scale = plm_video_mpeg2_quantizer_scale(self, plm_buffer_read(self.Buffer, 5))
+	if plm_buffer_read(self.Buffer, 1) != 0 {
+		plm_buffer_skip(self.Buffer, 8)
+		for plm_buffer_read(self.Buffer, 1) != 0 {
+			plm_buffer_skip(self.Buffer, 8)
+		}
+	}
+	plm_video_mpeg2_reset_dc_predictors(self)
+	m.Pmv = [2][2][2]int64{}
+	self.Slice_begin = _true
+	self.Macroblock_address = row*self.Mb_width - 1
+	var mb_size int64 = self.Mb_width * mb_height
+	for {
+		if plm_video_mpeg2_decode_macroblock(self, mb_size) == 0 {
+			break
+		}
+		if !(self.Macroblock_address < mb_size-1 && plm_buffer_peek_non_zero(self.Buffer, 23) != 0) {
+			break
+		}
+	}
+}
+
+func plm_video_mpeg2_quantizer_scale(self *plm_video_t, code int64) int64 {
+	if self.Mpeg2.Q_scale_type != 0 {
+		return plm_video_non_linear_quantizer_scale[code]
+	}
+	return code << 1
+}
+
+func plm_video_mpeg2_reset_dc_predictors(self *plm_video_t) {
+	var predictor int64 = 128 << self.Mpeg2.Intra_dc_precision
+	self.Dc_predictor[0] = predictor
+	self.Dc_predictor[1] = predictor
+	self.Dc_predictor[2] = predictor
+}
+
+// plm_video_mpeg2_no_motion sets up the prediction of a predicted macroblock
+// that has no motion vectors, which is a zero vector from the same field.
+func plm_video_mpeg2_no_motion(self *plm_video_t) {
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	m.Pmv = [2][2][2]int64{}
+	m.Vector[0][0] = [2]int64{}
+	m.Motion_forward = _true
+	m.Motion_backward = _false
+	if m.Picture_structure == plm_video_picture_frame {
+		m.Motion_type = plm_video_motion_frame
+	} else {
+		m.Motion_type = plm_video_motion_field
+		m.Field_select[0][0] = m.Picture_structure - plm_video_picture_top_field
+	}
+}
+
+// plm_video_mpeg2_decode_macroblock decodes the next macroblock and those
+// skipped before it. It returns false if the macroblock address is invalid.
+func plm_video_mpeg2_decode_macroblock(self *plm_video_t, mb_size int64) int64 {
+	var (
+		m         *plm_video_mpeg2_t = self.Mpeg2
+		increment int64              = 0
+		t         int64              = int64(plm_buffer_read_vlc(self.Buffer, &plm_video_macroblock_address_increment[0]))
+	)
+	for t == 34 {
+		t = int64(plm_buffer_read_vlc(self.Buffer, &plm_video_macroblock_address_increment[0]))
+	}
+	for t == 35 {
+		increment += 33
+		t = int64(plm_buffer_read_vlc(self.Buffer, &plm_video_macroblock_address_increment[0]))
+	}
+	increment += t
+	if increment == 0 {
+		self.Error = plm_error_video_macroblock_address
+		return _false
+	}
+	if self.Slice_begin != 0 {
+		self.Slice_begin = _false
+		self.Macroblock_address += increment
+	} else {
+		if self.Macroblock_address+increment >= mb_size {
+			self.Error = plm_error_video_macroblock_address
+			return _false
+		}
+		if increment > 1 {
+			plm_video_mpeg2_reset_dc_predictors(self)
+			if self.Picture_type == plm_video_picture_type_predictive {
+				plm_video_mpeg2_no_motion(self)
+			}
+		}
+		for increment > 1 {
+			self.Macroblock_address++
+			self.Mb_row = self.Macroblock_address / self.Mb_width
+			self.Mb_col = self.Macroblock_address % self.Mb_width
+			plm_video_mpeg2_predict_macroblock(self)
+			increment--
+		}
+		self.Macroblock_address++
+	}
+	self.Mb_row = self.Macroblock_address / self.Mb_width
+	self.Mb_col = self.Macroblock_address % self.Mb_width
+	if self.Macroblock_address >= mb_size {
+		self.Error = plm_error_video_macroblock_address
+		return _false
+	}
+	var frame_picture bool = m.Picture_structure == plm_video_picture_frame
+	self.Macroblock_type = int64(plm_buffer_read_vlc(self.Buffer, plm_video_macroblock_type[self.Picture_type]))
+	self.Macroblock_intra = self.Macroblock_type & 1
+	m.Motion_forward = self.Macroblock_type & 8
+	m.Motion_backward = self.Macroblock_type & 4
+	if m.Motion_forward != 0 || m.Motion_backward != 0 {
+		if frame_picture && m.Frame_pred_frame_dct != 0 {
+			m.Motion_type = plm_video_motion_frame
+		} else {
+			m.Motion_type = plm_buffer_read(self.Buffer, 2)
+		}
+	} else if self.Macroblock_intra != 0 && m.Concealment_motion_vectors != 0 {
+		if frame_picture {
+			m.Motion_type = plm_video_motion_frame
+		} else {
+			m.Motion_type = plm_video_motion_field
+		}
+	}
+	m.Dct_type = 0
+	if frame_picture && m.Frame_pred_frame_dct == 0 && (self.Macroblock_intra != 0 || (self.Macroblock_type&2) != 0) {
+		m.Dct_type = plm_buffer_read(self.Buffer, 1)
+	}
+	if (self.Macroblock_type & 16) != 0 {
+		self.Quantizer_scale = plm_video_mpeg2_quantizer_scale(self, plm_buffer_read(self.Buffer, 5))
+	}
+	if self.Macroblock_intra != 0 {
+		if m.Concealment_motion_vectors != 0 {
+			// Concealment vectors only hide errors in other decoders, but
+			// they still update the motion vector predictions.
+			plm_video_mpeg2_decode_motion_vectors(self, 0)
+			plm_buffer_skip(self.Buffer, 1)
+		} else {
+			m.Pmv = [2][2][2]int64{}
+		}
+	} else {
+		plm_video_mpeg2_reset_dc_predictors(self)
+		if self.Picture_type == plm_video_picture_type_predictive && m.Motion_forward == 0 {
+			plm_video_mpeg2_no_motion(self)
+		}
+		if (self.Macroblock_type & 8) != 0 {
+			plm_video_mpeg2_decode_motion_vectors(self, 0)
+		}
+		if (self.Macroblock_type & 4) != 0 {
+			plm_video_mpeg2_decode_motion_vectors(self, 1)
+		}
+		plm_video_mpeg2_predict_macroblock(self)
+	}
+	var cbp int64
+	if (self.Macroblock_type & 2) != 0 {
+		cbp = int64(plm_buffer_read_vlc(self.Buffer, &plm_video_code_block_pattern[0]))
+	} else if self.Macroblock_intra != 0 {
+		cbp = 63
+	}
+	for block, mask := int64(0), int64(32); block < 6; block++ {
+		if (cbp & mask) != 0 {
+			plm_video_mpeg2_decode_block(self, block)
+		}
+		mask >>= 1
+	}
+	return _true
+}
+
+// plm_video_mpeg2_decode_motion_vectors reads the forward (s = 0) or
+// backward (s = 1) motion vectors of a macroblock.
+func plm_video_mpeg2_decode_motion_vectors(self *plm_video_t, s int64) {
+	var (
+		m            *plm_video_mpeg2_t = self.Mpeg2
+		count        int64              = 1
+		field_format bool               = m.Picture_structure != plm_video_picture_frame
+		dual_prime   bool               = m.Motion_type == plm_video_motion_dual_prime
+	)
+	if m.Picture_structure == plm_video_picture_frame {
+		if m.Motion_type == plm_video_motion_field {
+			count = 2
+			field_format = true
+		} else if dual_prime {
+			field_format = true
+		}
+	} else if m.Motion_type == plm_video_motion_16x8 {
+		count = 2
+	}
+	for r := int64(0); r < count; r++ {
+		if field_format && !dual_prime {
+			m.Field_select[r][s] = plm_buffer_read(self.Buffer, 1)
+		}
+		plm_video_mpeg2_decode_motion_vector(self, r, s, field_format && m.Picture_structure == plm_video_picture_frame, dual_prime)
+	}
+	if count == 1 {
+		m.Pmv[1][s] = m.Pmv[0][s]
+	}
+}
+
+// plm_video_mpeg2_decode_motion_vector reads one motion vector. Field
+// vectors in frame pictures have half the vertical range, so their prediction
+// is halved and the vector doubled again to predict the next one.
+func plm_video_mpeg2_decode_motion_vector(self *plm_video_t, r int64, s int64, field_in_frame bool, dual_prime bool) {
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	for t := 0; t < 2; t++ {
+		var prediction int64 = m.Pmv[r][s][t]
+		if t == 1 && field_in_frame {
+			prediction >>= 1
+		}
+		var vector int64 = plm_video_decode_motion_vector(self, m.F_code[s][t]-1, prediction)
+		if dual_prime {
+			m.Dmvector[t] = 0
+			if plm_buffer_read(self.Buffer, 1) != 0 {
+				m.Dmvector[t] = 1 - plm_buffer_read(self.Buffer, 1)*2
+			}
+		}
+		m.Vector[r][s][t] = vector
+		if t == 1 && field_in_frame {
+			vector <<= 1
+		}
+		m.Pmv[r][s][t] = vector
+	}
+}
+
+// plm_video_mpeg2_dual_prime returns the vector that predicts a field from
+// the field of opposite parity in dual prime prediction. "m" scales the
+// vector by the distance between the fields, in half units, and "e" moves it
+// by half a line between the fields.
+func plm_video_mpeg2_dual_prime(vector [2]int64, dmvector [2]int64, m int64, e int64) (int64, int64) {
+	var scale = func(v int64) int64 {
+		v *= m
+		if v > 0 {
+			v++
+		}
+		return v >> 1
+	}
+	return scale(vector[0]) + dmvector[0], scale(vector[1]) + dmvector[1] + e
+}
+
+// plm_video_mpeg2_reference returns the frame that field "field" of the
+// forward (s = 0) or backward (s = 1) prediction is read from. The second
+// field of a predicted frame can be predicted from the first.
+func plm_video_mpeg2_reference(self *plm_video_t, s int64, field int64) *plm_frame_t {
+	var m *plm_video_mpeg2_t = self.Mpeg2
+	if s != 0 {
+		return &self.Frame_backward
+	}
+	if m.Second_field != 0 && self.Picture_type == plm_video_picture_type_predictive && field != m.Picture_structure-plm_video_picture_top_field {
+		return &self.Frame_current
+	}
+	return &self.Frame_forward
+}
+
+func plm_video_mpeg2_predict_macroblock(self *plm_video_t) {
+	var (
+		m       *plm_video_mpeg2_t = self.Mpeg2
+		average bool
+	)
+	for s := int64(0); s < 2; s++ {
+		if (s == 0 && m.Motion_forward == 0) || (s == 1 && m.Motion_backward == 0) {
+			continue
+		}
+		var vector [2][2]int64 = [2][2]int64{m.Vector[0][s], m.Vector[1][s]}
+		if m.Picture_structure == plm_video_picture_frame {
+			var ref *plm_frame_t = plm_video_mpeg2_reference(self, s, 0)
+			switch m.Motion_type {
+			case plm_video_motion_frame:
+				plm_video_mpeg2_predict_block(self, ref, 0, 0, 1, self.Mb_row<<4, 16, vector[0], average)
+			case plm_video_motion_field:
+				for r := int64(0); r < 2; r++ {
+					plm_video_mpeg2_predict_block(self, ref, r, m.Field_select[r][s], 2, self.Mb_row<<3, 8, vector[r], average)
+				}
+			case plm_video_motion_dual_prime:
+				var mul int64 = 3
+				if m.Top_field_first != 0 {
+					mul = 1
+				}
+				var top, bottom [2]int64
+				top[0], top[1] = plm_video_mpeg2_dual_prime(vector[0], m.Dmvector, mul, -1)
+				bottom[0], bottom[1] = plm_video_mpeg2_dual_prime(vector[0], m.Dmvector, 4-mul, 1)
+				plm_video_mpeg2_predict_block(self, ref, 0, 0, 2, self.Mb_row<<3, 8, vector[0], average)
+				plm_video_mpeg2_predict_block(self, ref, 0, 1, 2, self.Mb_row<<3, 8, top, true)
+				plm_video_mpeg2_predict_block(self, ref, 1, 1, 2, self.Mb_row<<3, 8, vector[0], average)
+				plm_video_mpeg2_predict_block(self, ref, 1, 0, 2, self.Mb_row<<3, 8, bottom, true)
+			}
+		} else {
+			var field int64 = m.Picture_structure - plm_video_picture_top_field
+			switch m.Motion_type {
+			case plm_video_motion_field:
+				var ref *plm_frame_t = plm_video_mpeg2_reference(self, s, m.Field_select[0][s])
+				plm_video_mpeg2_predict_block(self, ref, field, m.Field_select[0][s], 2, self.Mb_row<<4, 16, vector[0], average)
+			case plm_video_motion_16x8:
+				for r := int64(0); r < 2; r++ {
+					var ref *plm_frame_t = plm_video_mpeg2_reference(self, s, m.Field_select[r][s])
+					plm_video_mpeg2_predict_block(self, ref, field, m.Field_select[r][s], 2, self.Mb_row<<4+r<<3, 8, vector[r], average)
+				}
+			case plm_video_motion_dual_prime:
+				var opposite [2]int64
+				opposite[0], opposite[1] = plm_video_mpeg2_dual_prime(vector[0], m.Dmvector, 1, field*2-1)
+				plm_video_mpeg2_predict_block(self, plm_video_mpeg2_reference(self, s, field), field, field, 2, self.Mb_row<<4, 16, vector[0], average)
+				plm_video_mpeg2_predict_block(self, plm_video_mpeg2_reference(self, s, 1-field), field, 1-field, 2, self.Mb_row<<4, 16, opposite, true)
+			}
+		}
+		average = true
+	}
+}
+
+// plm_video_mpeg2_predict_block predicts "height" luma lines of the current
+// macroblock, starting at line "y", and the chroma lines that go with them.
+// The lines are read from field "source" of "ref" and written to field
+// "dest" of the current frame, or, with "step" 1, from and to whole frames.
+// With "average" set, the prediction is averaged with the one already there.
+func plm_video_mpeg2_predict_block(self *plm_video_t, ref *plm_frame_t, dest int64, source int64, step int64, y int64, height int64, vector [2]int64, average bool) {
+	var (
+		d *plm_frame_t = &self.Frame_current
+		x int64        = self.Mb_col << 4
+	)
+	plm_video_mpeg2_predict_plane(&d.Y, &ref.Y, dest, source, step, x, y, 16, height, vector[0], vector[1], average)
+	plm_video_mpeg2_predict_plane(&d.Cr, &ref.Cr, dest, source, step, x>>1, y>>1, 8, height>>1, vector[0]/2, vector[1]/2, average)
+	plm_video_mpeg2_predict_plane(&d.Cb, &ref.Cb, dest, source, step, x>>1, y>>1, 8, height>>1, vector[0]/2, vector[1]/2, average)
+}
+
+// plm_video_mpeg2_predict_plane copies a "w" by "h" block at "x", "y" from
+// plane "s", moved by the half pixel vector "dx", "dy", to the same place in
+// plane "d". Every "step"th line is used, starting at "d_field" and
+// "s_field". Reads outside the plane repeat its edge pixels.
+func plm_video_mpeg2_predict_plane(d *plm_plane_t, s *plm_plane_t, d_field int64, s_field int64, step int64, x int64, y int64, w int64, h int64, dx int64, dy int64, average bool) {
+	var (
+		width    int64   = int64(s.Width)
+		lines    int64   = int64(s.Height) / step
+		dst      []uint8 = planeBytes(*d)
+		src      []uint8 = planeBytes(*s)
+		sx       int64   = x + dx>>1
+		sy       int64   = y + dy>>1
+		half_x   int64   = dx & 1
+		half_y   int64   = dy & 1
+		si       int64   = (sy*step+s_field)*width + sx
+		s_stride int64   = width * step
+	)
+	if sx < 0 || sy < 0 || sx+w+half_x > width || sy+h+half_y > lines {
+		var edge [17 * 17]uint8
+		for j := int64(0); j <= h; j++ {
+			var line int64 = sy + j
+			if line < 0 {
+				line = 0
+			} else if line >= lines {
+				line = lines - 1
+			}
+			for i := int64(0); i <= w; i++ {
+				var col int64 = sx + i
+				if col < 0 {
+					col = 0
+				} else if col >= width {
+					col = width - 1
+				}
+				edge[j*17+i] = src[(line*step+s_field)*width+col]
+			}
+		}
+		src, si, s_stride = edge[:], 0, 17
+	}
+	var (
+		di       int64 = (y*step+d_field)*int64(d.Width) + x
+		d_stride int64 = int64(d.Width) * step
+	)
+	for j := int64(0); j < h; j++ {
+		for i := int64(0); i < w; i++ {
+			var p int64 = int64(src[si+i])
+			switch half_x | half_y<<1 {
+			case 1:
+				p = (p + int64(src[si+i+1]) + 1) >> 1
+			case 2:
+				p = (p + int64(src[si+i+s_stride]) + 1) >> 1
+			case 3:
+				p = (p + int64(src[si+i+1]) + int64(src[si+i+s_stride]) + int64(src[si+i+s_stride+1]) + 2) >> 2
+			}
+			if average {
+				p = (int64(dst[di+i]) + p + 1) >> 1
+			}
+			dst[di+i] = uint8(p)
+		}
+		si += s_stride
+		di += d_stride
+	}
+}
+
+func plm_video_mpeg2_decode_block(self *plm_video_t, block int64) {
+	var (
+		m            *plm_video_mpeg2_t = self.Mpeg2
+		n            int64              = 0
+		quant_matrix *[64]uint8         = &self.Non_intra_quant_matrix
+		table        *plm_vlc_uint_t    = &plm_video_dct_coeff[0]
+		scan         *[64]uint8         = &plm_video_zig_zag
+		sum          int64              = 0
+	)
+	if m.Alternate_scan != 0 {
+		scan = &plm_video_alternate_scan
+	}
+	if self.Macroblock_intra != 0 {
+		var plane_index int64 = 0
+		if block > 3 {
+			plane_index = block - 3
+		}
+		var (
+			predictor int64 = self.Dc_predictor[plane_index]
+			dct_size  int64 = int64(plm_buffer_read_vlc(self.Buffer, plm_video_dct_size[plane_index]))
+		)
+		if dct_size > 0 {
+			var differential int64 = plm_buffer_read(self.Buffer, dct_size)
+			if (differential & (1 << (dct_size - 1))) != 0 {
+				predictor += differential
+			} else {
+				predictor += -(1 << dct_size) | (differential + 1)
+			}
+		}
+		self.Dc_predictor[plane_index] = predictor
+		sum = predictor << (3 - m.Intra_dc_precision)
+		self.Block_data[0] = sum * int64(plm_video_premultiplier_matrix[0])
+		quant_matrix = &self.Intra_quant_matrix
+		if m.Intra_vlc_format != 0 {
+			table = &plm_video_dct_coeff_intra[0]
+		}
+		n = 1
+	}
+	for {
+		var (
+			run   int64
+			level int64
+			coeff uint16 = plm_buffer_read_vlc_uint(self.Buffer, table)
+		)
+		if table == &plm_video_dct_coeff[0] {
+			if coeff == 1 && n > 0 && plm_buffer_read(self.Buffer, 1) == 0 {
+				break
+			}
+		} else if coeff == 0 {
+			break
+		}
+		if coeff == math.MaxUint16 {
+			run = plm_buffer_read(self.Buffer, 6)
+			level = plm_buffer_read(self.Buffer, 12)
+			if level >= 2048 {
+				level -= 4096
+			}
+		} else {
+			run = int64(coeff) >> 8
+			level = int64(coeff) & math.MaxUint8
+			if plm_buffer_read(self.Buffer, 1) != 0 {
+				level = -level
+			}
+		}
+		n += run
+		if n >= 64 {
+			break
+		}
+		var index int64 = int64(scan[n])
+		n++
+		level <<= 1
+		if self.Macroblock_intra == 0 {
+			if level < 0 {
+				level--
+			} else {
+				level++
+			}
+		}
+		level = level * self.Quantizer_scale * int64(quant_matrix[index]) / 32
+		if level > 2047 {
+			level = 2047
+		} else if level < -2048 {
+			level = -2048
+		}
+		sum += level
+		self.Block_data[index] = level * int64(plm_video_premultiplier_matrix[index])
+	}
+	// Mismatch control keeps the sum of the coefficients odd, so that rounding
+	// in the inverse DCT does not drift between decoders.
+	if (sum & 1) == 0 {
+		var last int64 = self.Block_data[63] / int64(plm_video_premultiplier_matrix[63])
+		if (last & 1) != 0 {
+			last--
+		} else {
+			last++
+		}
+		self.Block_data[63] = last * int64(plm_video_premultiplier_matrix[63])
+	}
+	var (
+		plane  *plm_plane_t
+		x      int64
+		y      int64
+		field  int64 = 0
+		step   int64 = 1
+		offset int64 = 0
+	)
+	if block < 4 {
+		plane = &self.Frame_current.Y
+		x = self.Mb_col<<4 + (block&1)<<3
+		y = self.Mb_row<<4 + (block&2)<<2
+		if m.Dct_type != 0 {
+			// Each block holds eight lines of one field of the macroblock.
+			y = self.Mb_row<<4 + block>>1
+			step = 2
+		}
+	} else {
+		if block == 4 {
+			plane = &self.Frame_current.Cb
+		} else {
+			plane = &self.Frame_current.Cr
+		}
+		x = self.Mb_col << 3
+		y = self.Mb_row << 3
+	}
+	var width int64 = int64(plane.Width)
+	if m.Picture_structure != plm_video_picture_frame {
+		field = m.Picture_structure - plm_video_picture_top_field
+		offset = (y*2 + field) * width
+		step = 2
+	} else {
+		offset = y * width
+	}
+	var (
+		d        []uint8 = planeBytes(*plane)
+		di       int64   = offset + x
+		d_stride int64   = width * step
+	)
+	plm_video_idct(&self.Block_data[0])
+	for j := int64(0); j < 8; j++ {
+		for i := int64(0); i < 8; i++ {
+			var value int64 = self.Block_data[j*8+i]
+			if self.Macroblock_intra == 0 {
+				value += int64(d[di+i])
+			}
+			d[di+i] = plm_clamp(value)
+		}
+		di += d_stride
+	}
+	self.Block_data = [64]int64{}
+}
+
+// plm_demux_decode_mpeg2_pack_header reads the rest of the pack header of an
+// MPEG2 program stream after its '01' marker bits. Its system clock reference
+// has a 27MHz extension, and it ends with up to 7 stuffing bytes.
+func plm_demux_decode_mpeg2_pack_header(self *plm_demux_t) {
+	var clock float64 = plm_demux_decode_time(self)
+	var extension int64 = plm_buffer_read(self.Buffer, 9)
+	self.System_clock_ref = clock + float64(extension)/27000000.0
+	plm_buffer_skip(self.Buffer, 1)
+	self.Mux_rate = plm_buffer_read(self.Buffer, 22)
+	plm_buffer_skip(self.Buffer, 7)
+	var stuffing_length int64 = plm_buffer_read(self.Buffer, 3)
+	plm_buffer_skip(self.Buffer, uint64(stuffing_length)<<3)
+}
+
+// plm_demux_decode_mpeg2_packet reads the rest of an MPEG2 PES header after
+// its '10' marker bits. Only the time stamps are used, and the other optional
+// fields and stuffing bytes are skipped by their total length.
+func plm_demux_decode_mpeg2_packet(self *plm_demux_t) *plm_packet_t {
+	plm_buffer_skip(self.Buffer, 6)
+	var pts_dts_flags int64 = plm_buffer_read(self.Buffer, 2)
+	plm_buffer_skip(self.Buffer, 6)
+	var header_length int64 = plm_buffer_read(self.Buffer, 8)
+	if self.Next_packet.Length < uint64(header_length+3) {
+		self.Error = plm_error_demux_packet_header
+		self.Error_offset = plm_buffer_tell(self.Buffer)
+		// Skip the corrupt packet and continue with the next one.
+		self.Next_packet.Length = 0
+		return plm_demux_decode(self)
+	}
+	self.Next_packet.Length -= uint64(header_length + 3)
+	self.Next_packet.Pts = float64(-1)
+	self.Next_packet.Dts = float64(-1)
+	if pts_dts_flags&2 != 0 && header_length >= 5 {
+		plm_buffer_skip(self.Buffer, 4)
+		self.Next_packet.Pts = plm_demux_decode_time(self)
+		self.Last_decoded_pts = self.Next_packet.Pts
+		self.Next_packet.Dts = self.Next_packet.Pts
+		header_length -= 5
+		if pts_dts_flags == 3 && header_length >= 5 {
+			plm_buffer_skip(self.Buffer, 4)
+			self.Next_packet.Dts = plm_demux_decode_time(self)
+			header_length -= 5
+		}
+	}
+	plm_buffer_skip(self.Buffer, uint64(header_length)<<3)
+	return plm_demux_get_packet(self)
+}
@@ -0,0 +1,310 @@
+package mpg
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// testdata/mpeg2.m2v is a 48x32 MPEG2 video of 13 frames at 25 frames per
+// second. It mixes frame and field pictures, B frames, field and dual prime
+// motion, field DCT, the alternate scan and an intra frame whose second field
+// is a P field. Frames 1, 3 and 9 repeat their first field. The checksum is of
+// the reconstruction of the encoder that made it.
+const (
+	mpeg2TestFile   = "testdata/mpeg2.m2v"
+	mpeg2TestFrames = 13
+	mpeg2TestSum    = "ac11f623314874b2151ea3bbb296ed5db4bcbf67"
+)
+
+func TestVideoDecoderMPEG2(t *testing.T) {
+	d, err := NewVideoDecoderFromFilename(mpeg2TestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if d.Width() != 48 || d.Height() != 32 || d.FrameRate() != 25 {
+		t.Fatalf("video is %dx%d at %v fps, want 48x32 at 25 fps", d.Width(), d.Height(), d.FrameRate())
+	}
+	sum := sha1.New()
+	fields := 0
+	n := 0
+	for ; ; n++ {
+		f, err := d.NextFrame()
+		if err != nil {
+			break
+		}
+		if want := time.Duration(n*2+fields) * time.Second / 50; f.Time != want {
+			t.Errorf("frame %d is at %v, want %v", n, f.Time, want)
+		}
+		if n == 1 || n == 3 || n == 9 {
+			fields++
+		}
+		sum.Write(f.Y)
+		sum.Write(f.Cb)
+		sum.Write(f.Cr)
+	}
+	if n != mpeg2TestFrames {
+		t.Errorf("decoded %d frames, want %d", n, mpeg2TestFrames)
+	}
+	if err := d.LastError(); err != nil {
+		t.Errorf("decoding failed: %v", err)
+	}
+	if got := fmt.Sprintf("%x", sum.Sum(nil)); got != mpeg2TestSum {
+		t.Errorf("checksum of the frames is %s, want %s", got, mpeg2TestSum)
+	}
+}
+
+// TestDisplaySizeMPEG2 checks the aspect ratio of MPEG2 video, whose sequence
+// header codes the aspect ratio of the display rather than of the pixels.
+func TestDisplaySizeMPEG2(t *testing.T) {
+	es := readTestFile(t, mpeg2TestFile)
+	for _, c := range []struct {
+		code, width int
+	}{
+		{1, 48},          // square pixels
+		{2, 43},          // 4:3
+		{3, 57},          // 16:9
+		{4, 71},          // 2.21:1
+		{5, 48}, {8, 48}, // not defined
+	} {
+		data := withAspectRatio(es, c.code)
+		d, err := NewVideoDecoderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		par := float64(c.width) / 48
+		if c.code >= 2 && c.code <= 4 {
+			par = displayAspectRatios[c.code] * 32 / 48
+		}
+		if got := d.PixelAspectRatio(); math.Abs(got-par) > 1e-9 {
+			t.Errorf("code %d: pixel aspect ratio is %v, want %v", c.code, got, par)
+		}
+		d.Close()
+
+		var ps bytes.Buffer
+		m, err := NewMuxer(&ps, MuxerOptions{Video: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.WriteVideo(data, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Close(); err != nil {
+			t.Fatal(err)
+		}
+		plm, err := NewPlayerFromBytes(ps.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if width, height := plm.DisplaySize(); width != c.width || height != 32 {
+			t.Errorf("code %d: display size is %dx%d, want %dx32", c.code, width, height, c.width)
+		}
+		if n := len(decodeFrames(t, plm)); n != mpeg2TestFrames {
+			t.Errorf("code %d: player decoded %d frames, want %d", c.code, n, mpeg2TestFrames)
+		}
+	}
+}
+
+// toMPEG2PS rewrites the MPEG1 system stream "in" as an MPEG2 program stream
+// with the same packets. Pack headers get an SCR extension and stuffing, and
+// PES headers get stuffing counted by their header data length. Video packets
+// with a PTS also get a DTS, one frame before it.
+func toMPEG2PS(t testing.TB, in []byte) []byte {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	var out []byte
+	for i := 0; i+4 <= len(in); {
+		if !bytes.HasPrefix(in[i:], []byte{0x00, 0x00, 0x01}) {
+			t.Fatalf("no start code at %d", i)
+		}
+		code := in[i+3]
+		i += 4
+		switch code {
+		case 0xBA:
+			h := in[i : i+8]
+			scr := readBits(h, 0, 4, 3)<<30 | readBits(h, 0, 8, 15)<<15 | readBits(h, 0, 24, 15)
+			var w bitWriter
+			w.write(1, 2) // '01' marker
+			w.write(uint32(scr>>30), 3)
+			w.write(1, 1)
+			w.write(uint32(scr>>15&0x7FFF), 15)
+			w.write(1, 1)
+			w.write(uint32(scr&0x7FFF), 15)
+			w.write(1, 1)
+			w.write(uint32(rng.Intn(300)), 9) // SCR extension
+			w.write(1, 1)
+			w.write(uint32(readBits(h, 0, 41, 22)), 22) // mux rate
+			w.write(3, 2)
+			w.write(0x1F, 5) // reserved
+			stuffing := rng.Intn(8)
+			w.write(uint32(stuffing), 3)
+			for k := 0; k < stuffing; k++ {
+				w.write(0xFF, 8)
+			}
+			out = append(append(out, 0x00, 0x00, 0x01, code), w.bytes()...)
+			i += 8
+		case 0xB9:
+			out = append(out, 0x00, 0x00, 0x01, code)
+		case 0xBB, 0xBE, 0xBF:
+			n := int(in[i])<<8 | int(in[i+1])
+			out = append(append(out, 0x00, 0x00, 0x01, code), in[i:i+2+n]...)
+			i += 2 + n
+		default:
+			n := int(in[i])<<8 | int(in[i+1])
+			packet := in[i+2 : i+2+n]
+			i += 2 + n
+			j := 0
+			for packet[j] == 0xFF {
+				j++
+			}
+			if packet[j]>>6 == 1 {
+				j += 2 // STD buffer size
+			}
+			var flags byte
+			var timestamps []byte
+			switch packet[j] >> 4 {
+			case 0x2:
+				flags, timestamps = 2, append(timestamps, packet[j:j+5]...)
+				if code == 0xE0 {
+					pts := readBits(packet, j, 4, 3)<<30 | readBits(packet, j, 8, 15)<<15 | readBits(packet, j, 24, 15)
+					timestamps[0] = timestamps[0]&0x0F | 0x30
+					flags, timestamps = 3, appendTestTimestamp(timestamps, 0x1, int64(pts-90000/testFrameRate))
+				}
+				j += 5
+			case 0x3:
+				flags, timestamps = 3, append(timestamps, packet[j:j+10]...)
+				j += 10
+			default:
+				j++
+			}
+			stuffing := rng.Intn(4)
+			header := []byte{0x81, flags << 6, byte(len(timestamps) + stuffing)}
+			header = append(header, timestamps...)
+			for k := 0; k < stuffing; k++ {
+				header = append(header, 0xFF)
+			}
+			body := append(header, packet[j:]...)
+			out = append(out, 0x00, 0x00, 0x01, code, byte(len(body)>>8), byte(len(body)))
+			out = append(out, body...)
+		}
+	}
+	return out
+}
+
+// decodeSamples decodes all audio of "plm" without its video.
+func decodeSamples(t testing.TB, plm *Player) (interleaved []float32) {
+	t.Helper()
+	plm.SetVideoEnabled(false)
+	for {
+		s, err := plm.NextSamples()
+		if err != nil {
+			return interleaved
+		}
+		interleaved = append(interleaved, s.Interleaved...)
+	}
+}
+
+// TestMPEG2ProgramStream plays the streams of the test file from an MPEG2
+// program stream.
+func TestMPEG2ProgramStream(t *testing.T) {
+	mpeg1 := readTestFile(t, testFile)
+	mpeg2 := toMPEG2PS(t, mpeg1)
+	if mpeg2[4]>>6 != 1 {
+		t.Fatal("the pack header is not an MPEG2 pack header")
+	}
+
+	open := func(data []byte) *Player {
+		plm, err := NewPlayerFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plm
+	}
+	want, got := open(mpeg1), open(mpeg2)
+	if want.Info().MuxRate != got.Info().MuxRate || want.Info().StartTime != got.Info().StartTime ||
+		want.Duration() != got.Duration() || !bytes.Equal(want.Info().StreamIDs, got.Info().StreamIDs) {
+		t.Errorf("MPEG2 program stream info is %+v, want %+v", got.Info(), want.Info())
+	}
+	wantFrames, gotFrames := decodeFrames(t, want), decodeFrames(t, got)
+	if len(gotFrames) != len(wantFrames) {
+		t.Fatalf("decoded %d frames, want %d", len(gotFrames), len(wantFrames))
+	}
+	for i := range gotFrames {
+		if gotFrames[i] != wantFrames[i] {
+			t.Errorf("frame %d differs", i)
+		}
+	}
+	for stream := 0; stream < 2; stream++ {
+		want, got := open(mpeg1), open(mpeg2)
+		want.SetAudioStream(stream)
+		got.SetAudioStream(stream)
+		wantSamples, gotSamples := decodeSamples(t, want), decodeSamples(t, got)
+		if len(gotSamples) != len(wantSamples) || len(gotSamples) == 0 {
+			t.Errorf("audio stream %d: decoded %d samples, want %d", stream, len(gotSamples), len(wantSamples))
+			continue
+		}
+		for i := range gotSamples {
+			if gotSamples[i] != wantSamples[i] {
+				t.Errorf("audio stream %d: sample %d differs", stream, i)
+				break
+			}
+		}
+	}
+	if err := got.LastError(); err != nil {
+		t.Error(err)
+	}
+
+	// The demuxer reads the same packets, with the time stamps of the PES
+	// headers.
+	d1, err := NewDemuxerFromBytes(mpeg1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := NewDemuxerFromBytes(mpeg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		p1, err1 := d1.NextPacket()
+		p2, err2 := d2.NextPacket()
+		if err1 != nil || err2 != nil {
+			if err1 != io.EOF || err2 != io.EOF {
+				t.Errorf("demuxing ended with %v and %v, want io.EOF", err1, err2)
+			}
+			break
+		}
+		dts := p1.PTS
+		if p1.StreamID == 0xE0 && p1.PTS != NoTimestamp {
+			dts -= time.Second / testFrameRate
+		}
+		if p2.StreamID != p1.StreamID || p2.PTS != p1.PTS || p2.DTS != dts || !bytes.Equal(p2.Data, p1.Data) {
+			t.Fatalf("packet 0x%02X with PTS %v and DTS %v, want 0x%02X with PTS %v and DTS %v",
+				p2.StreamID, p2.PTS, p2.DTS, p1.StreamID, p1.PTS, dts)
+		}
+	}
+
+	// A header data length longer than the packet skips only that packet.
+	packet := bytes.Index(mpeg2[len(mpeg2)/2:], []byte{0x00, 0x00, 0x01, 0xC0}) + len(mpeg2)/2
+	mpeg2[packet+4], mpeg2[packet+5] = 0, mpeg2[packet+8]+2
+	plm := open(mpeg2)
+	n := 0
+	for ; ; n++ {
+		if _, err := plm.NextFrame(); err != nil {
+			break
+		}
+	}
+	if n != testFrames {
+		t.Errorf("decoded %d frames after a corrupt packet, want %d", n, testFrames)
+	}
+	var decodeErr *DecodeError
+	if !errors.As(plm.LastError(), &decodeErr) || decodeErr.Stage != StageDemux {
+		t.Errorf("LastError returned %v, want a demux error", plm.LastError())
+	}
+}
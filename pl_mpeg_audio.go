@@ -0,0 +1,694 @@
+package mpg
+
+import "math"
+
+// Besides Layer II, MPEG1 audio may be coded in Layer I or Layer III (MP3).
+// The decoder in pl_mpeg.go reads the frame header all layers share and hands
+// frames of the other layers to the functions here. Their subband samples go
+// through the same synthesis filter as those of Layer II.
+//
+// Layer I frames hold 384 samples, coded much like Layer II without grouping.
+//
+// Layer III frames hold two granules of 576 samples per channel. Their
+// Huffman coded spectrum may start in the main data of earlier frames (the bit
+// reservoir), and is turned into subband samples by an IMDCT of long or short
+// blocks. Joint stereo frames may code the channels as their sum and
+// difference, and the upper bands of the right channel as a ratio of the left.
+
+type plm_audio_granule_t struct {
+	Part2_3_length     int64
+	Big_values         int64
+	Global_gain        int64
+	Scalefac_compress  int64
+	Window_switching   int64
+	Block_type         int64
+	Mixed_block        int64
+	Table_select       [3]int64
+	Subblock_gain      [3]int64
+	Region0_count      int64
+	Region1_count      int64
+	Preflag            int64
+	Scalefac_scale     int64
+	Count1table_select int64
+}
+type plm_audio_layer3_t struct {
+	Main_data_begin int64
+	Scfsi           [2][4]int64
+	Granule         [2][2]plm_audio_granule_t
+	Scalefac_l      [2][22]int64
+	Scalefac_s      [2][13][3]int64
+	Values          [576]int64
+	Count           [2]int64
+	Xr              [2][576]float32
+	Overlap         [2][576]float32
+	Reservoir       [2048]uint8
+	Reservoir_size  int64
+	Main_data       plm_buffer_t
+}
+
+const plm_audio_layer3_max_reservoir = 511
+
+var plm_audio_layer_i_bit_rate [28]int16 = [28]int16{32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256}
+var plm_audio_layer_iii_bit_rate [28]int16 = [28]int16{32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+
+// plm_audio_layer_i_quant_tab holds the quantizers of Layer I, for samples
+// of 2 to 15 bits.
+var plm_audio_layer_i_quant_tab [14]plm_quantizer_spec_t = [14]plm_quantizer_spec_t{{Levels: 3, Group: 0, Bits: 2}, {Levels: 7, Group: 0, Bits: 3}, {Levels: 15, Group: 0, Bits: 4}, {Levels: 31, Group: 0, Bits: 5}, {Levels: 63, Group: 0, Bits: 6}, {Levels: math.MaxInt8, Group: 0, Bits: 7}, {Levels: math.MaxUint8, Group: 0, Bits: 8}, {Levels: 511, Group: 0, Bits: 9}, {Levels: 1023, Group: 0, Bits: 10}, {Levels: 2047, Group: 0, Bits: 11}, {Levels: 4095, Group: 0, Bits: 12}, {Levels: 8191, Group: 0, Bits: 13}, {Levels: 0x3FFF, Group: 0, Bits: 14}, {Levels: math.MaxInt16, Group: 0, Bits: 15}}
+
+var plm_audio_layer3_band_long [3][23]int16 = [3][23]int16{{0, 4, 8, 12, 16, 20, 24, 30, 36, 44, 52, 62, 74, 90, 110, 134, 162, 196, 238, 288, 342, 418, 576}, {0, 4, 8, 12, 16, 20, 24, 30, 36, 42, 50, 60, 72, 88, 106, 128, 156, 190, 230, 276, 330, 384, 576}, {0, 4, 8, 12, 16, 20, 24, 30, 36, 44, 54, 66, 82, 102, 126, 156, 194, 240, 296, 364, 448, 550, 576}}
+var plm_audio_layer3_band_short [3][14]int16 = [3][14]int16{{0, 4, 8, 12, 16, 22, 30, 40, 52, 66, 84, 106, 136, 192}, {0, 4, 8, 12, 16, 22, 28, 38, 50, 64, 80, 100, 126, 192}, {0, 4, 8, 12, 16, 22, 30, 42, 58, 78, 104, 138, 180, 192}}
+var plm_audio_layer3_pretab [22]uint8 = [22]uint8{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 3, 3, 3, 2, 0}
+var plm_audio_layer3_slen [2][16]uint8 = [2][16]uint8{{0, 0, 0, 0, 3, 1, 1, 1, 2, 2, 2, 3, 3, 3, 4, 4}, {0, 1, 2, 3, 0, 1, 2, 3, 1, 2, 3, 1, 2, 3, 2, 3}}
+
+// Huffman tables of the big values hold both values of a pair as x<<4 | y.
+// Tables that share a code, such as 16 to 23, differ in the number of extra
+// bits that follow a value of 15.
+var plm_audio_huffman_table_1 [6]plm_vlc_t = [6]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 0, Value: 16}, {Index: 0, Value: 17}, {Index: 0, Value: 1}}
+var plm_audio_huffman_table_2 [16]plm_vlc_t = [16]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 32}, {Index: 0, Value: 34}, {Index: 0, Value: 2}}
+var plm_audio_huffman_table_3 [16]plm_vlc_t = [16]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 16}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 32}, {Index: 0, Value: 34}, {Index: 0, Value: 2}}
+var plm_audio_huffman_table_5 [30]plm_vlc_t = [30]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 0, Value: 49}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 14 << 1, Value: 0}, {Index: 0, Value: 50}, {Index: 0, Value: 19}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 0, Value: 34}, {Index: 0, Value: 51}, {Index: 0, Value: 35}}
+var plm_audio_huffman_table_6 [30]plm_vlc_t = [30]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 32}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 34}, {Index: 0, Value: 2}, {Index: 14 << 1, Value: 0}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 48}, {Index: 0, Value: 51}, {Index: 0, Value: 3}}
+var plm_audio_huffman_table_7 [70]plm_vlc_t = [70]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 0, Value: 33}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 0, Value: 20}, {Index: 0, Value: 65}, {Index: 0, Value: 64}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 48}, {Index: 0, Value: 34}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 0, Value: 21}, {Index: 0, Value: 81}, {Index: 31 << 1, Value: 0}, {Index: 0, Value: 80}, {Index: 32 << 1, Value: 0}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 4}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 3}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 0, Value: 53}, {Index: 0, Value: 68}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 5}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 51}, {Index: 0, Value: 85}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 83}}
+var plm_audio_huffman_table_8 [70]plm_vlc_t = [70]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 4 << 1, Value: 0}, {}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 0, Value: 65}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 0, Value: 21}, {Index: 0, Value: 81}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 20}, {Index: 0, Value: 4}, {Index: 0, Value: 64}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 32 << 1, Value: 0}, {Index: 0, Value: 83}, {Index: 33 << 1, Value: 0}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 5}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 80}, {Index: 0, Value: 51}, {Index: 34 << 1, Value: 0}, {Index: 0, Value: 69}, {Index: 0, Value: 53}, {Index: 0, Value: 68}, {Index: 0, Value: 85}, {Index: 0, Value: 84}}
+var plm_audio_huffman_table_9 [70]plm_vlc_t = [70]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 32}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 21 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 0, Value: 2}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 0, Value: 20}, {Index: 0, Value: 65}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 0, Value: 81}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 32 << 1, Value: 0}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 51}, {Index: 0, Value: 64}, {Index: 33 << 1, Value: 0}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 34 << 1, Value: 0}, {Index: 0, Value: 68}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 21}, {Index: 0, Value: 80}, {Index: 0, Value: 4}, {Index: 0, Value: 85}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 5}}
+var plm_audio_huffman_table_10 [126]plm_vlc_t = [126]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 48}, {Index: 0, Value: 34}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 0, Value: 23}, {Index: 0, Value: 113}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 0, Value: 22}, {Index: 0, Value: 97}, {Index: 0, Value: 96}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 0, Value: 20}, {Index: 0, Value: 65}, {Index: 0, Value: 64}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 3}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 0, Value: 39}, {Index: 0, Value: 114}, {Index: 54 << 1, Value: 0}, {Index: 0, Value: 112}, {Index: 0, Value: 98}, {Index: 55 << 1, Value: 0}, {Index: 0, Value: 6}, {Index: 56 << 1, Value: 0}, {Index: 0, Value: 54}, {Index: 0, Value: 38}, {Index: 57 << 1, Value: 0}, {Index: 0, Value: 21}, {Index: 0, Value: 81}, {Index: 58 << 1, Value: 0}, {Index: 0, Value: 5}, {Index: 0, Value: 80}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 51}, {Index: 0, Value: 4}, {Index: 59 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 0, Value: 86}, {Index: 0, Value: 101}, {Index: 0, Value: 55}, {Index: 0, Value: 115}, {Index: 0, Value: 70}, {Index: 62 << 1, Value: 0}, {Index: 0, Value: 99}, {Index: 0, Value: 100}, {Index: 0, Value: 7}, {Index: 0, Value: 69}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 0, Value: 68}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 119}, {Index: 0, Value: 103}, {Index: 0, Value: 118}, {Index: 0, Value: 87}, {Index: 0, Value: 117}, {Index: 0, Value: 102}, {Index: 0, Value: 85}, {Index: 0, Value: 84}}
+var plm_audio_huffman_table_11 [126]plm_vlc_t = [126]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 0, Value: 33}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 31 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 0, Value: 113}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 0, Value: 98}, {Index: 40 << 1, Value: 0}, {Index: 0, Value: 22}, {Index: 0, Value: 97}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 0, Value: 39}, {Index: 0, Value: 114}, {Index: 51 << 1, Value: 0}, {Index: 0, Value: 23}, {Index: 0, Value: 112}, {Index: 0, Value: 54}, {Index: 0, Value: 99}, {Index: 0, Value: 96}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 0, Value: 21}, {Index: 0, Value: 38}, {Index: 0, Value: 6}, {Index: 0, Value: 81}, {Index: 0, Value: 52}, {Index: 0, Value: 80}, {Index: 54 << 1, Value: 0}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 20}, {Index: 0, Value: 65}, {Index: 0, Value: 4}, {Index: 0, Value: 64}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 0, Value: 55}, {Index: 0, Value: 115}, {Index: 0, Value: 70}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 0, Value: 100}, {Index: 0, Value: 7}, {Index: 0, Value: 68}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 5}, {Index: 0, Value: 67}, {Index: 0, Value: 51}, {Index: 0, Value: 119}, {Index: 0, Value: 103}, {Index: 0, Value: 118}, {Index: 0, Value: 117}, {Index: 0, Value: 102}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 62 << 1, Value: 0}, {Index: 0, Value: 86}, {Index: 0, Value: 101}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 0, Value: 87}, {Index: 0, Value: 85}}
+var plm_audio_huffman_table_12 [126]plm_vlc_t = [126]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 18 << 1, Value: 0}, {}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 34}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 0, Value: 51}, {Index: 0, Value: 65}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 40 << 1, Value: 0}, {Index: 0, Value: 48}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 0, Value: 38}, {Index: 0, Value: 98}, {Index: 0, Value: 97}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 0, Value: 21}, {Index: 0, Value: 81}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 54 << 1, Value: 0}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 20}, {Index: 0, Value: 64}, {Index: 0, Value: 3}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 0, Value: 86}, {Index: 0, Value: 55}, {Index: 59 << 1, Value: 0}, {Index: 0, Value: 39}, {Index: 0, Value: 114}, {Index: 0, Value: 70}, {Index: 0, Value: 100}, {Index: 0, Value: 23}, {Index: 0, Value: 113}, {Index: 60 << 1, Value: 0}, {Index: 0, Value: 54}, {Index: 0, Value: 99}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 68}, {Index: 61 << 1, Value: 0}, {Index: 0, Value: 22}, {Index: 0, Value: 96}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 80}, {Index: 0, Value: 4}, {Index: 62 << 1, Value: 0}, {Index: 0, Value: 118}, {Index: 0, Value: 87}, {Index: 0, Value: 117}, {Index: 0, Value: 102}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 0, Value: 101}, {Index: 0, Value: 115}, {Index: 0, Value: 85}, {Index: 0, Value: 7}, {Index: 0, Value: 112}, {Index: 0, Value: 6}, {Index: 0, Value: 5}, {Index: 0, Value: 119}, {Index: 0, Value: 103}}
+var plm_audio_huffman_table_13 [510]plm_vlc_t = [510]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 16}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 0, Value: 65}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 0, Value: 34}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: 63 << 1, Value: 0}, {Index: 64 << 1, Value: 0}, {Index: 65 << 1, Value: 0}, {Index: 66 << 1, Value: 0}, {Index: 67 << 1, Value: 0}, {Index: 68 << 1, Value: 0}, {Index: 0, Value: 129}, {Index: 69 << 1, Value: 0}, {Index: 70 << 1, Value: 0}, {Index: 71 << 1, Value: 0}, {Index: 72 << 1, Value: 0}, {Index: 73 << 1, Value: 0}, {Index: 0, Value: 21}, {Index: 0, Value: 81}, {Index: 74 << 1, Value: 0}, {Index: 75 << 1, Value: 0}, {Index: 76 << 1, Value: 0}, {Index: 0, Value: 20}, {Index: 0, Value: 4}, {Index: 0, Value: 64}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 77 << 1, Value: 0}, {Index: 78 << 1, Value: 0}, {Index: 79 << 1, Value: 0}, {Index: 80 << 1, Value: 0}, {Index: 81 << 1, Value: 0}, {Index: 82 << 1, Value: 0}, {Index: 83 << 1, Value: 0}, {Index: 84 << 1, Value: 0}, {Index: 85 << 1, Value: 0}, {Index: 86 << 1, Value: 0}, {Index: 87 << 1, Value: 0}, {Index: 88 << 1, Value: 0}, {Index: 89 << 1, Value: 0}, {Index: 90 << 1, Value: 0}, {Index: 91 << 1, Value: 0}, {Index: 92 << 1, Value: 0}, {Index: 93 << 1, Value: 0}, {Index: 94 << 1, Value: 0}, {Index: 95 << 1, Value: 0}, {Index: 96 << 1, Value: 0}, {Index: 97 << 1, Value: 0}, {Index: 98 << 1, Value: 0}, {Index: 99 << 1, Value: 0}, {Index: 100 << 1, Value: 0}, {Index: 0, Value: 25}, {Index: 0, Value: 145}, {Index: 101 << 1, Value: 0}, {Index: 102 << 1, Value: 0}, {Index: 103 << 1, Value: 0}, {Index: 0, Value: 40}, {Index: 0, Value: 130}, {Index: 0, Value: 24}, {Index: 104 << 1, Value: 0}, {Index: 0, Value: 23}, {Index: 0, Value: 113}, {Index: 105 << 1, Value: 0}, {Index: 106 << 1, Value: 0}, {Index: 107 << 1, Value: 0}, {Index: 108 << 1, Value: 0}, {Index: 109 << 1, Value: 0}, {Index: 0, Value: 8}, {Index: 0, Value: 128}, {Index: 0, Value: 22}, {Index: 0, Value: 97}, {Index: 0, Value: 6}, {Index: 0, Value: 96}, {Index: 110 << 1, Value: 0}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 5}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 80}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 51}, {Index: 111 << 1, Value: 0}, {Index: 112 << 1, Value: 0}, {Index: 113 << 1, Value: 0}, {Index: 114 << 1, Value: 0}, {Index: 115 << 1, Value: 0}, {Index: 116 << 1, Value: 0}, {Index: 117 << 1, Value: 0}, {Index: 118 << 1, Value: 0}, {Index: 119 << 1, Value: 0}, {Index: 120 << 1, Value: 0}, {Index: 121 << 1, Value: 0}, {Index: 122 << 1, Value: 0}, {Index: 123 << 1, Value: 0}, {Index: 124 << 1, Value: 0}, {Index: 125 << 1, Value: 0}, {Index: 126 << 1, Value: 0}, {Index: 127 << 1, Value: 0}, {Index: 128 << 1, Value: 0}, {Index: 129 << 1, Value: 0}, {Index: 130 << 1, Value: 0}, {Index: 131 << 1, Value: 0}, {Index: 132 << 1, Value: 0}, {Index: 133 << 1, Value: 0}, {Index: 0, Value: 178}, {Index: 0, Value: 27}, {Index: 0, Value: 177}, {Index: 134 << 1, Value: 0}, {Index: 135 << 1, Value: 0}, {Index: 136 << 1, Value: 0}, {Index: 137 << 1, Value: 0}, {Index: 0, Value: 42}, {Index: 0, Value: 162}, {Index: 0, Value: 26}, {Index: 0, Value: 161}, {Index: 138 << 1, Value: 0}, {Index: 0, Value: 160}, {Index: 139 << 1, Value: 0}, {Index: 0, Value: 147}, {Index: 140 << 1, Value: 0}, {Index: 141 << 1, Value: 0}, {Index: 0, Value: 41}, {Index: 0, Value: 146}, {Index: 142 << 1, Value: 0}, {Index: 0, Value: 56}, {Index: 0, Value: 131}, {Index: 143 << 1, Value: 0}, {Index: 144 << 1, Value: 0}, {Index: 145 << 1, Value: 0}, {Index: 0, Value: 9}, {Index: 0, Value: 144}, {Index: 0, Value: 72}, {Index: 0, Value: 132}, {Index: 0, Value: 114}, {Index: 146 << 1, Value: 0}, {Index: 0, Value: 55}, {Index: 0, Value: 39}, {Index: 0, Value: 85}, {Index: 0, Value: 7}, {Index: 0, Value: 112}, {Index: 0, Value: 54}, {Index: 0, Value: 99}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 38}, {Index: 0, Value: 98}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 0, Value: 68}, {Index: 147 << 1, Value: 0}, {Index: 148 << 1, Value: 0}, {Index: 149 << 1, Value: 0}, {Index: 150 << 1, Value: 0}, {Index: 151 << 1, Value: 0}, {Index: 152 << 1, Value: 0}, {Index: 153 << 1, Value: 0}, {Index: 154 << 1, Value: 0}, {Index: 155 << 1, Value: 0}, {Index: 156 << 1, Value: 0}, {Index: 157 << 1, Value: 0}, {Index: 158 << 1, Value: 0}, {Index: 159 << 1, Value: 0}, {Index: 160 << 1, Value: 0}, {Index: 161 << 1, Value: 0}, {Index: 162 << 1, Value: 0}, {Index: 163 << 1, Value: 0}, {Index: 164 << 1, Value: 0}, {Index: 165 << 1, Value: 0}, {Index: 166 << 1, Value: 0}, {Index: 167 << 1, Value: 0}, {Index: 0, Value: 209}, {Index: 168 << 1, Value: 0}, {Index: 169 << 1, Value: 0}, {Index: 170 << 1, Value: 0}, {Index: 171 << 1, Value: 0}, {Index: 0, Value: 60}, {Index: 0, Value: 44}, {Index: 0, Value: 194}, {Index: 0, Value: 91}, {Index: 172 << 1, Value: 0}, {Index: 0, Value: 28}, {Index: 0, Value: 193}, {Index: 173 << 1, Value: 0}, {Index: 0, Value: 192}, {Index: 174 << 1, Value: 0}, {Index: 175 << 1, Value: 0}, {Index: 0, Value: 59}, {Index: 0, Value: 179}, {Index: 176 << 1, Value: 0}, {Index: 0, Value: 43}, {Index: 177 << 1, Value: 0}, {Index: 0, Value: 164}, {Index: 178 << 1, Value: 0}, {Index: 0, Value: 148}, {Index: 179 << 1, Value: 0}, {Index: 0, Value: 11}, {Index: 0, Value: 176}, {Index: 0, Value: 150}, {Index: 0, Value: 74}, {Index: 0, Value: 58}, {Index: 0, Value: 163}, {Index: 0, Value: 89}, {Index: 0, Value: 149}, {Index: 0, Value: 10}, {Index: 0, Value: 104}, {Index: 0, Value: 134}, {Index: 0, Value: 73}, {Index: 0, Value: 57}, {Index: 0, Value: 88}, {Index: 0, Value: 133}, {Index: 0, Value: 103}, {Index: 0, Value: 87}, {Index: 0, Value: 117}, {Index: 0, Value: 102}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 0, Value: 86}, {Index: 0, Value: 101}, {Index: 0, Value: 115}, {Index: 0, Value: 70}, {Index: 0, Value: 100}, {Index: 180 << 1, Value: 0}, {Index: 181 << 1, Value: 0}, {Index: 182 << 1, Value: 0}, {Index: 183 << 1, Value: 0}, {Index: 184 << 1, Value: 0}, {Index: 185 << 1, Value: 0}, {Index: 186 << 1, Value: 0}, {Index: 187 << 1, Value: 0}, {Index: 188 << 1, Value: 0}, {Index: 189 << 1, Value: 0}, {Index: 190 << 1, Value: 0}, {Index: 191 << 1, Value: 0}, {Index: 192 << 1, Value: 0}, {Index: 193 << 1, Value: 0}, {Index: 0, Value: 31}, {Index: 0, Value: 241}, {Index: 0, Value: 240}, {Index: 194 << 1, Value: 0}, {Index: 195 << 1, Value: 0}, {Index: 196 << 1, Value: 0}, {Index: 0, Value: 226}, {Index: 197 << 1, Value: 0}, {Index: 0, Value: 30}, {Index: 0, Value: 225}, {Index: 198 << 1, Value: 0}, {Index: 199 << 1, Value: 0}, {Index: 200 << 1, Value: 0}, {Index: 201 << 1, Value: 0}, {Index: 202 << 1, Value: 0}, {Index: 203 << 1, Value: 0}, {Index: 0, Value: 198}, {Index: 0, Value: 61}, {Index: 204 << 1, Value: 0}, {Index: 0, Value: 45}, {Index: 0, Value: 210}, {Index: 0, Value: 29}, {Index: 0, Value: 183}, {Index: 205 << 1, Value: 0}, {Index: 206 << 1, Value: 0}, {Index: 0, Value: 195}, {Index: 207 << 1, Value: 0}, {Index: 0, Value: 75}, {Index: 0, Value: 13}, {Index: 0, Value: 208}, {Index: 0, Value: 138}, {Index: 0, Value: 168}, {Index: 0, Value: 76}, {Index: 0, Value: 196}, {Index: 0, Value: 107}, {Index: 0, Value: 182}, {Index: 0, Value: 181}, {Index: 0, Value: 137}, {Index: 0, Value: 152}, {Index: 0, Value: 12}, {Index: 0, Value: 180}, {Index: 0, Value: 106}, {Index: 0, Value: 166}, {Index: 0, Value: 121}, {Index: 0, Value: 136}, {Index: 0, Value: 90}, {Index: 0, Value: 165}, {Index: 0, Value: 105}, {Index: 0, Value: 120}, {Index: 0, Value: 135}, {Index: 0, Value: 119}, {Index: 0, Value: 118}, {Index: 208 << 1, Value: 0}, {Index: 209 << 1, Value: 0}, {Index: 210 << 1, Value: 0}, {Index: 211 << 1, Value: 0}, {Index: 212 << 1, Value: 0}, {Index: 213 << 1, Value: 0}, {Index: 214 << 1, Value: 0}, {Index: 215 << 1, Value: 0}, {Index: 216 << 1, Value: 0}, {Index: 217 << 1, Value: 0}, {Index: 218 << 1, Value: 0}, {Index: 219 << 1, Value: 0}, {Index: 220 << 1, Value: 0}, {Index: 221 << 1, Value: 0}, {Index: 0, Value: 63}, {Index: 222 << 1, Value: 0}, {Index: 0, Value: 47}, {Index: 0, Value: 242}, {Index: 223 << 1, Value: 0}, {Index: 0, Value: 15}, {Index: 224 << 1, Value: 0}, {Index: 0, Value: 171}, {Index: 225 << 1, Value: 0}, {Index: 0, Value: 78}, {Index: 226 << 1, Value: 0}, {Index: 0, Value: 62}, {Index: 0, Value: 185}, {Index: 227 << 1, Value: 0}, {Index: 0, Value: 186}, {Index: 0, Value: 229}, {Index: 0, Value: 228}, {Index: 0, Value: 140}, {Index: 0, Value: 109}, {Index: 0, Value: 227}, {Index: 0, Value: 46}, {Index: 0, Value: 14}, {Index: 0, Value: 224}, {Index: 0, Value: 93}, {Index: 0, Value: 213}, {Index: 0, Value: 124}, {Index: 0, Value: 199}, {Index: 0, Value: 77}, {Index: 0, Value: 139}, {Index: 0, Value: 184}, {Index: 0, Value: 212}, {Index: 0, Value: 154}, {Index: 0, Value: 169}, {Index: 0, Value: 108}, {Index: 0, Value: 211}, {Index: 0, Value: 123}, {Index: 0, Value: 92}, {Index: 0, Value: 197}, {Index: 0, Value: 153}, {Index: 0, Value: 122}, {Index: 0, Value: 167}, {Index: 0, Value: 151}, {Index: 228 << 1, Value: 0}, {Index: 229 << 1, Value: 0}, {Index: 230 << 1, Value: 0}, {Index: 231 << 1, Value: 0}, {Index: 232 << 1, Value: 0}, {Index: 233 << 1, Value: 0}, {Index: 234 << 1, Value: 0}, {Index: 235 << 1, Value: 0}, {Index: 236 << 1, Value: 0}, {Index: 237 << 1, Value: 0}, {Index: 238 << 1, Value: 0}, {Index: 0, Value: 247}, {Index: 0, Value: 218}, {Index: 239 << 1, Value: 0}, {Index: 240 << 1, Value: 0}, {Index: 0, Value: 111}, {Index: 0, Value: 232}, {Index: 0, Value: 95}, {Index: 0, Value: 157}, {Index: 0, Value: 217}, {Index: 0, Value: 245}, {Index: 0, Value: 231}, {Index: 0, Value: 172}, {Index: 0, Value: 187}, {Index: 0, Value: 79}, {Index: 0, Value: 244}, {Index: 241 << 1, Value: 0}, {Index: 0, Value: 243}, {Index: 0, Value: 141}, {Index: 0, Value: 216}, {Index: 0, Value: 110}, {Index: 0, Value: 156}, {Index: 0, Value: 201}, {Index: 0, Value: 94}, {Index: 0, Value: 125}, {Index: 0, Value: 215}, {Index: 0, Value: 200}, {Index: 0, Value: 214}, {Index: 0, Value: 155}, {Index: 0, Value: 170}, {Index: 242 << 1, Value: 0}, {Index: 243 << 1, Value: 0}, {Index: 244 << 1, Value: 0}, {Index: 245 << 1, Value: 0}, {Index: 246 << 1, Value: 0}, {Index: 247 << 1, Value: 0}, {Index: 0, Value: 236}, {Index: 0, Value: 221}, {Index: 248 << 1, Value: 0}, {Index: 0, Value: 190}, {Index: 0, Value: 235}, {Index: 0, Value: 159}, {Index: 0, Value: 249}, {Index: 0, Value: 234}, {Index: 0, Value: 189}, {Index: 0, Value: 219}, {Index: 0, Value: 143}, {Index: 0, Value: 248}, {Index: 0, Value: 204}, {Index: 249 << 1, Value: 0}, {Index: 0, Value: 142}, {Index: 250 << 1, Value: 0}, {Index: 0, Value: 173}, {Index: 0, Value: 188}, {Index: 0, Value: 203}, {Index: 0, Value: 246}, {Index: 0, Value: 202}, {Index: 0, Value: 230}, {Index: 251 << 1, Value: 0}, {Index: 0, Value: 255}, {Index: 0, Value: 239}, {Index: 0, Value: 223}, {Index: 0, Value: 238}, {Index: 0, Value: 207}, {Index: 0, Value: 222}, {Index: 0, Value: 191}, {Index: 0, Value: 251}, {Index: 0, Value: 206}, {Index: 0, Value: 220}, {Index: 252 << 1, Value: 0}, {Index: 0, Value: 250}, {Index: 0, Value: 205}, {Index: 0, Value: 174}, {Index: 0, Value: 158}, {Index: 0, Value: 127}, {Index: 0, Value: 126}, {Index: 253 << 1, Value: 0}, {Index: 0, Value: 237}, {Index: 0, Value: 175}, {Index: 0, Value: 233}, {Index: 254 << 1, Value: 0}, {Index: 0, Value: 253}, {Index: 0, Value: 254}, {Index: 0, Value: 252}}
+var plm_audio_huffman_table_15 [510]plm_vlc_t = [510]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 12 << 1, Value: 0}, {}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 0, Value: 65}, {Index: 60 << 1, Value: 0}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 61 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 0, Value: 48}, {Index: 62 << 1, Value: 0}, {Index: 63 << 1, Value: 0}, {Index: 64 << 1, Value: 0}, {Index: 65 << 1, Value: 0}, {Index: 66 << 1, Value: 0}, {Index: 67 << 1, Value: 0}, {Index: 68 << 1, Value: 0}, {Index: 69 << 1, Value: 0}, {Index: 70 << 1, Value: 0}, {Index: 71 << 1, Value: 0}, {Index: 72 << 1, Value: 0}, {Index: 73 << 1, Value: 0}, {Index: 74 << 1, Value: 0}, {Index: 75 << 1, Value: 0}, {Index: 76 << 1, Value: 0}, {Index: 77 << 1, Value: 0}, {Index: 78 << 1, Value: 0}, {Index: 79 << 1, Value: 0}, {Index: 80 << 1, Value: 0}, {Index: 81 << 1, Value: 0}, {Index: 82 << 1, Value: 0}, {Index: 83 << 1, Value: 0}, {Index: 84 << 1, Value: 0}, {Index: 85 << 1, Value: 0}, {Index: 86 << 1, Value: 0}, {Index: 87 << 1, Value: 0}, {Index: 88 << 1, Value: 0}, {Index: 89 << 1, Value: 0}, {Index: 90 << 1, Value: 0}, {Index: 91 << 1, Value: 0}, {Index: 92 << 1, Value: 0}, {Index: 93 << 1, Value: 0}, {Index: 0, Value: 97}, {Index: 94 << 1, Value: 0}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 21}, {Index: 0, Value: 81}, {Index: 95 << 1, Value: 0}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 51}, {Index: 0, Value: 20}, {Index: 0, Value: 4}, {Index: 0, Value: 64}, {Index: 0, Value: 3}, {Index: 96 << 1, Value: 0}, {Index: 97 << 1, Value: 0}, {Index: 98 << 1, Value: 0}, {Index: 99 << 1, Value: 0}, {Index: 100 << 1, Value: 0}, {Index: 101 << 1, Value: 0}, {Index: 102 << 1, Value: 0}, {Index: 103 << 1, Value: 0}, {Index: 104 << 1, Value: 0}, {Index: 105 << 1, Value: 0}, {Index: 106 << 1, Value: 0}, {Index: 107 << 1, Value: 0}, {Index: 108 << 1, Value: 0}, {Index: 109 << 1, Value: 0}, {Index: 110 << 1, Value: 0}, {Index: 111 << 1, Value: 0}, {Index: 112 << 1, Value: 0}, {Index: 113 << 1, Value: 0}, {Index: 114 << 1, Value: 0}, {Index: 115 << 1, Value: 0}, {Index: 116 << 1, Value: 0}, {Index: 117 << 1, Value: 0}, {Index: 118 << 1, Value: 0}, {Index: 119 << 1, Value: 0}, {Index: 120 << 1, Value: 0}, {Index: 121 << 1, Value: 0}, {Index: 122 << 1, Value: 0}, {Index: 123 << 1, Value: 0}, {Index: 124 << 1, Value: 0}, {Index: 125 << 1, Value: 0}, {Index: 126 << 1, Value: 0}, {Index: 127 << 1, Value: 0}, {Index: 128 << 1, Value: 0}, {Index: 129 << 1, Value: 0}, {Index: 0, Value: 145}, {Index: 130 << 1, Value: 0}, {Index: 131 << 1, Value: 0}, {Index: 132 << 1, Value: 0}, {Index: 133 << 1, Value: 0}, {Index: 134 << 1, Value: 0}, {Index: 0, Value: 40}, {Index: 0, Value: 130}, {Index: 0, Value: 24}, {Index: 0, Value: 129}, {Index: 135 << 1, Value: 0}, {Index: 136 << 1, Value: 0}, {Index: 137 << 1, Value: 0}, {Index: 138 << 1, Value: 0}, {Index: 0, Value: 39}, {Index: 0, Value: 114}, {Index: 0, Value: 100}, {Index: 0, Value: 23}, {Index: 0, Value: 85}, {Index: 0, Value: 113}, {Index: 139 << 1, Value: 0}, {Index: 0, Value: 54}, {Index: 0, Value: 99}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 38}, {Index: 0, Value: 98}, {Index: 0, Value: 22}, {Index: 140 << 1, Value: 0}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 0, Value: 68}, {Index: 0, Value: 5}, {Index: 0, Value: 80}, {Index: 141 << 1, Value: 0}, {Index: 142 << 1, Value: 0}, {Index: 143 << 1, Value: 0}, {Index: 144 << 1, Value: 0}, {Index: 145 << 1, Value: 0}, {Index: 146 << 1, Value: 0}, {Index: 147 << 1, Value: 0}, {Index: 148 << 1, Value: 0}, {Index: 149 << 1, Value: 0}, {Index: 150 << 1, Value: 0}, {Index: 151 << 1, Value: 0}, {Index: 152 << 1, Value: 0}, {Index: 153 << 1, Value: 0}, {Index: 154 << 1, Value: 0}, {Index: 155 << 1, Value: 0}, {Index: 156 << 1, Value: 0}, {Index: 157 << 1, Value: 0}, {Index: 158 << 1, Value: 0}, {Index: 159 << 1, Value: 0}, {Index: 160 << 1, Value: 0}, {Index: 161 << 1, Value: 0}, {Index: 162 << 1, Value: 0}, {Index: 163 << 1, Value: 0}, {Index: 164 << 1, Value: 0}, {Index: 165 << 1, Value: 0}, {Index: 166 << 1, Value: 0}, {Index: 167 << 1, Value: 0}, {Index: 168 << 1, Value: 0}, {Index: 169 << 1, Value: 0}, {Index: 170 << 1, Value: 0}, {Index: 0, Value: 194}, {Index: 171 << 1, Value: 0}, {Index: 172 << 1, Value: 0}, {Index: 173 << 1, Value: 0}, {Index: 174 << 1, Value: 0}, {Index: 175 << 1, Value: 0}, {Index: 176 << 1, Value: 0}, {Index: 0, Value: 179}, {Index: 177 << 1, Value: 0}, {Index: 178 << 1, Value: 0}, {Index: 0, Value: 178}, {Index: 179 << 1, Value: 0}, {Index: 0, Value: 177}, {Index: 180 << 1, Value: 0}, {Index: 181 << 1, Value: 0}, {Index: 182 << 1, Value: 0}, {Index: 183 << 1, Value: 0}, {Index: 0, Value: 163}, {Index: 0, Value: 89}, {Index: 0, Value: 149}, {Index: 0, Value: 42}, {Index: 0, Value: 162}, {Index: 0, Value: 26}, {Index: 0, Value: 161}, {Index: 184 << 1, Value: 0}, {Index: 0, Value: 104}, {Index: 0, Value: 134}, {Index: 0, Value: 73}, {Index: 0, Value: 148}, {Index: 0, Value: 57}, {Index: 0, Value: 147}, {Index: 185 << 1, Value: 0}, {Index: 0, Value: 88}, {Index: 0, Value: 133}, {Index: 0, Value: 41}, {Index: 0, Value: 103}, {Index: 0, Value: 118}, {Index: 0, Value: 146}, {Index: 0, Value: 25}, {Index: 0, Value: 144}, {Index: 0, Value: 72}, {Index: 0, Value: 132}, {Index: 0, Value: 87}, {Index: 0, Value: 117}, {Index: 0, Value: 56}, {Index: 0, Value: 131}, {Index: 0, Value: 102}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 0, Value: 8}, {Index: 0, Value: 128}, {Index: 0, Value: 86}, {Index: 0, Value: 101}, {Index: 0, Value: 55}, {Index: 0, Value: 115}, {Index: 0, Value: 70}, {Index: 0, Value: 7}, {Index: 0, Value: 112}, {Index: 0, Value: 6}, {Index: 0, Value: 96}, {Index: 186 << 1, Value: 0}, {Index: 187 << 1, Value: 0}, {Index: 188 << 1, Value: 0}, {Index: 189 << 1, Value: 0}, {Index: 190 << 1, Value: 0}, {Index: 191 << 1, Value: 0}, {Index: 192 << 1, Value: 0}, {Index: 193 << 1, Value: 0}, {Index: 194 << 1, Value: 0}, {Index: 195 << 1, Value: 0}, {Index: 196 << 1, Value: 0}, {Index: 197 << 1, Value: 0}, {Index: 198 << 1, Value: 0}, {Index: 199 << 1, Value: 0}, {Index: 200 << 1, Value: 0}, {Index: 201 << 1, Value: 0}, {Index: 202 << 1, Value: 0}, {Index: 203 << 1, Value: 0}, {Index: 204 << 1, Value: 0}, {Index: 205 << 1, Value: 0}, {Index: 206 << 1, Value: 0}, {Index: 207 << 1, Value: 0}, {Index: 208 << 1, Value: 0}, {Index: 209 << 1, Value: 0}, {Index: 210 << 1, Value: 0}, {Index: 211 << 1, Value: 0}, {Index: 212 << 1, Value: 0}, {Index: 213 << 1, Value: 0}, {Index: 214 << 1, Value: 0}, {Index: 215 << 1, Value: 0}, {Index: 216 << 1, Value: 0}, {Index: 217 << 1, Value: 0}, {Index: 218 << 1, Value: 0}, {Index: 219 << 1, Value: 0}, {Index: 0, Value: 212}, {Index: 220 << 1, Value: 0}, {Index: 221 << 1, Value: 0}, {Index: 222 << 1, Value: 0}, {Index: 0, Value: 211}, {Index: 0, Value: 210}, {Index: 223 << 1, Value: 0}, {Index: 0, Value: 29}, {Index: 0, Value: 123}, {Index: 0, Value: 183}, {Index: 0, Value: 209}, {Index: 224 << 1, Value: 0}, {Index: 0, Value: 197}, {Index: 0, Value: 138}, {Index: 0, Value: 168}, {Index: 0, Value: 76}, {Index: 0, Value: 196}, {Index: 0, Value: 107}, {Index: 0, Value: 182}, {Index: 225 << 1, Value: 0}, {Index: 0, Value: 60}, {Index: 0, Value: 195}, {Index: 0, Value: 122}, {Index: 0, Value: 167}, {Index: 0, Value: 166}, {Index: 226 << 1, Value: 0}, {Index: 0, Value: 44}, {Index: 0, Value: 91}, {Index: 0, Value: 181}, {Index: 0, Value: 28}, {Index: 0, Value: 137}, {Index: 0, Value: 152}, {Index: 0, Value: 193}, {Index: 0, Value: 75}, {Index: 0, Value: 180}, {Index: 0, Value: 106}, {Index: 0, Value: 59}, {Index: 0, Value: 121}, {Index: 0, Value: 151}, {Index: 0, Value: 136}, {Index: 0, Value: 43}, {Index: 0, Value: 90}, {Index: 0, Value: 165}, {Index: 0, Value: 27}, {Index: 0, Value: 176}, {Index: 0, Value: 105}, {Index: 0, Value: 150}, {Index: 0, Value: 74}, {Index: 0, Value: 164}, {Index: 0, Value: 120}, {Index: 0, Value: 135}, {Index: 0, Value: 58}, {Index: 0, Value: 10}, {Index: 0, Value: 160}, {Index: 0, Value: 119}, {Index: 0, Value: 9}, {Index: 227 << 1, Value: 0}, {Index: 228 << 1, Value: 0}, {Index: 229 << 1, Value: 0}, {Index: 230 << 1, Value: 0}, {Index: 231 << 1, Value: 0}, {Index: 232 << 1, Value: 0}, {Index: 233 << 1, Value: 0}, {Index: 234 << 1, Value: 0}, {Index: 235 << 1, Value: 0}, {Index: 236 << 1, Value: 0}, {Index: 237 << 1, Value: 0}, {Index: 238 << 1, Value: 0}, {Index: 239 << 1, Value: 0}, {Index: 240 << 1, Value: 0}, {Index: 241 << 1, Value: 0}, {Index: 242 << 1, Value: 0}, {Index: 0, Value: 203}, {Index: 0, Value: 246}, {Index: 243 << 1, Value: 0}, {Index: 244 << 1, Value: 0}, {Index: 0, Value: 245}, {Index: 0, Value: 126}, {Index: 0, Value: 231}, {Index: 0, Value: 172}, {Index: 0, Value: 202}, {Index: 0, Value: 187}, {Index: 245 << 1, Value: 0}, {Index: 0, Value: 79}, {Index: 0, Value: 244}, {Index: 0, Value: 63}, {Index: 0, Value: 243}, {Index: 0, Value: 216}, {Index: 0, Value: 230}, {Index: 0, Value: 47}, {Index: 0, Value: 242}, {Index: 246 << 1, Value: 0}, {Index: 0, Value: 31}, {Index: 0, Value: 241}, {Index: 0, Value: 156}, {Index: 0, Value: 201}, {Index: 0, Value: 94}, {Index: 0, Value: 171}, {Index: 0, Value: 186}, {Index: 0, Value: 229}, {Index: 0, Value: 125}, {Index: 0, Value: 215}, {Index: 0, Value: 78}, {Index: 0, Value: 228}, {Index: 0, Value: 140}, {Index: 0, Value: 200}, {Index: 0, Value: 62}, {Index: 0, Value: 109}, {Index: 0, Value: 214}, {Index: 0, Value: 227}, {Index: 0, Value: 155}, {Index: 0, Value: 185}, {Index: 0, Value: 46}, {Index: 0, Value: 170}, {Index: 0, Value: 226}, {Index: 0, Value: 30}, {Index: 0, Value: 225}, {Index: 247 << 1, Value: 0}, {Index: 0, Value: 93}, {Index: 0, Value: 213}, {Index: 0, Value: 124}, {Index: 0, Value: 199}, {Index: 0, Value: 77}, {Index: 0, Value: 139}, {Index: 0, Value: 184}, {Index: 0, Value: 154}, {Index: 0, Value: 169}, {Index: 0, Value: 108}, {Index: 0, Value: 198}, {Index: 0, Value: 61}, {Index: 0, Value: 45}, {Index: 0, Value: 13}, {Index: 0, Value: 92}, {Index: 0, Value: 208}, {Index: 0, Value: 153}, {Index: 0, Value: 12}, {Index: 0, Value: 192}, {Index: 0, Value: 11}, {Index: 248 << 1, Value: 0}, {Index: 249 << 1, Value: 0}, {Index: 0, Value: 238}, {Index: 250 << 1, Value: 0}, {Index: 251 << 1, Value: 0}, {Index: 252 << 1, Value: 0}, {Index: 0, Value: 251}, {Index: 253 << 1, Value: 0}, {Index: 0, Value: 221}, {Index: 0, Value: 175}, {Index: 0, Value: 250}, {Index: 0, Value: 190}, {Index: 0, Value: 235}, {Index: 0, Value: 205}, {Index: 0, Value: 220}, {Index: 0, Value: 159}, {Index: 0, Value: 249}, {Index: 0, Value: 234}, {Index: 0, Value: 189}, {Index: 0, Value: 219}, {Index: 0, Value: 143}, {Index: 0, Value: 248}, {Index: 0, Value: 204}, {Index: 0, Value: 158}, {Index: 0, Value: 233}, {Index: 0, Value: 127}, {Index: 0, Value: 247}, {Index: 0, Value: 173}, {Index: 0, Value: 218}, {Index: 0, Value: 188}, {Index: 0, Value: 111}, {Index: 254 << 1, Value: 0}, {Index: 0, Value: 142}, {Index: 0, Value: 232}, {Index: 0, Value: 95}, {Index: 0, Value: 157}, {Index: 0, Value: 217}, {Index: 0, Value: 141}, {Index: 0, Value: 110}, {Index: 0, Value: 240}, {Index: 0, Value: 14}, {Index: 0, Value: 224}, {Index: 0, Value: 255}, {Index: 0, Value: 239}, {Index: 0, Value: 254}, {Index: 0, Value: 223}, {Index: 0, Value: 253}, {Index: 0, Value: 207}, {Index: 0, Value: 252}, {Index: 0, Value: 222}, {Index: 0, Value: 237}, {Index: 0, Value: 191}, {Index: 0, Value: 206}, {Index: 0, Value: 236}, {Index: 0, Value: 174}, {Index: 0, Value: 15}}
+var plm_audio_huffman_table_16 [510]plm_vlc_t = [510]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 0, Value: 16}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 51 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 0, Value: 255}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 0, Value: 242}, {Index: 58 << 1, Value: 0}, {Index: 0, Value: 31}, {Index: 0, Value: 241}, {Index: 59 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: 63 << 1, Value: 0}, {Index: 64 << 1, Value: 0}, {Index: 65 << 1, Value: 0}, {Index: 66 << 1, Value: 0}, {Index: 67 << 1, Value: 0}, {Index: 68 << 1, Value: 0}, {Index: 69 << 1, Value: 0}, {Index: 70 << 1, Value: 0}, {Index: 71 << 1, Value: 0}, {Index: 72 << 1, Value: 0}, {Index: 73 << 1, Value: 0}, {Index: 74 << 1, Value: 0}, {Index: 75 << 1, Value: 0}, {Index: 76 << 1, Value: 0}, {Index: 77 << 1, Value: 0}, {Index: 0, Value: 81}, {Index: 78 << 1, Value: 0}, {Index: 79 << 1, Value: 0}, {Index: 80 << 1, Value: 0}, {Index: 81 << 1, Value: 0}, {Index: 0, Value: 20}, {Index: 0, Value: 65}, {Index: 82 << 1, Value: 0}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 83 << 1, Value: 0}, {Index: 84 << 1, Value: 0}, {Index: 85 << 1, Value: 0}, {Index: 86 << 1, Value: 0}, {Index: 87 << 1, Value: 0}, {Index: 88 << 1, Value: 0}, {Index: 89 << 1, Value: 0}, {Index: 0, Value: 79}, {Index: 0, Value: 244}, {Index: 0, Value: 243}, {Index: 0, Value: 240}, {Index: 90 << 1, Value: 0}, {Index: 0, Value: 47}, {Index: 0, Value: 15}, {Index: 91 << 1, Value: 0}, {Index: 92 << 1, Value: 0}, {Index: 93 << 1, Value: 0}, {Index: 94 << 1, Value: 0}, {Index: 95 << 1, Value: 0}, {Index: 96 << 1, Value: 0}, {Index: 97 << 1, Value: 0}, {Index: 98 << 1, Value: 0}, {Index: 99 << 1, Value: 0}, {Index: 100 << 1, Value: 0}, {Index: 101 << 1, Value: 0}, {Index: 102 << 1, Value: 0}, {Index: 103 << 1, Value: 0}, {Index: 104 << 1, Value: 0}, {Index: 105 << 1, Value: 0}, {Index: 106 << 1, Value: 0}, {Index: 107 << 1, Value: 0}, {Index: 108 << 1, Value: 0}, {Index: 109 << 1, Value: 0}, {Index: 110 << 1, Value: 0}, {Index: 111 << 1, Value: 0}, {Index: 112 << 1, Value: 0}, {Index: 113 << 1, Value: 0}, {Index: 114 << 1, Value: 0}, {Index: 115 << 1, Value: 0}, {Index: 0, Value: 23}, {Index: 0, Value: 113}, {Index: 116 << 1, Value: 0}, {Index: 117 << 1, Value: 0}, {Index: 118 << 1, Value: 0}, {Index: 0, Value: 98}, {Index: 0, Value: 22}, {Index: 0, Value: 97}, {Index: 119 << 1, Value: 0}, {Index: 0, Value: 83}, {Index: 120 << 1, Value: 0}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 21}, {Index: 0, Value: 5}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 80}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 51}, {Index: 0, Value: 4}, {Index: 0, Value: 64}, {Index: 121 << 1, Value: 0}, {Index: 122 << 1, Value: 0}, {Index: 123 << 1, Value: 0}, {Index: 124 << 1, Value: 0}, {Index: 0, Value: 175}, {Index: 125 << 1, Value: 0}, {Index: 126 << 1, Value: 0}, {Index: 0, Value: 143}, {Index: 0, Value: 127}, {Index: 0, Value: 247}, {Index: 0, Value: 111}, {Index: 0, Value: 246}, {Index: 0, Value: 95}, {Index: 0, Value: 245}, {Index: 0, Value: 63}, {Index: 127 << 1, Value: 0}, {Index: 128 << 1, Value: 0}, {Index: 129 << 1, Value: 0}, {Index: 130 << 1, Value: 0}, {Index: 131 << 1, Value: 0}, {Index: 132 << 1, Value: 0}, {Index: 133 << 1, Value: 0}, {Index: 134 << 1, Value: 0}, {Index: 135 << 1, Value: 0}, {Index: 136 << 1, Value: 0}, {Index: 137 << 1, Value: 0}, {Index: 138 << 1, Value: 0}, {Index: 139 << 1, Value: 0}, {Index: 140 << 1, Value: 0}, {Index: 141 << 1, Value: 0}, {Index: 142 << 1, Value: 0}, {Index: 143 << 1, Value: 0}, {Index: 144 << 1, Value: 0}, {Index: 145 << 1, Value: 0}, {Index: 146 << 1, Value: 0}, {Index: 147 << 1, Value: 0}, {Index: 148 << 1, Value: 0}, {Index: 149 << 1, Value: 0}, {Index: 150 << 1, Value: 0}, {Index: 0, Value: 162}, {Index: 0, Value: 26}, {Index: 151 << 1, Value: 0}, {Index: 152 << 1, Value: 0}, {Index: 153 << 1, Value: 0}, {Index: 0, Value: 41}, {Index: 0, Value: 146}, {Index: 154 << 1, Value: 0}, {Index: 0, Value: 25}, {Index: 0, Value: 145}, {Index: 155 << 1, Value: 0}, {Index: 156 << 1, Value: 0}, {Index: 157 << 1, Value: 0}, {Index: 158 << 1, Value: 0}, {Index: 0, Value: 130}, {Index: 159 << 1, Value: 0}, {Index: 0, Value: 24}, {Index: 0, Value: 129}, {Index: 0, Value: 128}, {Index: 160 << 1, Value: 0}, {Index: 0, Value: 55}, {Index: 0, Value: 115}, {Index: 161 << 1, Value: 0}, {Index: 0, Value: 39}, {Index: 0, Value: 114}, {Index: 162 << 1, Value: 0}, {Index: 0, Value: 7}, {Index: 0, Value: 112}, {Index: 0, Value: 54}, {Index: 0, Value: 99}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 38}, {Index: 0, Value: 6}, {Index: 0, Value: 96}, {Index: 0, Value: 53}, {Index: 0, Value: 68}, {Index: 0, Value: 239}, {Index: 0, Value: 254}, {Index: 0, Value: 223}, {Index: 0, Value: 253}, {Index: 0, Value: 207}, {Index: 0, Value: 252}, {Index: 0, Value: 191}, {Index: 0, Value: 251}, {Index: 0, Value: 250}, {Index: 0, Value: 159}, {Index: 0, Value: 249}, {Index: 0, Value: 248}, {Index: 163 << 1, Value: 0}, {Index: 164 << 1, Value: 0}, {Index: 165 << 1, Value: 0}, {Index: 166 << 1, Value: 0}, {Index: 167 << 1, Value: 0}, {Index: 168 << 1, Value: 0}, {Index: 169 << 1, Value: 0}, {Index: 170 << 1, Value: 0}, {Index: 171 << 1, Value: 0}, {Index: 172 << 1, Value: 0}, {Index: 173 << 1, Value: 0}, {Index: 174 << 1, Value: 0}, {Index: 175 << 1, Value: 0}, {Index: 176 << 1, Value: 0}, {Index: 177 << 1, Value: 0}, {Index: 178 << 1, Value: 0}, {Index: 0, Value: 226}, {Index: 179 << 1, Value: 0}, {Index: 180 << 1, Value: 0}, {Index: 181 << 1, Value: 0}, {Index: 182 << 1, Value: 0}, {Index: 0, Value: 29}, {Index: 183 << 1, Value: 0}, {Index: 184 << 1, Value: 0}, {Index: 0, Value: 44}, {Index: 185 << 1, Value: 0}, {Index: 186 << 1, Value: 0}, {Index: 187 << 1, Value: 0}, {Index: 188 << 1, Value: 0}, {Index: 0, Value: 179}, {Index: 189 << 1, Value: 0}, {Index: 0, Value: 43}, {Index: 0, Value: 178}, {Index: 0, Value: 27}, {Index: 0, Value: 177}, {Index: 190 << 1, Value: 0}, {Index: 191 << 1, Value: 0}, {Index: 192 << 1, Value: 0}, {Index: 193 << 1, Value: 0}, {Index: 0, Value: 163}, {Index: 194 << 1, Value: 0}, {Index: 0, Value: 42}, {Index: 195 << 1, Value: 0}, {Index: 0, Value: 161}, {Index: 196 << 1, Value: 0}, {Index: 0, Value: 148}, {Index: 197 << 1, Value: 0}, {Index: 0, Value: 103}, {Index: 0, Value: 10}, {Index: 0, Value: 160}, {Index: 0, Value: 57}, {Index: 0, Value: 147}, {Index: 0, Value: 88}, {Index: 0, Value: 133}, {Index: 0, Value: 118}, {Index: 0, Value: 9}, {Index: 0, Value: 144}, {Index: 0, Value: 72}, {Index: 0, Value: 132}, {Index: 0, Value: 117}, {Index: 0, Value: 56}, {Index: 0, Value: 131}, {Index: 0, Value: 102}, {Index: 0, Value: 40}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 0, Value: 8}, {Index: 0, Value: 86}, {Index: 0, Value: 101}, {Index: 0, Value: 70}, {Index: 0, Value: 100}, {Index: 0, Value: 85}, {Index: 198 << 1, Value: 0}, {Index: 199 << 1, Value: 0}, {Index: 200 << 1, Value: 0}, {Index: 201 << 1, Value: 0}, {Index: 202 << 1, Value: 0}, {Index: 203 << 1, Value: 0}, {Index: 204 << 1, Value: 0}, {Index: 205 << 1, Value: 0}, {Index: 206 << 1, Value: 0}, {Index: 207 << 1, Value: 0}, {Index: 208 << 1, Value: 0}, {Index: 209 << 1, Value: 0}, {Index: 210 << 1, Value: 0}, {Index: 211 << 1, Value: 0}, {Index: 212 << 1, Value: 0}, {Index: 0, Value: 227}, {Index: 213 << 1, Value: 0}, {Index: 214 << 1, Value: 0}, {Index: 215 << 1, Value: 0}, {Index: 216 << 1, Value: 0}, {Index: 217 << 1, Value: 0}, {Index: 218 << 1, Value: 0}, {Index: 219 << 1, Value: 0}, {Index: 0, Value: 13}, {Index: 220 << 1, Value: 0}, {Index: 221 << 1, Value: 0}, {Index: 222 << 1, Value: 0}, {Index: 0, Value: 60}, {Index: 223 << 1, Value: 0}, {Index: 0, Value: 28}, {Index: 0, Value: 192}, {Index: 224 << 1, Value: 0}, {Index: 0, Value: 46}, {Index: 0, Value: 30}, {Index: 0, Value: 211}, {Index: 0, Value: 45}, {Index: 0, Value: 210}, {Index: 0, Value: 209}, {Index: 0, Value: 59}, {Index: 225 << 1, Value: 0}, {Index: 0, Value: 196}, {Index: 0, Value: 107}, {Index: 0, Value: 195}, {Index: 0, Value: 167}, {Index: 0, Value: 194}, {Index: 0, Value: 181}, {Index: 0, Value: 193}, {Index: 0, Value: 12}, {Index: 0, Value: 75}, {Index: 0, Value: 180}, {Index: 0, Value: 106}, {Index: 0, Value: 166}, {Index: 0, Value: 90}, {Index: 0, Value: 165}, {Index: 0, Value: 11}, {Index: 0, Value: 176}, {Index: 0, Value: 105}, {Index: 0, Value: 150}, {Index: 0, Value: 74}, {Index: 0, Value: 164}, {Index: 0, Value: 120}, {Index: 0, Value: 135}, {Index: 0, Value: 58}, {Index: 0, Value: 89}, {Index: 0, Value: 149}, {Index: 0, Value: 104}, {Index: 0, Value: 134}, {Index: 0, Value: 119}, {Index: 0, Value: 73}, {Index: 0, Value: 87}, {Index: 226 << 1, Value: 0}, {Index: 227 << 1, Value: 0}, {Index: 228 << 1, Value: 0}, {Index: 229 << 1, Value: 0}, {Index: 230 << 1, Value: 0}, {Index: 231 << 1, Value: 0}, {Index: 232 << 1, Value: 0}, {Index: 0, Value: 189}, {Index: 0, Value: 158}, {Index: 233 << 1, Value: 0}, {Index: 234 << 1, Value: 0}, {Index: 235 << 1, Value: 0}, {Index: 236 << 1, Value: 0}, {Index: 237 << 1, Value: 0}, {Index: 0, Value: 230}, {Index: 0, Value: 156}, {Index: 238 << 1, Value: 0}, {Index: 239 << 1, Value: 0}, {Index: 0, Value: 78}, {Index: 240 << 1, Value: 0}, {Index: 0, Value: 200}, {Index: 0, Value: 62}, {Index: 0, Value: 109}, {Index: 241 << 1, Value: 0}, {Index: 242 << 1, Value: 0}, {Index: 0, Value: 225}, {Index: 0, Value: 212}, {Index: 243 << 1, Value: 0}, {Index: 0, Value: 123}, {Index: 244 << 1, Value: 0}, {Index: 0, Value: 14}, {Index: 0, Value: 224}, {Index: 0, Value: 93}, {Index: 0, Value: 213}, {Index: 0, Value: 124}, {Index: 0, Value: 199}, {Index: 0, Value: 77}, {Index: 0, Value: 139}, {Index: 0, Value: 154}, {Index: 0, Value: 108}, {Index: 0, Value: 198}, {Index: 0, Value: 61}, {Index: 0, Value: 92}, {Index: 0, Value: 197}, {Index: 0, Value: 138}, {Index: 0, Value: 168}, {Index: 0, Value: 153}, {Index: 0, Value: 76}, {Index: 0, Value: 182}, {Index: 0, Value: 122}, {Index: 0, Value: 91}, {Index: 0, Value: 137}, {Index: 0, Value: 152}, {Index: 0, Value: 121}, {Index: 0, Value: 151}, {Index: 0, Value: 136}, {Index: 245 << 1, Value: 0}, {Index: 246 << 1, Value: 0}, {Index: 0, Value: 238}, {Index: 247 << 1, Value: 0}, {Index: 0, Value: 190}, {Index: 0, Value: 205}, {Index: 248 << 1, Value: 0}, {Index: 0, Value: 174}, {Index: 0, Value: 204}, {Index: 249 << 1, Value: 0}, {Index: 250 << 1, Value: 0}, {Index: 0, Value: 202}, {Index: 251 << 1, Value: 0}, {Index: 0, Value: 94}, {Index: 0, Value: 188}, {Index: 0, Value: 203}, {Index: 0, Value: 142}, {Index: 0, Value: 232}, {Index: 0, Value: 157}, {Index: 0, Value: 231}, {Index: 0, Value: 187}, {Index: 0, Value: 141}, {Index: 0, Value: 216}, {Index: 0, Value: 110}, {Index: 0, Value: 171}, {Index: 0, Value: 186}, {Index: 0, Value: 229}, {Index: 0, Value: 215}, {Index: 0, Value: 228}, {Index: 0, Value: 140}, {Index: 0, Value: 214}, {Index: 0, Value: 155}, {Index: 0, Value: 185}, {Index: 0, Value: 170}, {Index: 0, Value: 184}, {Index: 0, Value: 169}, {Index: 0, Value: 183}, {Index: 0, Value: 208}, {Index: 252 << 1, Value: 0}, {Index: 0, Value: 222}, {Index: 0, Value: 233}, {Index: 253 << 1, Value: 0}, {Index: 0, Value: 237}, {Index: 0, Value: 235}, {Index: 0, Value: 220}, {Index: 0, Value: 219}, {Index: 0, Value: 173}, {Index: 0, Value: 218}, {Index: 0, Value: 126}, {Index: 0, Value: 172}, {Index: 0, Value: 201}, {Index: 0, Value: 125}, {Index: 0, Value: 206}, {Index: 254 << 1, Value: 0}, {Index: 0, Value: 234}, {Index: 0, Value: 217}, {Index: 0, Value: 236}, {Index: 0, Value: 221}}
+var plm_audio_huffman_table_24 [510]plm_vlc_t = [510]plm_vlc_t{{Index: 1 << 1, Value: 0}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 15 << 1, Value: 0}, {Index: 16 << 1, Value: 0}, {Index: 17 << 1, Value: 0}, {Index: 0, Value: 255}, {Index: 18 << 1, Value: 0}, {Index: 19 << 1, Value: 0}, {Index: 20 << 1, Value: 0}, {Index: 21 << 1, Value: 0}, {Index: 22 << 1, Value: 0}, {Index: 23 << 1, Value: 0}, {Index: 24 << 1, Value: 0}, {Index: 25 << 1, Value: 0}, {Index: 0, Value: 17}, {Index: 0, Value: 1}, {Index: 0, Value: 16}, {}, {Index: 26 << 1, Value: 0}, {Index: 27 << 1, Value: 0}, {Index: 28 << 1, Value: 0}, {Index: 29 << 1, Value: 0}, {Index: 30 << 1, Value: 0}, {Index: 31 << 1, Value: 0}, {Index: 32 << 1, Value: 0}, {Index: 33 << 1, Value: 0}, {Index: 34 << 1, Value: 0}, {Index: 35 << 1, Value: 0}, {Index: 36 << 1, Value: 0}, {Index: 37 << 1, Value: 0}, {Index: 38 << 1, Value: 0}, {Index: 39 << 1, Value: 0}, {Index: 40 << 1, Value: 0}, {Index: 41 << 1, Value: 0}, {Index: 42 << 1, Value: 0}, {Index: 43 << 1, Value: 0}, {Index: 44 << 1, Value: 0}, {Index: 0, Value: 18}, {Index: 0, Value: 33}, {Index: 45 << 1, Value: 0}, {Index: 46 << 1, Value: 0}, {Index: 47 << 1, Value: 0}, {Index: 48 << 1, Value: 0}, {Index: 49 << 1, Value: 0}, {Index: 50 << 1, Value: 0}, {Index: 51 << 1, Value: 0}, {Index: 52 << 1, Value: 0}, {Index: 53 << 1, Value: 0}, {Index: 54 << 1, Value: 0}, {Index: 55 << 1, Value: 0}, {Index: 56 << 1, Value: 0}, {Index: 57 << 1, Value: 0}, {Index: 58 << 1, Value: 0}, {Index: 59 << 1, Value: 0}, {Index: 60 << 1, Value: 0}, {Index: 61 << 1, Value: 0}, {Index: 62 << 1, Value: 0}, {Index: 63 << 1, Value: 0}, {Index: 64 << 1, Value: 0}, {Index: 65 << 1, Value: 0}, {Index: 66 << 1, Value: 0}, {Index: 67 << 1, Value: 0}, {Index: 68 << 1, Value: 0}, {Index: 69 << 1, Value: 0}, {Index: 70 << 1, Value: 0}, {Index: 71 << 1, Value: 0}, {Index: 72 << 1, Value: 0}, {Index: 73 << 1, Value: 0}, {Index: 74 << 1, Value: 0}, {Index: 75 << 1, Value: 0}, {Index: 76 << 1, Value: 0}, {Index: 77 << 1, Value: 0}, {Index: 78 << 1, Value: 0}, {Index: 79 << 1, Value: 0}, {Index: 0, Value: 19}, {Index: 0, Value: 49}, {Index: 80 << 1, Value: 0}, {Index: 0, Value: 34}, {Index: 0, Value: 2}, {Index: 0, Value: 32}, {Index: 81 << 1, Value: 0}, {Index: 82 << 1, Value: 0}, {Index: 83 << 1, Value: 0}, {Index: 84 << 1, Value: 0}, {Index: 0, Value: 250}, {Index: 85 << 1, Value: 0}, {Index: 0, Value: 249}, {Index: 0, Value: 248}, {Index: 86 << 1, Value: 0}, {Index: 0, Value: 247}, {Index: 0, Value: 111}, {Index: 0, Value: 246}, {Index: 0, Value: 95}, {Index: 0, Value: 245}, {Index: 0, Value: 79}, {Index: 0, Value: 244}, {Index: 0, Value: 63}, {Index: 0, Value: 243}, {Index: 0, Value: 47}, {Index: 0, Value: 242}, {Index: 0, Value: 241}, {Index: 87 << 1, Value: 0}, {Index: 88 << 1, Value: 0}, {Index: 89 << 1, Value: 0}, {Index: 90 << 1, Value: 0}, {Index: 91 << 1, Value: 0}, {Index: 92 << 1, Value: 0}, {Index: 93 << 1, Value: 0}, {Index: 94 << 1, Value: 0}, {Index: 95 << 1, Value: 0}, {Index: 96 << 1, Value: 0}, {Index: 97 << 1, Value: 0}, {Index: 98 << 1, Value: 0}, {Index: 99 << 1, Value: 0}, {Index: 100 << 1, Value: 0}, {Index: 101 << 1, Value: 0}, {Index: 102 << 1, Value: 0}, {Index: 103 << 1, Value: 0}, {Index: 104 << 1, Value: 0}, {Index: 105 << 1, Value: 0}, {Index: 106 << 1, Value: 0}, {Index: 107 << 1, Value: 0}, {Index: 108 << 1, Value: 0}, {Index: 109 << 1, Value: 0}, {Index: 110 << 1, Value: 0}, {Index: 111 << 1, Value: 0}, {Index: 112 << 1, Value: 0}, {Index: 113 << 1, Value: 0}, {Index: 114 << 1, Value: 0}, {Index: 115 << 1, Value: 0}, {Index: 116 << 1, Value: 0}, {Index: 117 << 1, Value: 0}, {Index: 118 << 1, Value: 0}, {Index: 119 << 1, Value: 0}, {Index: 120 << 1, Value: 0}, {Index: 121 << 1, Value: 0}, {Index: 122 << 1, Value: 0}, {Index: 123 << 1, Value: 0}, {Index: 0, Value: 81}, {Index: 124 << 1, Value: 0}, {Index: 0, Value: 36}, {Index: 0, Value: 66}, {Index: 0, Value: 51}, {Index: 0, Value: 20}, {Index: 0, Value: 65}, {Index: 125 << 1, Value: 0}, {Index: 0, Value: 35}, {Index: 0, Value: 50}, {Index: 0, Value: 3}, {Index: 0, Value: 48}, {Index: 0, Value: 239}, {Index: 0, Value: 254}, {Index: 0, Value: 223}, {Index: 0, Value: 253}, {Index: 0, Value: 207}, {Index: 0, Value: 252}, {Index: 0, Value: 191}, {Index: 0, Value: 251}, {Index: 0, Value: 175}, {Index: 0, Value: 159}, {Index: 0, Value: 143}, {Index: 0, Value: 127}, {Index: 0, Value: 31}, {Index: 0, Value: 240}, {Index: 126 << 1, Value: 0}, {Index: 127 << 1, Value: 0}, {Index: 128 << 1, Value: 0}, {Index: 129 << 1, Value: 0}, {Index: 130 << 1, Value: 0}, {Index: 131 << 1, Value: 0}, {Index: 132 << 1, Value: 0}, {Index: 133 << 1, Value: 0}, {Index: 134 << 1, Value: 0}, {Index: 135 << 1, Value: 0}, {Index: 136 << 1, Value: 0}, {Index: 137 << 1, Value: 0}, {Index: 138 << 1, Value: 0}, {Index: 139 << 1, Value: 0}, {Index: 140 << 1, Value: 0}, {Index: 141 << 1, Value: 0}, {Index: 142 << 1, Value: 0}, {Index: 143 << 1, Value: 0}, {Index: 144 << 1, Value: 0}, {Index: 145 << 1, Value: 0}, {Index: 146 << 1, Value: 0}, {Index: 147 << 1, Value: 0}, {Index: 148 << 1, Value: 0}, {Index: 149 << 1, Value: 0}, {Index: 150 << 1, Value: 0}, {Index: 151 << 1, Value: 0}, {Index: 152 << 1, Value: 0}, {Index: 153 << 1, Value: 0}, {Index: 154 << 1, Value: 0}, {Index: 155 << 1, Value: 0}, {Index: 156 << 1, Value: 0}, {Index: 157 << 1, Value: 0}, {Index: 158 << 1, Value: 0}, {Index: 159 << 1, Value: 0}, {Index: 160 << 1, Value: 0}, {Index: 161 << 1, Value: 0}, {Index: 162 << 1, Value: 0}, {Index: 163 << 1, Value: 0}, {Index: 164 << 1, Value: 0}, {Index: 165 << 1, Value: 0}, {Index: 166 << 1, Value: 0}, {Index: 167 << 1, Value: 0}, {Index: 168 << 1, Value: 0}, {Index: 169 << 1, Value: 0}, {Index: 170 << 1, Value: 0}, {Index: 171 << 1, Value: 0}, {Index: 172 << 1, Value: 0}, {Index: 173 << 1, Value: 0}, {Index: 174 << 1, Value: 0}, {Index: 0, Value: 115}, {Index: 175 << 1, Value: 0}, {Index: 0, Value: 114}, {Index: 0, Value: 70}, {Index: 0, Value: 100}, {Index: 0, Value: 85}, {Index: 0, Value: 113}, {Index: 0, Value: 54}, {Index: 0, Value: 99}, {Index: 0, Value: 69}, {Index: 0, Value: 84}, {Index: 0, Value: 38}, {Index: 0, Value: 98}, {Index: 0, Value: 22}, {Index: 0, Value: 97}, {Index: 176 << 1, Value: 0}, {Index: 0, Value: 53}, {Index: 0, Value: 83}, {Index: 0, Value: 68}, {Index: 0, Value: 37}, {Index: 0, Value: 82}, {Index: 0, Value: 21}, {Index: 177 << 1, Value: 0}, {Index: 0, Value: 52}, {Index: 0, Value: 67}, {Index: 0, Value: 4}, {Index: 0, Value: 64}, {Index: 0, Value: 15}, {Index: 178 << 1, Value: 0}, {Index: 179 << 1, Value: 0}, {Index: 180 << 1, Value: 0}, {Index: 181 << 1, Value: 0}, {Index: 182 << 1, Value: 0}, {Index: 183 << 1, Value: 0}, {Index: 184 << 1, Value: 0}, {Index: 185 << 1, Value: 0}, {Index: 186 << 1, Value: 0}, {Index: 187 << 1, Value: 0}, {Index: 188 << 1, Value: 0}, {Index: 189 << 1, Value: 0}, {Index: 190 << 1, Value: 0}, {Index: 191 << 1, Value: 0}, {Index: 192 << 1, Value: 0}, {Index: 193 << 1, Value: 0}, {Index: 194 << 1, Value: 0}, {Index: 195 << 1, Value: 0}, {Index: 196 << 1, Value: 0}, {Index: 197 << 1, Value: 0}, {Index: 198 << 1, Value: 0}, {Index: 199 << 1, Value: 0}, {Index: 200 << 1, Value: 0}, {Index: 201 << 1, Value: 0}, {Index: 202 << 1, Value: 0}, {Index: 203 << 1, Value: 0}, {Index: 204 << 1, Value: 0}, {Index: 205 << 1, Value: 0}, {Index: 206 << 1, Value: 0}, {Index: 207 << 1, Value: 0}, {Index: 208 << 1, Value: 0}, {Index: 209 << 1, Value: 0}, {Index: 210 << 1, Value: 0}, {Index: 211 << 1, Value: 0}, {Index: 212 << 1, Value: 0}, {Index: 213 << 1, Value: 0}, {Index: 214 << 1, Value: 0}, {Index: 215 << 1, Value: 0}, {Index: 216 << 1, Value: 0}, {Index: 217 << 1, Value: 0}, {Index: 218 << 1, Value: 0}, {Index: 219 << 1, Value: 0}, {Index: 220 << 1, Value: 0}, {Index: 0, Value: 180}, {Index: 221 << 1, Value: 0}, {Index: 222 << 1, Value: 0}, {Index: 223 << 1, Value: 0}, {Index: 0, Value: 179}, {Index: 0, Value: 136}, {Index: 224 << 1, Value: 0}, {Index: 0, Value: 178}, {Index: 225 << 1, Value: 0}, {Index: 226 << 1, Value: 0}, {Index: 0, Value: 150}, {Index: 0, Value: 164}, {Index: 227 << 1, Value: 0}, {Index: 0, Value: 135}, {Index: 0, Value: 58}, {Index: 0, Value: 163}, {Index: 0, Value: 89}, {Index: 0, Value: 149}, {Index: 0, Value: 42}, {Index: 0, Value: 162}, {Index: 0, Value: 161}, {Index: 0, Value: 104}, {Index: 0, Value: 134}, {Index: 0, Value: 119}, {Index: 0, Value: 73}, {Index: 0, Value: 148}, {Index: 0, Value: 57}, {Index: 0, Value: 147}, {Index: 0, Value: 88}, {Index: 0, Value: 133}, {Index: 0, Value: 41}, {Index: 0, Value: 103}, {Index: 0, Value: 118}, {Index: 0, Value: 146}, {Index: 0, Value: 25}, {Index: 0, Value: 145}, {Index: 0, Value: 72}, {Index: 0, Value: 132}, {Index: 0, Value: 87}, {Index: 0, Value: 117}, {Index: 0, Value: 56}, {Index: 0, Value: 131}, {Index: 0, Value: 102}, {Index: 0, Value: 40}, {Index: 0, Value: 130}, {Index: 0, Value: 24}, {Index: 0, Value: 71}, {Index: 0, Value: 116}, {Index: 0, Value: 129}, {Index: 228 << 1, Value: 0}, {Index: 0, Value: 86}, {Index: 0, Value: 101}, {Index: 0, Value: 23}, {Index: 229 << 1, Value: 0}, {Index: 0, Value: 55}, {Index: 0, Value: 39}, {Index: 0, Value: 6}, {Index: 0, Value: 96}, {Index: 0, Value: 5}, {Index: 0, Value: 80}, {Index: 230 << 1, Value: 0}, {Index: 231 << 1, Value: 0}, {Index: 232 << 1, Value: 0}, {Index: 233 << 1, Value: 0}, {Index: 234 << 1, Value: 0}, {Index: 235 << 1, Value: 0}, {Index: 236 << 1, Value: 0}, {Index: 237 << 1, Value: 0}, {Index: 238 << 1, Value: 0}, {Index: 239 << 1, Value: 0}, {Index: 240 << 1, Value: 0}, {Index: 241 << 1, Value: 0}, {Index: 242 << 1, Value: 0}, {Index: 243 << 1, Value: 0}, {Index: 244 << 1, Value: 0}, {Index: 245 << 1, Value: 0}, {Index: 246 << 1, Value: 0}, {Index: 0, Value: 230}, {Index: 247 << 1, Value: 0}, {Index: 0, Value: 201}, {Index: 0, Value: 94}, {Index: 0, Value: 186}, {Index: 0, Value: 229}, {Index: 248 << 1, Value: 0}, {Index: 0, Value: 215}, {Index: 0, Value: 228}, {Index: 0, Value: 140}, {Index: 0, Value: 200}, {Index: 249 << 1, Value: 0}, {Index: 0, Value: 62}, {Index: 0, Value: 109}, {Index: 0, Value: 214}, {Index: 0, Value: 227}, {Index: 0, Value: 155}, {Index: 0, Value: 185}, {Index: 0, Value: 170}, {Index: 0, Value: 226}, {Index: 0, Value: 30}, {Index: 0, Value: 225}, {Index: 0, Value: 93}, {Index: 0, Value: 213}, {Index: 0, Value: 124}, {Index: 0, Value: 199}, {Index: 0, Value: 77}, {Index: 0, Value: 139}, {Index: 0, Value: 184}, {Index: 0, Value: 212}, {Index: 0, Value: 154}, {Index: 0, Value: 169}, {Index: 0, Value: 108}, {Index: 0, Value: 198}, {Index: 0, Value: 61}, {Index: 0, Value: 211}, {Index: 0, Value: 45}, {Index: 0, Value: 210}, {Index: 0, Value: 29}, {Index: 0, Value: 123}, {Index: 0, Value: 183}, {Index: 0, Value: 209}, {Index: 0, Value: 92}, {Index: 0, Value: 197}, {Index: 0, Value: 138}, {Index: 0, Value: 168}, {Index: 0, Value: 153}, {Index: 0, Value: 76}, {Index: 0, Value: 196}, {Index: 0, Value: 107}, {Index: 0, Value: 182}, {Index: 250 << 1, Value: 0}, {Index: 0, Value: 60}, {Index: 0, Value: 195}, {Index: 0, Value: 122}, {Index: 0, Value: 167}, {Index: 0, Value: 44}, {Index: 0, Value: 194}, {Index: 0, Value: 91}, {Index: 0, Value: 181}, {Index: 0, Value: 28}, {Index: 0, Value: 137}, {Index: 0, Value: 152}, {Index: 0, Value: 193}, {Index: 0, Value: 75}, {Index: 251 << 1, Value: 0}, {Index: 0, Value: 59}, {Index: 252 << 1, Value: 0}, {Index: 0, Value: 26}, {Index: 0, Value: 106}, {Index: 0, Value: 166}, {Index: 0, Value: 121}, {Index: 0, Value: 151}, {Index: 253 << 1, Value: 0}, {Index: 0, Value: 144}, {Index: 0, Value: 43}, {Index: 0, Value: 90}, {Index: 0, Value: 165}, {Index: 0, Value: 27}, {Index: 0, Value: 177}, {Index: 0, Value: 105}, {Index: 0, Value: 74}, {Index: 0, Value: 120}, {Index: 0, Value: 8}, {Index: 0, Value: 128}, {Index: 0, Value: 7}, {Index: 0, Value: 112}, {Index: 0, Value: 238}, {Index: 0, Value: 222}, {Index: 0, Value: 237}, {Index: 0, Value: 206}, {Index: 0, Value: 236}, {Index: 0, Value: 221}, {Index: 0, Value: 190}, {Index: 0, Value: 235}, {Index: 0, Value: 205}, {Index: 0, Value: 220}, {Index: 0, Value: 174}, {Index: 0, Value: 234}, {Index: 0, Value: 189}, {Index: 0, Value: 219}, {Index: 0, Value: 204}, {Index: 0, Value: 158}, {Index: 0, Value: 233}, {Index: 0, Value: 173}, {Index: 0, Value: 218}, {Index: 0, Value: 188}, {Index: 0, Value: 203}, {Index: 0, Value: 142}, {Index: 0, Value: 232}, {Index: 0, Value: 157}, {Index: 0, Value: 217}, {Index: 0, Value: 126}, {Index: 0, Value: 231}, {Index: 0, Value: 172}, {Index: 0, Value: 202}, {Index: 0, Value: 187}, {Index: 0, Value: 141}, {Index: 0, Value: 216}, {Index: 254 << 1, Value: 0}, {Index: 0, Value: 13}, {Index: 0, Value: 110}, {Index: 0, Value: 156}, {Index: 0, Value: 171}, {Index: 0, Value: 125}, {Index: 0, Value: 78}, {Index: 0, Value: 46}, {Index: 0, Value: 208}, {Index: 0, Value: 12}, {Index: 0, Value: 192}, {Index: 0, Value: 11}, {Index: 0, Value: 176}, {Index: 0, Value: 10}, {Index: 0, Value: 160}, {Index: 0, Value: 9}, {Index: 0, Value: 14}, {Index: 0, Value: 224}}
+
+// plm_audio_huffman_quad is table A of the quadruples, which holds four values
+// of 0 or 1 as v<<3 | w<<2 | x<<1 | y. Table B codes them in 4 bits instead.
+var plm_audio_huffman_quad [30]plm_vlc_t = [30]plm_vlc_t{{Index: 1 << 1, Value: 0}, {}, {Index: 2 << 1, Value: 0}, {Index: 3 << 1, Value: 0}, {Index: 4 << 1, Value: 0}, {Index: 5 << 1, Value: 0}, {Index: 6 << 1, Value: 0}, {Index: 7 << 1, Value: 0}, {Index: 8 << 1, Value: 0}, {Index: 9 << 1, Value: 0}, {Index: 10 << 1, Value: 0}, {Index: 11 << 1, Value: 0}, {Index: 0, Value: 2}, {Index: 0, Value: 1}, {Index: 0, Value: 4}, {Index: 0, Value: 8}, {Index: 12 << 1, Value: 0}, {Index: 13 << 1, Value: 0}, {Index: 14 << 1, Value: 0}, {Index: 0, Value: 9}, {Index: 0, Value: 6}, {Index: 0, Value: 3}, {Index: 0, Value: 10}, {Index: 0, Value: 12}, {Index: 0, Value: 11}, {Index: 0, Value: 15}, {Index: 0, Value: 13}, {Index: 0, Value: 14}, {Index: 0, Value: 7}, {Index: 0, Value: 5}}
+var plm_audio_huffman_tables [32]*plm_vlc_t = [32]*plm_vlc_t{nil, &plm_audio_huffman_table_1[0], &plm_audio_huffman_table_2[0], &plm_audio_huffman_table_3[0], nil, &plm_audio_huffman_table_5[0], &plm_audio_huffman_table_6[0], &plm_audio_huffman_table_7[0], &plm_audio_huffman_table_8[0], &plm_audio_huffman_table_9[0], &plm_audio_huffman_table_10[0], &plm_audio_huffman_table_11[0], &plm_audio_huffman_table_12[0], &plm_audio_huffman_table_13[0], nil, &plm_audio_huffman_table_15[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_16[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0], &plm_audio_huffman_table_24[0]}
+var plm_audio_huffman_linbits [32]uint8 = [32]uint8{16: 1, 17: 2, 18: 3, 19: 4, 20: 6, 21: 8, 22: 10, 23: 13, 24: 4, 25: 5, 26: 6, 27: 7, 28: 8, 29: 9, 30: 11, 31: 13}
+
+// plm_audio_layer3_antialias holds the butterflies between subbands of long
+// blocks, as their cosine and sine.
+var plm_audio_layer3_antialias [2][8]float32 = [2][8]float32{{0.857492926, 0.881741997, 0.949628649, 0.983314592, 0.995517816, 0.999160558, 0.999899195, 0.999993155}, {-0.514495755, -0.471731969, -0.313377454, -0.1819132, -0.0945741925, -0.0409655829, -0.0141985686, -0.00369997467}}
+
+// plm_audio_layer3_window holds the windows of the IMDCT by block type. Short
+// blocks use the first 12 values.
+var plm_audio_layer3_window [4][36]float32 = [4][36]float32{{0.0436193874, 0.130526192, 0.216439614, 0.3007058, 0.382683432, 0.461748613, 0.537299608, 0.608761429, 0.675590208, 0.737277337, 0.79335334, 0.843391446, 0.887010833, 0.923879533, 0.953716951, 0.976296007, 0.991444861, 0.999048222, 0.999048222, 0.991444861, 0.976296007, 0.953716951, 0.923879533, 0.887010833, 0.843391446, 0.79335334, 0.737277337, 0.675590208, 0.608761429, 0.537299608, 0.461748613, 0.382683432, 0.3007058, 0.216439614, 0.130526192, 0.0436193874}, {0.0436193874, 0.130526192, 0.216439614, 0.3007058, 0.382683432, 0.461748613, 0.537299608, 0.608761429, 0.675590208, 0.737277337, 0.79335334, 0.843391446, 0.887010833, 0.923879533, 0.953716951, 0.976296007, 0.991444861, 0.999048222, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 0.991444861, 0.923879533, 0.79335334, 0.608761429, 0.382683432, 0.130526192, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0}, {0.130526192, 0.382683432, 0.608761429, 0.79335334, 0.923879533, 0.991444861, 0.991444861, 0.923879533, 0.79335334, 0.608761429, 0.382683432, 0.130526192, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0}, {0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.130526192, 0.382683432, 0.608761429, 0.79335334, 0.923879533, 0.991444861, 1.0, 1.0, 1.0, 1.0, 1.0, 1.0, 0.999048222, 0.991444861, 0.976296007, 0.953716951, 0.923879533, 0.887010833, 0.843391446, 0.79335334, 0.737277337, 0.675590208, 0.608761429, 0.537299608, 0.461748613, 0.382683432, 0.3007058, 0.216439614, 0.130526192, 0.0436193874}}
+var plm_audio_layer3_imdct_long [36][18]float32 = [36][18]float32{{0.675590208, -0.79335334, -0.537299608, 0.887010833, 0.382683432, -0.953716951, -0.216439614, 0.991444861, 0.0436193874, -0.999048222, 0.130526192, 0.976296007, -0.3007058, -0.923879533, 0.461748613, 0.843391446, -0.608761429, -0.737277337}, {0.608761429, -0.923879533, -0.130526192, 0.991444861, -0.382683432, -0.79335334, 0.79335334, 0.382683432, -0.991444861, 0.130526192, 0.923879533, -0.608761429, -0.608761429, 0.923879533, 0.130526192, -0.991444861, 0.382683432, 0.79335334}, {0.537299608, -0.991444861, 0.3007058, 0.737277337, -0.923879533, 0.0436193874, 0.887010833, -0.79335334, -0.216439614, 0.976296007, -0.608761429, -0.461748613, 0.999048222, -0.382683432, -0.675590208, 0.953716951, -0.130526192, -0.843391446}, {0.461748613, -0.991444861, 0.675590208, 0.216439614, -0.923879533, 0.843391446, -0.0436193874, -0.79335334, 0.953716951, -0.3007058, -0.608761429, 0.999048222, -0.537299608, -0.382683432, 0.976296007, -0.737277337, -0.130526192, 0.887010833}, {0.382683432, -0.923879533, 0.923879533, -0.382683432, -0.382683432, 0.923879533, -0.923879533, 0.382683432, 0.382683432, -0.923879533, 0.923879533, -0.382683432, -0.382683432, 0.923879533, -0.923879533, 0.382683432, 0.382683432, -0.923879533}, {0.3007058, -0.79335334, 0.999048222, -0.843391446, 0.382683432, 0.216439614, -0.737277337, 0.991444861, -0.887010833, 0.461748613, 0.130526192, -0.675590208, 0.976296007, -0.923879533, 0.537299608, 0.0436193874, -0.608761429, 0.953716951}, {0.216439614, -0.608761429, 0.887010833, -0.999048222, 0.923879533, -0.675590208, 0.3007058, 0.130526192, -0.537299608, 0.843391446, -0.991444861, 0.953716951, -0.737277337, 0.382683432, 0.0436193874, -0.461748613, 0.79335334, -0.976296007}, {0.130526192, -0.382683432, 0.608761429, -0.79335334, 0.923879533, -0.991444861, 0.991444861, -0.923879533, 0.79335334, -0.608761429, 0.382683432, -0.130526192, -0.130526192, 0.382683432, -0.608761429, 0.79335334, -0.923879533, 0.991444861}, {0.0436193874, -0.130526192, 0.216439614, -0.3007058, 0.382683432, -0.461748613, 0.537299608, -0.608761429, 0.675590208, -0.737277337, 0.79335334, -0.843391446, 0.887010833, -0.923879533, 0.953716951, -0.976296007, 0.991444861, -0.999048222}, {-0.0436193874, 0.130526192, -0.216439614, 0.3007058, -0.382683432, 0.461748613, -0.537299608, 0.608761429, -0.675590208, 0.737277337, -0.79335334, 0.843391446, -0.887010833, 0.923879533, -0.953716951, 0.976296007, -0.991444861, 0.999048222}, {-0.130526192, 0.382683432, -0.608761429, 0.79335334, -0.923879533, 0.991444861, -0.991444861, 0.923879533, -0.79335334, 0.608761429, -0.382683432, 0.130526192, 0.130526192, -0.382683432, 0.608761429, -0.79335334, 0.923879533, -0.991444861}, {-0.216439614, 0.608761429, -0.887010833, 0.999048222, -0.923879533, 0.675590208, -0.3007058, -0.130526192, 0.537299608, -0.843391446, 0.991444861, -0.953716951, 0.737277337, -0.382683432, -0.0436193874, 0.461748613, -0.79335334, 0.976296007}, {-0.3007058, 0.79335334, -0.999048222, 0.843391446, -0.382683432, -0.216439614, 0.737277337, -0.991444861, 0.887010833, -0.461748613, -0.130526192, 0.675590208, -0.976296007, 0.923879533, -0.537299608, -0.0436193874, 0.608761429, -0.953716951}, {-0.382683432, 0.923879533, -0.923879533, 0.382683432, 0.382683432, -0.923879533, 0.923879533, -0.382683432, -0.382683432, 0.923879533, -0.923879533, 0.382683432, 0.382683432, -0.923879533, 0.923879533, -0.382683432, -0.382683432, 0.923879533}, {-0.461748613, 0.991444861, -0.675590208, -0.216439614, 0.923879533, -0.843391446, 0.0436193874, 0.79335334, -0.953716951, 0.3007058, 0.608761429, -0.999048222, 0.537299608, 0.382683432, -0.976296007, 0.737277337, 0.130526192, -0.887010833}, {-0.537299608, 0.991444861, -0.3007058, -0.737277337, 0.923879533, -0.0436193874, -0.887010833, 0.79335334, 0.216439614, -0.976296007, 0.608761429, 0.461748613, -0.999048222, 0.382683432, 0.675590208, -0.953716951, 0.130526192, 0.843391446}, {-0.608761429, 0.923879533, 0.130526192, -0.991444861, 0.382683432, 0.79335334, -0.79335334, -0.382683432, 0.991444861, -0.130526192, -0.923879533, 0.608761429, 0.608761429, -0.923879533, -0.130526192, 0.991444861, -0.382683432, -0.79335334}, {-0.675590208, 0.79335334, 0.537299608, -0.887010833, -0.382683432, 0.953716951, 0.216439614, -0.991444861, -0.0436193874, 0.999048222, -0.130526192, -0.976296007, 0.3007058, 0.923879533, -0.461748613, -0.843391446, 0.608761429, 0.737277337}, {-0.737277337, 0.608761429, 0.843391446, -0.461748613, -0.923879533, 0.3007058, 0.976296007, -0.130526192, -0.999048222, -0.0436193874, 0.991444861, 0.216439614, -0.953716951, -0.382683432, 0.887010833, 0.537299608, -0.79335334, -0.675590208}, {-0.79335334, 0.382683432, 0.991444861, 0.130526192, -0.923879533, -0.608761429, 0.608761429, 0.923879533, -0.130526192, -0.991444861, -0.382683432, 0.79335334, 0.79335334, -0.382683432, -0.991444861, -0.130526192, 0.923879533, 0.608761429}, {-0.843391446, 0.130526192, 0.953716951, 0.675590208, -0.382683432, -0.999048222, -0.461748613, 0.608761429, 0.976296007, 0.216439614, -0.79335334, -0.887010833, 0.0436193874, 0.923879533, 0.737277337, -0.3007058, -0.991444861, -0.537299608}, {-0.887010833, -0.130526192, 0.737277337, 0.976296007, 0.382683432, -0.537299608, -0.999048222, -0.608761429, 0.3007058, 0.953716951, 0.79335334, -0.0436193874, -0.843391446, -0.923879533, -0.216439614, 0.675590208, 0.991444861, 0.461748613}, {-0.923879533, -0.382683432, 0.382683432, 0.923879533, 0.923879533, 0.382683432, -0.382683432, -0.923879533, -0.923879533, -0.382683432, 0.382683432, 0.923879533, 0.923879533, 0.382683432, -0.382683432, -0.923879533, -0.923879533, -0.382683432}, {-0.953716951, -0.608761429, -0.0436193874, 0.537299608, 0.923879533, 0.976296007, 0.675590208, 0.130526192, -0.461748613, -0.887010833, -0.991444861, -0.737277337, -0.216439614, 0.382683432, 0.843391446, 0.999048222, 0.79335334, 0.3007058}, {-0.976296007, -0.79335334, -0.461748613, -0.0436193874, 0.382683432, 0.737277337, 0.953716951, 0.991444861, 0.843391446, 0.537299608, 0.130526192, -0.3007058, -0.675590208, -0.923879533, -0.999048222, -0.887010833, -0.608761429, -0.216439614}, {-0.991444861, -0.923879533, -0.79335334, -0.608761429, -0.382683432, -0.130526192, 0.130526192, 0.382683432, 0.608761429, 0.79335334, 0.923879533, 0.991444861, 0.991444861, 0.923879533, 0.79335334, 0.608761429, 0.382683432, 0.130526192}, {-0.999048222, -0.991444861, -0.976296007, -0.953716951, -0.923879533, -0.887010833, -0.843391446, -0.79335334, -0.737277337, -0.675590208, -0.608761429, -0.537299608, -0.461748613, -0.382683432, -0.3007058, -0.216439614, -0.130526192, -0.0436193874}, {-0.999048222, -0.991444861, -0.976296007, -0.953716951, -0.923879533, -0.887010833, -0.843391446, -0.79335334, -0.737277337, -0.675590208, -0.608761429, -0.537299608, -0.461748613, -0.382683432, -0.3007058, -0.216439614, -0.130526192, -0.0436193874}, {-0.991444861, -0.923879533, -0.79335334, -0.608761429, -0.382683432, -0.130526192, 0.130526192, 0.382683432, 0.608761429, 0.79335334, 0.923879533, 0.991444861, 0.991444861, 0.923879533, 0.79335334, 0.608761429, 0.382683432, 0.130526192}, {-0.976296007, -0.79335334, -0.461748613, -0.0436193874, 0.382683432, 0.737277337, 0.953716951, 0.991444861, 0.843391446, 0.537299608, 0.130526192, -0.3007058, -0.675590208, -0.923879533, -0.999048222, -0.887010833, -0.608761429, -0.216439614}, {-0.953716951, -0.608761429, -0.0436193874, 0.537299608, 0.923879533, 0.976296007, 0.675590208, 0.130526192, -0.461748613, -0.887010833, -0.991444861, -0.737277337, -0.216439614, 0.382683432, 0.843391446, 0.999048222, 0.79335334, 0.3007058}, {-0.923879533, -0.382683432, 0.382683432, 0.923879533, 0.923879533, 0.382683432, -0.382683432, -0.923879533, -0.923879533, -0.382683432, 0.382683432, 0.923879533, 0.923879533, 0.382683432, -0.382683432, -0.923879533, -0.923879533, -0.382683432}, {-0.887010833, -0.130526192, 0.737277337, 0.976296007, 0.382683432, -0.537299608, -0.999048222, -0.608761429, 0.3007058, 0.953716951, 0.79335334, -0.0436193874, -0.843391446, -0.923879533, -0.216439614, 0.675590208, 0.991444861, 0.461748613}, {-0.843391446, 0.130526192, 0.953716951, 0.675590208, -0.382683432, -0.999048222, -0.461748613, 0.608761429, 0.976296007, 0.216439614, -0.79335334, -0.887010833, 0.0436193874, 0.923879533, 0.737277337, -0.3007058, -0.991444861, -0.537299608}, {-0.79335334, 0.382683432, 0.991444861, 0.130526192, -0.923879533, -0.608761429, 0.608761429, 0.923879533, -0.130526192, -0.991444861, -0.382683432, 0.79335334, 0.79335334, -0.382683432, -0.991444861, -0.130526192, 0.923879533, 0.608761429}, {-0.737277337, 0.608761429, 0.843391446, -0.461748613, -0.923879533, 0.3007058, 0.976296007, -0.130526192, -0.999048222, -0.0436193874, 0.991444861, 0.216439614, -0.953716951, -0.382683432, 0.887010833, 0.537299608, -0.79335334, -0.675590208}}
+var plm_audio_layer3_imdct_short [12][6]float32 = [12][6]float32{{0.608761429, -0.923879533, -0.130526192, 0.991444861, -0.382683432, -0.79335334}, {0.382683432, -0.923879533, 0.923879533, -0.382683432, -0.382683432, 0.923879533}, {0.130526192, -0.382683432, 0.608761429, -0.79335334, 0.923879533, -0.991444861}, {-0.130526192, 0.382683432, -0.608761429, 0.79335334, -0.923879533, 0.991444861}, {-0.382683432, 0.923879533, -0.923879533, 0.382683432, 0.382683432, -0.923879533}, {-0.608761429, 0.923879533, 0.130526192, -0.991444861, 0.382683432, 0.79335334}, {-0.79335334, 0.382683432, 0.991444861, 0.130526192, -0.923879533, -0.608761429}, {-0.923879533, -0.382683432, 0.382683432, 0.923879533, 0.923879533, 0.382683432}, {-0.991444861, -0.923879533, -0.79335334, -0.608761429, -0.382683432, -0.130526192}, {-0.991444861, -0.923879533, -0.79335334, -0.608761429, -0.382683432, -0.130526192}, {-0.923879533, -0.382683432, 0.382683432, 0.923879533, 0.923879533, 0.382683432}, {-0.79335334, 0.382683432, 0.991444861, 0.130526192, -0.923879533, -0.608761429}}
+
+// plm_audio_get_bitrate returns the bitrate of the last frame header in
+// kbit/s.
+func plm_audio_get_bitrate(self *plm_audio_t) int64 {
+	switch self.Layer {
+	case plm_audio_layer_i:
+		return int64(plm_audio_layer_i_bit_rate[self.Bitrate_index])
+	case plm_audio_layer_iii:
+		return int64(plm_audio_layer_iii_bit_rate[self.Bitrate_index])
+	}
+	return int64(plm_audio_bit_rate[self.Bitrate_index])
+}
+
+// plm_audio_get_samples_per_frame returns the number of samples per channel
+// in a frame of the current layer.
+func plm_audio_get_samples_per_frame(self *plm_audio_t) int64 {
+	if self.Layer == plm_audio_layer_i {
+		return 384
+	}
+	return plm_audio_samples_per_frame
+}
+func plm_audio_decode_frame_layer_i(self *plm_audio_t) {
+	for sb := int64(0); sb < self.Bound; sb++ {
+		self.Allocation[0][sb] = plm_audio_read_allocation_layer_i(self)
+		self.Allocation[1][sb] = plm_audio_read_allocation_layer_i(self)
+	}
+	for sb := int64(self.Bound); sb < 32; sb++ {
+		self.Allocation[0][sb] = plm_audio_read_allocation_layer_i(self)
+		self.Allocation[1][sb] = self.Allocation[0][sb]
+	}
+	var channels int64 = 2
+	if self.Mode == plm_audio_mode_mono {
+		channels = 1
+	}
+	for sb := int64(0); sb < 32; sb++ {
+		for ch := int64(0); ch < channels; ch++ {
+			if self.Allocation[ch][sb] != nil {
+				self.Scale_factor[ch][sb][0] = plm_buffer_read(self.Buffer, 6)
+			}
+		}
+		if self.Mode == plm_audio_mode_mono {
+			self.Scale_factor[1][sb][0] = self.Scale_factor[0][sb][0]
+		}
+	}
+	var out_pos int64 = 0
+	for s := int64(0); s < 12; s++ {
+		var p int64 = s % 3
+		for sb := int64(0); sb < 32; sb++ {
+			if sb < self.Bound {
+				self.Sample[0][sb][p] = plm_audio_read_sample_layer_i(self, 0, sb, plm_buffer_read(self.Buffer, plm_audio_get_sample_bits(self.Allocation[0][sb])))
+				self.Sample[1][sb][p] = plm_audio_read_sample_layer_i(self, 1, sb, plm_buffer_read(self.Buffer, plm_audio_get_sample_bits(self.Allocation[1][sb])))
+				continue
+			}
+			// Above the bound both channels share one sample, scaled by the
+			// scale factor of each.
+			var value int64 = plm_buffer_read(self.Buffer, plm_audio_get_sample_bits(self.Allocation[0][sb]))
+			self.Sample[0][sb][p] = plm_audio_read_sample_layer_i(self, 0, sb, value)
+			self.Sample[1][sb][p] = plm_audio_read_sample_layer_i(self, 1, sb, value)
+		}
+		if p == 2 {
+			plm_audio_synthesis(self, out_pos)
+			out_pos += 96
+		}
+	}
+	plm_buffer_align(self.Buffer)
+}
+func plm_audio_read_allocation_layer_i(self *plm_audio_t) *plm_quantizer_spec_t {
+	var allocation int64 = plm_buffer_read(self.Buffer, 4)
+	if allocation == 0 || allocation == 15 {
+		return nil
+	}
+	return &plm_audio_layer_i_quant_tab[allocation-1]
+}
+func plm_audio_get_sample_bits(q *plm_quantizer_spec_t) int64 {
+	if q == nil {
+		return 0
+	}
+	return int64(q.Bits)
+}
+
+// plm_audio_read_sample_layer_i dequantizes "value" into the same fixed point
+// format "plm_audio_read_samples" uses for Layer II. A code of "bits" bits
+// represents one of 2^bits-1 levels spread evenly between -1 and 1.
+func plm_audio_read_sample_layer_i(self *plm_audio_t, ch int64, sb int64, value int64) int64 {
+	var q *plm_quantizer_spec_t = self.Allocation[ch][sb]
+	if q == nil {
+		return 0
+	}
+	var sf int64 = self.Scale_factor[ch][sb][0]
+	if sf == 63 {
+		sf = 0
+	} else {
+		var shift int64 = sf / 3
+		sf = (plm_audio_scalefactor_base[sf%3] + ((1 << shift) >> 1)) >> shift
+	}
+	var levels int64 = int64(q.Levels)
+	var adj int64 = ((levels + 1) >> 1) - 1
+	var val int64 = (adj - value) * 0x10000 / levels
+	return (val*(sf>>12) + ((val*(sf&4095) + 2048) >> 12)) >> 12
+}
+func plm_audio_decode_frame_layer_iii(self *plm_audio_t) {
+	if self.Layer3 == nil {
+		self.Layer3 = new(plm_audio_layer3_t)
+	}
+	var l3 *plm_audio_layer3_t = self.Layer3
+	var channels int64 = 2
+	var side_info_size int64 = 32
+	if self.Mode == plm_audio_mode_mono {
+		channels = 1
+		side_info_size = 17
+	}
+	l3.Main_data_begin = plm_buffer_read(self.Buffer, 9)
+	plm_buffer_skip(self.Buffer, uint64(7-channels*2))
+	for ch := int64(0); ch < channels; ch++ {
+		for band := int64(0); band < 4; band++ {
+			l3.Scfsi[ch][band] = plm_buffer_read(self.Buffer, 1)
+		}
+	}
+	for gr := int64(0); gr < 2; gr++ {
+		for ch := int64(0); ch < channels; ch++ {
+			plm_audio_read_granule_info(self, &l3.Granule[gr][ch])
+		}
+	}
+
+	// The main data of this frame may start in the frames before it. Their
+	// main data is kept in the reservoir, and this frame's is added to it.
+	var available bool = l3.Main_data_begin <= l3.Reservoir_size
+	var start int64 = l3.Reservoir_size - l3.Main_data_begin
+	for i := side_info_size; i < self.Next_frame_data_size; i++ {
+		l3.Reservoir[l3.Reservoir_size] = uint8(plm_buffer_read(self.Buffer, 8))
+		l3.Reservoir_size++
+	}
+	l3.Main_data = plm_buffer_t{}
+	l3.Main_data.Bytes = &l3.Reservoir[0]
+	l3.Main_data.Capacity = uint64(l3.Reservoir_size)
+	l3.Main_data.Length = uint64(l3.Reservoir_size)
+	l3.Main_data.Total_size = uint64(l3.Reservoir_size)
+	l3.Main_data.Mode = plm_buffer_mode(plm_buffer_mode_fixed_mem)
+	if available {
+		l3.Main_data.Bit_index = uint64(start) << 3
+	}
+
+	var out_pos int64 = 0
+	for gr := int64(0); gr < 2; gr++ {
+		for ch := int64(0); ch < channels; ch++ {
+			// After a seek, frames referring to data before it are silent.
+			if !available {
+				l3.Xr[ch] = [576]float32{}
+				l3.Count[ch] = 0
+				continue
+			}
+			var part2_start uint64 = l3.Main_data.Bit_index
+			plm_audio_read_scalefactors(self, gr, ch)
+			plm_audio_read_huffman(self, gr, ch, part2_start)
+			plm_audio_requantize(self, gr, ch)
+		}
+		if self.Mode == plm_audio_mode_joint_stereo {
+			plm_audio_joint_stereo(self, gr)
+		}
+		for ch := int64(0); ch < channels; ch++ {
+			plm_audio_hybrid_synthesis(self, gr, ch)
+		}
+		if channels == 1 {
+			l3.Xr[1] = l3.Xr[0]
+		}
+		// The synthesis filter takes subband samples in the fixed point format
+		// of Layer II, where 65536 is full scale.
+		for t := int64(0); t < 18; t += 3 {
+			for ch := int64(0); ch < 2; ch++ {
+				for sb := int64(0); sb < 32; sb++ {
+					for p := int64(0); p < 3; p++ {
+						self.Sample[ch][sb][p] = int64(math.Round(float64(l3.Xr[ch][sb*18+t+p]) * 65536))
+					}
+				}
+			}
+			plm_audio_synthesis(self, out_pos)
+			out_pos += 96
+		}
+	}
+
+	// Only the last bytes can be referred to by the next frame.
+	if l3.Reservoir_size > plm_audio_layer3_max_reservoir {
+		copy(l3.Reservoir[:], l3.Reservoir[l3.Reservoir_size-plm_audio_layer3_max_reservoir:l3.Reservoir_size])
+		l3.Reservoir_size = plm_audio_layer3_max_reservoir
+	}
+}
+func plm_audio_read_granule_info(self *plm_audio_t, gi *plm_audio_granule_t) {
+	gi.Part2_3_length = plm_buffer_read(self.Buffer, 12)
+	gi.Big_values = plm_buffer_read(self.Buffer, 9)
+	if gi.Big_values > 288 {
+		gi.Big_values = 288
+	}
+	gi.Global_gain = plm_buffer_read(self.Buffer, 8)
+	gi.Scalefac_compress = plm_buffer_read(self.Buffer, 4)
+	gi.Window_switching = plm_buffer_read(self.Buffer, 1)
+	if gi.Window_switching != 0 {
+		gi.Block_type = plm_buffer_read(self.Buffer, 2)
+		gi.Mixed_block = plm_buffer_read(self.Buffer, 1)
+		gi.Table_select[0] = plm_buffer_read(self.Buffer, 5)
+		gi.Table_select[1] = plm_buffer_read(self.Buffer, 5)
+		gi.Table_select[2] = 0
+		for w := int64(0); w < 3; w++ {
+			gi.Subblock_gain[w] = plm_buffer_read(self.Buffer, 3)
+		}
+		// Block type 0 is reserved here, and is decoded as a long block.
+		if gi.Block_type == 2 && gi.Mixed_block == 0 {
+			gi.Region0_count = 8
+		} else {
+			gi.Region0_count = 7
+		}
+		gi.Region1_count = 20 - gi.Region0_count
+	} else {
+		gi.Block_type = 0
+		gi.Mixed_block = 0
+		for i := int64(0); i < 3; i++ {
+			gi.Table_select[i] = plm_buffer_read(self.Buffer, 5)
+		}
+		gi.Subblock_gain = [3]int64{}
+		gi.Region0_count = plm_buffer_read(self.Buffer, 4)
+		gi.Region1_count = plm_buffer_read(self.Buffer, 3)
+	}
+	gi.Preflag = plm_buffer_read(self.Buffer, 1)
+	gi.Scalefac_scale = plm_buffer_read(self.Buffer, 1)
+	gi.Count1table_select = plm_buffer_read(self.Buffer, 1)
+}
+func plm_audio_read_scalefactors(self *plm_audio_t, gr int64, ch int64) {
+	var (
+		l3    *plm_audio_layer3_t  = self.Layer3
+		gi    *plm_audio_granule_t = &l3.Granule[gr][ch]
+		slen1 int64                = int64(plm_audio_layer3_slen[0][gi.Scalefac_compress])
+		slen2 int64                = int64(plm_audio_layer3_slen[1][gi.Scalefac_compress])
+		buf   *plm_buffer_t        = &l3.Main_data
+	)
+	if gi.Block_type == 2 {
+		var sfb int64 = 0
+		if gi.Mixed_block != 0 {
+			for ; sfb < 8; sfb++ {
+				l3.Scalefac_l[ch][sfb] = plm_buffer_read(buf, slen1)
+			}
+			sfb = 3
+		}
+		for ; sfb < 12; sfb++ {
+			var slen int64 = slen1
+			if sfb >= 6 {
+				slen = slen2
+			}
+			for w := int64(0); w < 3; w++ {
+				l3.Scalefac_s[ch][sfb][w] = plm_buffer_read(buf, slen)
+			}
+		}
+		l3.Scalefac_s[ch][12] = [3]int64{}
+		return
+	}
+	// Bands whose scale factors are shared with the first granule are left
+	// as they were.
+	var bands [5]int64 = [5]int64{0, 6, 11, 16, 21}
+	for band := int64(0); band < 4; band++ {
+		if gr != 0 && l3.Scfsi[ch][band] != 0 {
+			continue
+		}
+		var slen int64 = slen1
+		if band >= 2 {
+			slen = slen2
+		}
+		for sfb := bands[band]; sfb < bands[band+1]; sfb++ {
+			l3.Scalefac_l[ch][sfb] = plm_buffer_read(buf, slen)
+		}
+	}
+	l3.Scalefac_l[ch][21] = 0
+}
+func plm_audio_read_huffman(self *plm_audio_t, gr int64, ch int64, part2_start uint64) {
+	var (
+		l3  *plm_audio_layer3_t  = self.Layer3
+		gi  *plm_audio_granule_t = &l3.Granule[gr][ch]
+		buf *plm_buffer_t        = &l3.Main_data
+		end uint64               = part2_start + uint64(gi.Part2_3_length)
+		i   int64                = 0
+	)
+	var region1_start int64 = 36
+	var region2_start int64 = 576
+	if gi.Block_type != 2 {
+		var band *[23]int16 = &plm_audio_layer3_band_long[self.Samplerate_index]
+		region1_start = int64(band[gi.Region0_count+1])
+		if j := gi.Region0_count + gi.Region1_count + 2; j < 23 {
+			region2_start = int64(band[j])
+		}
+	}
+	for ; i < gi.Big_values*2; i += 2 {
+		var table int64 = gi.Table_select[2]
+		if i < region1_start {
+			table = gi.Table_select[0]
+		} else if i < region2_start {
+			table = gi.Table_select[1]
+		}
+		if plm_audio_huffman_tables[table] == nil {
+			l3.Values[i], l3.Values[i+1] = 0, 0
+			continue
+		}
+		var (
+			value   int64 = int64(plm_buffer_read_vlc(buf, plm_audio_huffman_tables[table]))
+			linbits int64 = int64(plm_audio_huffman_linbits[table])
+		)
+		l3.Values[i] = plm_audio_read_huffman_value(buf, value>>4, linbits)
+		l3.Values[i+1] = plm_audio_read_huffman_value(buf, value&15, linbits)
+	}
+	// Values of 0 or 1 follow in groups of four, until the end of the data.
+	for i+4 <= 576 && buf.Bit_index < end {
+		var value int64
+		if gi.Count1table_select != 0 {
+			value = 15 - plm_buffer_read(buf, 4)
+		} else {
+			value = int64(plm_buffer_read_vlc(buf, &plm_audio_huffman_quad[0]))
+		}
+		for j := int64(0); j < 4; j++ {
+			l3.Values[i+j] = plm_audio_read_huffman_value(buf, (value>>(3-j))&1, 0)
+		}
+		i += 4
+	}
+	// The last group may have been read past the end by padding bits.
+	if buf.Bit_index > end && i > gi.Big_values*2 {
+		i -= 4
+	}
+	l3.Count[ch] = i
+	for ; i < 576; i++ {
+		l3.Values[i] = 0
+	}
+	buf.Bit_index = end
+}
+func plm_audio_read_huffman_value(buf *plm_buffer_t, value int64, linbits int64) int64 {
+	if linbits != 0 && value == 15 {
+		value += plm_buffer_read(buf, linbits)
+	}
+	if value != 0 && plm_buffer_read(buf, 1) != 0 {
+		return -value
+	}
+	return value
+}
+func plm_audio_requantize(self *plm_audio_t, gr int64, ch int64) {
+	var (
+		l3    *plm_audio_layer3_t  = self.Layer3
+		gi    *plm_audio_granule_t = &l3.Granule[gr][ch]
+		xr    *[576]float32        = &l3.Xr[ch]
+		gain  float64              = float64(gi.Global_gain-210) / 4
+		shift float64              = 0.5
+		long  *[23]int16           = &plm_audio_layer3_band_long[self.Samplerate_index]
+		short *[14]int16           = &plm_audio_layer3_band_short[self.Samplerate_index]
+		i     int64                = 0
+	)
+	if gi.Scalefac_scale != 0 {
+		shift = 1
+	}
+	var long_bands int64 = 22
+	var short_band int64 = 13
+	if gi.Block_type == 2 {
+		long_bands, short_band = 0, 0
+		if gi.Mixed_block != 0 {
+			long_bands, short_band = 8, 3
+		}
+	}
+	for sfb := int64(0); sfb < long_bands; sfb++ {
+		var exponent float64 = gain - shift*float64(l3.Scalefac_l[ch][sfb]+gi.Preflag*int64(plm_audio_layer3_pretab[sfb]))
+		for ; i < int64(long[sfb+1]); i++ {
+			xr[i] = plm_audio_dequantize(l3.Values[i], exponent)
+		}
+	}
+	for sfb := short_band; sfb < 13; sfb++ {
+		var width int64 = int64(short[sfb+1] - short[sfb])
+		for w := int64(0); w < 3; w++ {
+			var exponent float64 = gain - 2*float64(gi.Subblock_gain[w]) - shift*float64(l3.Scalefac_s[ch][sfb][w])
+			for j := int64(0); j < width; j++ {
+				xr[i] = plm_audio_dequantize(l3.Values[i], exponent)
+				i++
+			}
+		}
+	}
+}
+func plm_audio_dequantize(value int64, exponent float64) float32 {
+	if value == 0 {
+		return 0
+	}
+	var v float64 = math.Abs(float64(value))
+	v = v * math.Cbrt(v) * math.Exp2(exponent)
+	if value < 0 {
+		return float32(-v)
+	}
+	return float32(v)
+}
+
+// plm_audio_joint_stereo turns the channels of a joint stereo granule back
+// into left and right. Where the right channel is zero at the top of the
+// spectrum, intensity stereo codes its bands as a share of the left channel,
+// given by the scale factors of the right channel.
+func plm_audio_joint_stereo(self *plm_audio_t, gr int64) {
+	var (
+		l3        *plm_audio_layer3_t  = self.Layer3
+		gi        *plm_audio_granule_t = &l3.Granule[gr][1]
+		ms        bool                 = self.Mode_extension&2 != 0
+		intensity bool                 = self.Mode_extension&1 != 0
+		long      *[23]int16           = &plm_audio_layer3_band_long[self.Samplerate_index]
+		short     *[14]int16           = &plm_audio_layer3_band_short[self.Samplerate_index]
+	)
+	if gi.Block_type != 2 {
+		// Intensity stereo starts above the last band with right channel
+		// values.
+		var first int64 = 22
+		if intensity {
+			first = 0
+			for sfb := int64(0); sfb < 22; sfb++ {
+				if plm_audio_has_values(&l3.Xr[1], int64(long[sfb]), int64(long[sfb+1])) {
+					first = sfb + 1
+				}
+			}
+		}
+		for sfb := int64(0); sfb < 22; sfb++ {
+			var is_pos int64 = 7
+			if sfb >= first {
+				is_pos = l3.Scalefac_l[1][plm_audio_min(sfb, 20)]
+			}
+			plm_audio_stereo_band(self, int64(long[sfb]), int64(long[sfb+1]), is_pos, ms)
+		}
+		return
+	}
+	// In short blocks, each window starts intensity stereo on its own. The
+	// long bands of mixed blocks use it only if all windows do.
+	var (
+		first      [3]int64 = [3]int64{13, 13, 13}
+		short_band int64    = 0
+		i          int64    = 0
+	)
+	if gi.Mixed_block != 0 {
+		short_band, i = 3, 36
+	}
+	if intensity {
+		var pos int64 = i
+		first = [3]int64{short_band, short_band, short_band}
+		for sfb := short_band; sfb < 13; sfb++ {
+			var width int64 = int64(short[sfb+1] - short[sfb])
+			for w := int64(0); w < 3; w++ {
+				if plm_audio_has_values(&l3.Xr[1], pos, pos+width) {
+					first[w] = sfb + 1
+				}
+				pos += width
+			}
+		}
+	}
+	if gi.Mixed_block != 0 {
+		var mixed_first int64 = 8
+		if intensity && first == [3]int64{3, 3, 3} {
+			mixed_first = 0
+			for sfb := int64(0); sfb < 8; sfb++ {
+				if plm_audio_has_values(&l3.Xr[1], int64(long[sfb]), int64(long[sfb+1])) {
+					mixed_first = sfb + 1
+				}
+			}
+		}
+		for sfb := int64(0); sfb < 8; sfb++ {
+			var is_pos int64 = 7
+			if sfb >= mixed_first {
+				is_pos = l3.Scalefac_l[1][sfb]
+			}
+			plm_audio_stereo_band(self, int64(long[sfb]), int64(long[sfb+1]), is_pos, ms)
+		}
+	}
+	for sfb := short_band; sfb < 13; sfb++ {
+		var width int64 = int64(short[sfb+1] - short[sfb])
+		for w := int64(0); w < 3; w++ {
+			var is_pos int64 = 7
+			if sfb >= first[w] {
+				is_pos = l3.Scalefac_s[1][plm_audio_min(sfb, 11)][w]
+			}
+			plm_audio_stereo_band(self, i, i+width, is_pos, ms)
+			i += width
+		}
+	}
+}
+func plm_audio_has_values(xr *[576]float32, start int64, end int64) bool {
+	for i := start; i < end; i++ {
+		if xr[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+func plm_audio_min(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// plm_audio_stereo_band decodes the lines from "start" to "end" with intensity
+// stereo at "is_pos", or as middle and side if "is_pos" is 7 and "ms" is set.
+func plm_audio_stereo_band(self *plm_audio_t, start int64, end int64, is_pos int64, ms bool) {
+	var l3 *plm_audio_layer3_t = self.Layer3
+	if is_pos != 7 {
+		var ratio float64 = math.Tan(float64(is_pos) * math.Pi / 12)
+		var left float32 = float32(ratio / (1 + ratio))
+		var right float32 = float32(1 / (1 + ratio))
+		for i := start; i < end; i++ {
+			l3.Xr[1][i] = l3.Xr[0][i] * right
+			l3.Xr[0][i] *= left
+		}
+		return
+	}
+	if !ms {
+		return
+	}
+	for i := start; i < end; i++ {
+		var m float32 = l3.Xr[0][i]
+		var s float32 = l3.Xr[1][i]
+		l3.Xr[0][i] = (m + s) * math.Sqrt2 / 2
+		l3.Xr[1][i] = (m - s) * math.Sqrt2 / 2
+	}
+}
+
+// plm_audio_hybrid_synthesis turns the spectrum of a granule into 18 samples
+// of each subband, which replace it in "Xr" ordered by subband.
+func plm_audio_hybrid_synthesis(self *plm_audio_t, gr int64, ch int64) {
+	var (
+		l3         *plm_audio_layer3_t  = self.Layer3
+		gi         *plm_audio_granule_t = &l3.Granule[gr][ch]
+		xr         *[576]float32        = &l3.Xr[ch]
+		long_lines int64                = 576
+	)
+	if gi.Block_type == 2 {
+		long_lines = 0
+		if gi.Mixed_block != 0 {
+			long_lines = 36
+		}
+		// Short blocks are ordered by band and window. Each subband takes the
+		// 6 lines of each window in turn.
+		var (
+			short *[14]int16 = &plm_audio_layer3_band_short[self.Samplerate_index]
+			tmp   [576]float32
+			i     int64 = long_lines
+		)
+		for sfb := long_lines / 12; sfb < 13; sfb++ {
+			for w := int64(0); w < 3; w++ {
+				for f := int64(short[sfb]); f < int64(short[sfb+1]); f++ {
+					tmp[(f/6)*18+w*6+f%6] = xr[i]
+					i++
+				}
+			}
+		}
+		copy(xr[long_lines:], tmp[long_lines:])
+	}
+
+	// Alias reduction between the subbands of long blocks
+	for sb := int64(1); sb*18 <= long_lines && sb < 32; sb++ {
+		for i := int64(0); i < 8; i++ {
+			var lo float32 = xr[sb*18-1-i]
+			var hi float32 = xr[sb*18+i]
+			xr[sb*18-1-i] = lo*plm_audio_layer3_antialias[0][i] - hi*plm_audio_layer3_antialias[1][i]
+			xr[sb*18+i] = hi*plm_audio_layer3_antialias[0][i] + lo*plm_audio_layer3_antialias[1][i]
+		}
+	}
+
+	var out [36]float32
+	for sb := int64(0); sb < 32; sb++ {
+		var in []float32 = xr[sb*18 : sb*18+18]
+		out = [36]float32{}
+		if sb*18 >= long_lines {
+			var window *[36]float32 = &plm_audio_layer3_window[2]
+			for w := int64(0); w < 3; w++ {
+				for i := int64(0); i < 12; i++ {
+					var sum float32 = 0
+					for k := int64(0); k < 6; k++ {
+						sum += in[w*6+k] * plm_audio_layer3_imdct_short[i][k]
+					}
+					out[6+w*6+i] += sum * window[i]
+				}
+			}
+		} else {
+			var window *[36]float32 = &plm_audio_layer3_window[0]
+			if gi.Block_type != 2 {
+				window = &plm_audio_layer3_window[gi.Block_type]
+			}
+			for i := int64(0); i < 36; i++ {
+				var sum float32 = 0
+				for k := int64(0); k < 18; k++ {
+					sum += in[k] * plm_audio_layer3_imdct_long[i][k]
+				}
+				out[i] = sum * window[i]
+			}
+		}
+		// Overlap with the previous granule, and invert every other sample of
+		// odd subbands to undo their frequency inversion.
+		var overlap []float32 = l3.Overlap[ch][sb*18 : sb*18+18]
+		for i := int64(0); i < 18; i++ {
+			var sample float32 = out[i] + overlap[i]
+			overlap[i] = out[i+18]
+			if sb&1 != 0 && i&1 != 0 {
+				sample = -sample
+			}
+			in[i] = sample
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package mpg
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// testChord returns "count" samples of a mix of sines for each of "channels"
+// channels, which spreads over several subbands.
+func testChord(rate, channels, count int) [][]float32 {
+	pcm := make([][]float32, channels)
+	for i := 0; i < count; i++ {
+		t := float64(i) / float64(rate)
+		pcm[0] = append(pcm[0], float32(0.3*math.Sin(2*math.Pi*440*t)+0.2*math.Sin(2*math.Pi*1500*t)+0.1*math.Sin(2*math.Pi*3700*t)))
+		if channels == 2 {
+			pcm[1] = append(pcm[1], float32(0.25*math.Sin(2*math.Pi*660*t)+0.15*math.Sin(2*math.Pi*2500*t)))
+		}
+	}
+	return pcm
+}
+
+// interleave returns the samples of "pcm" interleaved like "Samples", with a
+// single channel copied to both.
+func interleave(pcm [][]float32) []float32 {
+	out := make([]float32, 0, len(pcm[0])*2)
+	for i := range pcm[0] {
+		out = append(out, pcm[0][i], pcm[len(pcm)-1][i])
+	}
+	return out
+}
+
+// writeAudioHeader writes the header of an audio frame without CRC or padding.
+// Sample rates below 32000 Hz are MPEG2 low sample rates.
+func writeAudioHeader(w *bitWriter, layer, bitrateIndex, rate int, mode ChannelMode, modeExtension int) {
+	w.write(0xFFF, 12)
+	if rate < 32000 {
+		w.write(0, 1)
+	} else {
+		w.write(1, 1)
+	}
+	w.write(uint32(4-layer), 2)
+	w.write(1, 1)
+	w.write(uint32(bitrateIndex), 4)
+	for i, r := range plm_audio_sample_rate {
+		if int(r) == rate {
+			w.write(uint32(i&3), 2)
+		}
+	}
+	w.write(0, 2)
+	w.write(uint32(mode), 2)
+	w.write(uint32(modeExtension), 2)
+	w.write(0, 4)
+}
+
+// encodeLayerI encodes "pcm" at "rate" Hz as Layer I, at 448 kbit/s or at
+// 256 kbit/s for low sample rates. Subbands up to 6 kHz are quantized with 15
+// bits, or 9 bits at low sample rates, and the others are left out. Joint
+// stereo shares the samples of subbands 4 and up between the channels.
+func encodeLayerI(t testing.TB, pcm [][]float32, mode ChannelMode, rate int) []byte {
+	t.Helper()
+	// The filterbank does not depend on the sample rate.
+	enc, err := NewAudioEncoder(AudioEncoderOptions{SampleRate: 48000, Mode: mode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Code the same frequencies at every sample rate.
+	sblimit := 8 * 48000 / rate
+	bits, bitrate, bitrateIndex := 15, 448000, 14
+	if rate < 32000 {
+		bits, bitrate, bitrateIndex = 9, 256000, 14
+	}
+	frameSize := 12 * bitrate / rate * 4
+	bound := 32
+	switch mode {
+	case ChannelModeMono:
+		bound = 0
+	case ChannelModeJointStereo:
+		// The bound of mode extension 0
+		bound = 4
+	}
+	var w bitWriter
+	for offset := 0; offset+1152 <= len(pcm[0]); offset += 1152 {
+		for ch := range pcm {
+			enc.pcm[ch] = append(enc.pcm[ch][:0], pcm[ch][offset:offset+1152]...)
+		}
+		enc.analyze()
+		// Each Layer I frame codes 12 samples of every subband.
+		for part := 0; part < 3; part++ {
+			start := w.len()
+			writeAudioHeader(&w, 1, bitrateIndex, rate, mode, 0)
+			for sb := 0; sb < 32; sb++ {
+				channels := len(pcm)
+				if sb >= bound {
+					channels = 1
+				}
+				for ch := 0; ch < channels; ch++ {
+					if sb < sblimit {
+						w.write(uint32(bits-1), 4)
+					} else {
+						w.write(0, 4)
+					}
+				}
+			}
+			var scalefactor [2][32]int
+			for sb := 0; sb < sblimit; sb++ {
+				for ch := range pcm {
+					peak := 0.0
+					for s := part * 12; s < part*12+12; s++ {
+						peak = math.Max(peak, math.Abs(enc.subband[ch][sb][s]))
+					}
+					scalefactor[ch][sb] = scalefactorIndex(peak)
+					w.write(uint32(scalefactor[ch][sb]), 6)
+				}
+			}
+			levels := 1<<bits - 1
+			for s := part * 12; s < part*12+12; s++ {
+				for sb := 0; sb < sblimit; sb++ {
+					channels := len(pcm)
+					if sb >= bound {
+						channels = 1
+					}
+					for ch := 0; ch < channels; ch++ {
+						q := int(math.Round(enc.subband[ch][sb][s] / scalefactorValue(scalefactor[ch][sb]) * float64(levels) / 2))
+						if q > levels/2 {
+							q = levels / 2
+						} else if q < -levels/2 {
+							q = -levels / 2
+						}
+						w.write(uint32(levels/2-q), uint(bits))
+					}
+				}
+			}
+			for w.len()-start < frameSize*8 {
+				w.write(0, 8)
+			}
+		}
+	}
+	return w.bytes()
+}
+
+func TestAudioDecoderLayerI(t *testing.T) {
+	for _, mode := range []ChannelMode{ChannelModeMono, ChannelModeStereo, ChannelModeJointStereo} {
+		channels := 2
+		if mode == ChannelModeMono {
+			channels = 1
+		}
+		pcm := testChord(48000, channels, 20*1152)
+		if mode == ChannelModeJointStereo {
+			pcm[1] = pcm[0]
+		}
+		data := encodeLayerI(t, pcm, mode, 48000)
+		d, err := NewAudioDecoderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.SampleRate() != 48000 || d.BitRate() != 448000 || d.ChannelMode() != mode {
+			t.Errorf("%v: audio is %v at %d Hz and %d bit/s, want 48000 Hz and 448000 bit/s", mode, d.ChannelMode(), d.SampleRate(), d.BitRate())
+		}
+		got, want := decodeTestAudio(t, data), interleave(pcm)
+		if len(got) != len(want) {
+			t.Errorf("%v: decoded %d samples, want %d", mode, len(got)/2, len(want)/2)
+		}
+		for ch := 0; ch < 2; ch++ {
+			if snr := toneSNR(got, want, ch); snr < 60 {
+				t.Errorf("%v: SNR of channel %d is %.1f dB, want at least 60 dB", mode, ch, snr)
+			}
+		}
+	}
+}
+
+// layerIIICodes are the Huffman codes of pairs of values, by table.
+var layerIIICodes = map[int]map[int16]vlcCode{
+	15: vlcEncodeTable(plm_audio_huffman_table_15[:]),
+	16: vlcEncodeTable(plm_audio_huffman_table_16[:]),
+}
+
+// layerIIIGranule is the quantized spectrum of a granule of one channel.
+type layerIIIGranule struct {
+	values     [576]int
+	globalGain int
+	table      int
+	bigValues  int
+	bits       int
+}
+
+// quantize quantizes "xr" with the smallest global gain whose Huffman coded
+// values fit in "budget" bits. Values up to 15 are coded with table 15, and
+// larger ones with the table of the fewest linbits that fit.
+func (g *layerIIIGranule) quantize(xr *[576]float64, budget int) {
+	// The largest gain quantizes everything to 0, which always fits.
+	for g.globalGain = 0; g.globalGain < 256; g.globalGain++ {
+		step := math.Exp2(float64(g.globalGain-210) / 4)
+		last, peak := -1, 0
+		for i, v := range xr {
+			q := int(math.Round(math.Pow(math.Abs(v)/step, 0.75)))
+			if q > peak {
+				peak = q
+			}
+			if q != 0 {
+				last = i
+			}
+			if v < 0 {
+				q = -q
+			}
+			g.values[i] = q
+		}
+		g.table = 15
+		if peak > 15 {
+			g.table = 0
+			for table := 16; table < 24 && g.table == 0; table++ {
+				if peak-15 < 1<<plm_audio_huffman_linbits[table] {
+					g.table = table
+				}
+			}
+			if g.table == 0 {
+				continue
+			}
+		}
+		g.bigValues = (last + 2) / 2
+		if g.bits = g.write(nil); g.bits <= budget {
+			return
+		}
+	}
+}
+
+// write writes the Huffman coded values to "w" if it is not nil, and returns
+// their size in bits.
+func (g *layerIIIGranule) write(w *bitWriter) (size int) {
+	codes := layerIIICodes[15]
+	linbits := uint(plm_audio_huffman_linbits[g.table])
+	if g.table > 15 {
+		codes = layerIIICodes[16]
+	}
+	for i := 0; i < g.bigValues*2; i += 2 {
+		x, y := abs(g.values[i]), abs(g.values[i+1])
+		code := codes[int16(minInt(x, 15)<<4|minInt(y, 15))]
+		size += int(code.length)
+		if w != nil {
+			w.writeCode(code)
+		}
+		for _, v := range g.values[i : i+2] {
+			if linbits > 0 && abs(v) >= 15 {
+				size += int(linbits)
+				if w != nil {
+					w.write(uint32(abs(v)-15), linbits)
+				}
+			}
+			if v != 0 {
+				size++
+				if w != nil {
+					w.writeBool(v < 0)
+				}
+			}
+		}
+	}
+	return size
+}
+
+// encodeLayerIII encodes "pcm" at 48000 Hz and "kbps" kbit/s as Layer III
+// with long blocks only. Odd frames save part of their main data for the
+// next frame, which gets it through the bit reservoir.
+func encodeLayerIII(t testing.TB, pcm [][]float32, kbps int) []byte {
+	t.Helper()
+	mode := ChannelModeStereo
+	if len(pcm) == 1 {
+		mode = ChannelModeMono
+	}
+	enc, err := NewAudioEncoder(AudioEncoderOptions{SampleRate: 48000, Mode: mode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channels := len(pcm)
+	bitrateIndex := 0
+	for i, rate := range plm_audio_layer_iii_bit_rate[:14] {
+		if int(rate) == kbps {
+			bitrateIndex = i + 1
+		}
+	}
+	frameSize := 144 * kbps * 1000 / 48000
+	sideInfoSize := 32
+	if channels == 1 {
+		sideInfoSize = 17
+	}
+	var (
+		out []byte
+		// slots are the positions in "out" of the main data of all frames,
+		// of which the last "reservoir" bytes are free.
+		slots     []int
+		reservoir int
+		previous  [2][32][18]float64
+	)
+	for frame := 0; frame*1152+1152 <= len(pcm[0]); frame++ {
+		for ch := range pcm {
+			enc.pcm[ch] = append(enc.pcm[ch][:0], pcm[ch][frame*1152:frame*1152+1152]...)
+		}
+		enc.analyze()
+
+		available := (frameSize-4-sideInfoSize+reservoir)*8 - 7
+		if frame%2 == 1 {
+			available /= 2
+		}
+		var granules [2][2]layerIIIGranule
+		for gr := 0; gr < 2; gr++ {
+			for ch := 0; ch < channels; ch++ {
+				xr := hybridAnalysis(&enc.subband[ch], &previous[ch], gr)
+				budget := minInt(available/(2*channels-gr*channels-ch), 4095)
+				granules[gr][ch].quantize(xr, budget)
+				available -= granules[gr][ch].bits
+			}
+		}
+
+		var w bitWriter
+		writeAudioHeader(&w, 3, bitrateIndex, 48000, mode, 0)
+		w.write(uint32(reservoir), 9)
+		w.write(0, uint(7-channels*2))
+		w.write(0, uint(4*channels))
+		for gr := 0; gr < 2; gr++ {
+			for ch := 0; ch < channels; ch++ {
+				g := &granules[gr][ch]
+				w.write(uint32(g.bits), 12)
+				w.write(uint32(g.bigValues), 9)
+				w.write(uint32(g.globalGain), 8)
+				w.write(0, 4) // No scale factors
+				w.write(0, 1) // Long blocks
+				for i := 0; i < 3; i++ {
+					w.write(uint32(g.table), 5)
+				}
+				// Region counts, preflag, scale factor scale and count1
+				// table.
+				w.write(0, 4+3+3)
+			}
+		}
+		header := w.bytes()
+		var main bitWriter
+		for gr := 0; gr < 2; gr++ {
+			for ch := 0; ch < channels; ch++ {
+				granules[gr][ch].write(&main)
+			}
+		}
+		data := main.bytes()
+
+		// The main data starts in the free bytes of the frames before.
+		out = append(out, header...)
+		for i := len(header); i < frameSize; i++ {
+			slots = append(slots, len(out))
+			out = append(out, 0)
+		}
+		free := slots[len(slots)-(frameSize-len(header))-reservoir:]
+		for i, b := range data {
+			out[free[i]] = b
+		}
+		reservoir = minInt(len(free)-len(data), plm_audio_layer3_max_reservoir)
+	}
+	return out
+}
+
+// hybridAnalysis turns 18 samples of each subband of granule "gr" into their
+// spectrum, the inverse of "plm_audio_hybrid_synthesis" for long blocks.
+// "previous" holds the samples of the granule before.
+func hybridAnalysis(subband *[32][36]float64, previous *[32][18]float64, gr int) *[576]float64 {
+	var xr [576]float64
+	for sb := 0; sb < 32; sb++ {
+		var x [36]float64
+		for i := 0; i < 18; i++ {
+			s := subband[sb][gr*18+i]
+			if sb&1 != 0 && i&1 != 0 {
+				s = -s
+			}
+			x[i], x[18+i] = previous[sb][i], s
+			previous[sb][i] = s
+		}
+		for k := 0; k < 18; k++ {
+			sum := 0.0
+			for i := range x {
+				sum += float64(plm_audio_layer3_window[0][i]) * x[i] * float64(plm_audio_layer3_imdct_long[i][k])
+			}
+			xr[sb*18+k] = sum / 18
+		}
+	}
+	// Undo the alias reduction of the decoder.
+	for sb := 1; sb < 32; sb++ {
+		for i := 0; i < 8; i++ {
+			cs, ca := float64(plm_audio_layer3_antialias[0][i]), float64(plm_audio_layer3_antialias[1][i])
+			lo, hi := xr[sb*18-1-i], xr[sb*18+i]
+			xr[sb*18-1-i] = lo*cs + hi*ca
+			xr[sb*18+i] = hi*cs - lo*ca
+		}
+	}
+	return &xr
+}
+
+func TestAudioDecoderLayerIII(t *testing.T) {
+	for _, tt := range []struct {
+		channels, kbps int
+		minSNR         float64
+	}{
+		{1, 128, 60},
+		{2, 256, 60},
+		{1, 32, 20},
+	} {
+		pcm := testChord(48000, tt.channels, 20*1152)
+		data := encodeLayerIII(t, pcm, tt.kbps)
+		d, err := NewAudioDecoderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.SampleRate() != 48000 || d.BitRate() != tt.kbps*1000 {
+			t.Errorf("%d kbit/s: audio is at %d Hz and %d bit/s", tt.kbps, d.SampleRate(), d.BitRate())
+		}
+		got, want := decodeTestAudio(t, data), interleave(pcm)
+		if len(got) != len(want) {
+			t.Errorf("%d kbit/s: decoded %d samples, want %d", tt.kbps, len(got)/2, len(want)/2)
+		}
+		for ch := 0; ch < 2; ch++ {
+			if snr := toneSNR(got, want, ch); snr < tt.minSNR {
+				t.Errorf("%d kbit/s: SNR of channel %d is %.1f dB, want at least %.0f dB", tt.kbps, ch, snr, tt.minSNR)
+			}
+		}
+	}
+}
+
+// encodeLayerIILowSampleRate encodes "pcm" at the low sample rate "rate" and
+// "kbps" kbit/s as MPEG2 Layer II. The MPEG1 encoder is set up with the
+// allocation table of low sample rates, and the headers of its frames are
+// rewritten.
+func encodeLayerIILowSampleRate(t testing.TB, pcm [][]float32, mode ChannelMode, rate, kbps int) []byte {
+	t.Helper()
+	enc, err := NewAudioEncoder(AudioEncoderOptions{SampleRate: 32000, Mode: mode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 14; i < 28; i++ {
+		if int(plm_audio_bit_rate[i]) == kbps {
+			enc.bitrateIndex = i
+		}
+	}
+	enc.opts.SampleRate, enc.opts.BitRate = rate, kbps*1000
+	enc.tab3, enc.sblimit = 2, 30
+	input := pcm[0]
+	if len(pcm) == 2 {
+		input = interleave(pcm)
+	}
+	data := append([]byte(nil), enc.EncodeFloat32(input)...)
+	data = append(data, enc.Flush()...)
+	frameSize := 144 * kbps * 1000 / rate
+	if len(data)%frameSize != 0 {
+		t.Fatalf("encoded %d bytes, which is not a multiple of the frame size %d", len(data), frameSize)
+	}
+	var header bitWriter
+	writeAudioHeader(&header, 2, enc.bitrateIndex-13, rate, mode, 0)
+	for i := 0; i < len(data); i += frameSize {
+		data[i+1] = header.data[1]
+		data[i+2] = header.data[2]&0xFC | data[i+2]&0x03
+	}
+	return data
+}
+
+func TestAudioDecoderLowSampleRate(t *testing.T) {
+	for _, tt := range []struct {
+		layer, channels, rate, kbps int
+		snr                         float64
+	}{
+		{1, 1, 24000, 256, 50},
+		{1, 2, 16000, 256, 50},
+		{2, 1, 24000, 64, 45},
+		// Subbands 11 and up, which hold 3700 Hz, have at most 9 levels.
+		{2, 2, 16000, 96, 25},
+	} {
+		name := fmt.Sprintf("layer %d at %d Hz", tt.layer, tt.rate)
+		mode := ChannelModeStereo
+		if tt.channels == 1 {
+			mode = ChannelModeMono
+		}
+		pcm := testChord(tt.rate, tt.channels, 20*1152)
+		var data []byte
+		if tt.layer == 1 {
+			data = encodeLayerI(t, pcm, mode, tt.rate)
+		} else {
+			data = encodeLayerIILowSampleRate(t, pcm, mode, tt.rate, tt.kbps)
+		}
+		d, err := NewAudioDecoderFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.SampleRate() != tt.rate || d.BitRate() != tt.kbps*1000 || d.ChannelMode() != mode {
+			t.Errorf("%s: audio is %v at %d Hz and %d bit/s, want %v at %d bit/s", name, d.ChannelMode(), d.SampleRate(), d.BitRate(), mode, tt.kbps*1000)
+		}
+		if want := time.Duration(20*1152) * time.Second / time.Duration(tt.rate); d.Duration() != want {
+			t.Errorf("%s: duration is %v, want %v", name, d.Duration(), want)
+		}
+		got, want := decodeTestAudio(t, data), interleave(pcm)
+		if len(got) != len(want) {
+			t.Errorf("%s: decoded %d samples, want %d", name, len(got)/2, len(want)/2)
+		}
+		for ch := 0; ch < 2; ch++ {
+			if snr := toneSNR(got, want, ch); snr < tt.snr {
+				t.Errorf("%s: SNR of channel %d is %.1f dB, want at least %v dB", name, ch, snr, tt.snr)
+			}
+		}
+		if err := d.Seek(time.Second/2, true); err != nil || d.Time() != time.Second/2 {
+			t.Errorf("%s: seeking to 0.5s landed at %v: %v", name, d.Time(), err)
+		}
+	}
+}
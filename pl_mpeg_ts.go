@@ -0,0 +1,479 @@
+package mpg
+
+import "unsafe"
+
+// Besides program streams, the demuxer reads MPEG transport streams (.ts
+// files, as written by capture devices and used for HLS segments). They are
+// made of 188 byte packets, each carrying a piece of one elementary stream,
+// identified by its PID. PID 0 carries the program association table (PAT),
+// which gives the PID of the program map table (PMT), which in turn lists the
+// PIDs of the video and audio streams of the program.
+//
+// The PES packets of each stream are reassembled from the payloads of the
+// transport packets, and are handed out like those of a program stream. Their
+// stream IDs are chosen by the order of the streams in the PMT, so that
+// "plm_demux_packet_video_1" is the first video stream and
+// "plm_demux_packet_audio_1" the first audio stream. Only the first program of
+// the PAT is read.
+//
+// A PES packet spans many transport packets, so it has no single position in
+// the file. "Packet_start" is the position of the transport packet it started
+// in, which is where seeking to it resumes reading.
+
+const plm_demux_ts_packet_size = 188
+const plm_demux_ts_sync_byte = 71
+
+const plm_demux_ts_stream_mpeg1_video = 1
+const plm_demux_ts_stream_mpeg2_video = 2
+const plm_demux_ts_stream_mpeg1_audio = 3
+const plm_demux_ts_stream_mpeg2_audio = 4
+
+type plm_demux_ts_stream_t struct {
+	Pid        int64
+	Type       int64
+	Continuity int64
+	Started    int64
+	Start      uint64
+	Data       []uint8
+}
+type plm_demux_ts_pes_t struct {
+	Type  int64
+	Start uint64
+	Data  []uint8
+}
+type plm_demux_ts_t struct {
+	Synced       int64
+	Pmt_pid      int64
+	Pcr_pid      int64
+	Section      []uint8
+	Streams      []plm_demux_ts_stream_t
+	Ready        []plm_demux_ts_pes_t
+	Current      []uint8
+	Spare        []uint8
+	Packet_start uint64
+	First_pcr    float64
+	First_pcr_at uint64
+}
+
+// plm_demux_ts_detect checks whether the buffer starts with a transport
+// stream, and skips any garbage before its first packet if so. It returns -1
+// when more data is needed to tell.
+func plm_demux_ts_detect(self *plm_demux_t) int64 {
+	var size uint64 = plm_demux_ts_packet_size
+	if plm_buffer_has(self.Buffer, (size*2+1)<<3) == 0 && plm_buffer_has_ended(self.Buffer) == 0 {
+		return -1
+	}
+	var (
+		pos       uint64 = self.Buffer.Bit_index >> 3
+		available uint64 = self.Buffer.Length - pos
+		data      []uint8
+	)
+	if available < size+1 {
+		return _false
+	}
+	data = unsafe.Slice((*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), pos)), available)
+	if data[0] == 0 && data[1] == 0 && data[2] == 1 {
+		return _false
+	}
+	for i := uint64(0); i < size && i+size < available; i++ {
+		if data[i] != plm_demux_ts_sync_byte || data[i+size] != plm_demux_ts_sync_byte {
+			continue
+		}
+		if i+size*2 < available && data[i+size*2] != plm_demux_ts_sync_byte {
+			continue
+		}
+		self.Buffer.Bit_index += i << 3
+		self.Ts = new(plm_demux_ts_t)
+		self.Ts.Pmt_pid = -1
+		self.Ts.Pcr_pid = -1
+		self.Ts.First_pcr = float64(-1)
+		return _true
+	}
+	return _false
+}
+
+// plm_demux_ts_has_headers reads packets until the PAT and the PMT of the
+// first program were found.
+func plm_demux_ts_has_headers(self *plm_demux_t) int64 {
+	for self.Has_headers == 0 {
+		if plm_demux_ts_read_packet(self, 0) == 0 {
+			return _false
+		}
+	}
+	return _true
+}
+
+// plm_demux_ts_reset drops the partial packets after the buffer was seeked.
+func plm_demux_ts_reset(self *plm_demux_t) {
+	var ts *plm_demux_ts_t = self.Ts
+	ts.Synced = _false
+	ts.Section = ts.Section[:0]
+	ts.Ready = nil
+	for i := range ts.Streams {
+		ts.Streams[i].Continuity = -1
+		ts.Streams[i].Started = _false
+		ts.Streams[i].Data = ts.Streams[i].Data[:0]
+	}
+}
+
+// plm_demux_ts_copy returns a copy of the reassembly state of "ts", to be
+// restored after reading elsewhere in the buffer. It returns nil for nil.
+func plm_demux_ts_copy(ts *plm_demux_ts_t) *plm_demux_ts_t {
+	if ts == nil {
+		return nil
+	}
+	var copy_ *plm_demux_ts_t = new(plm_demux_ts_t)
+	*copy_ = *ts
+	copy_.Section = append([]uint8(nil), ts.Section...)
+	copy_.Streams = append([]plm_demux_ts_stream_t(nil), ts.Streams...)
+	for i := range copy_.Streams {
+		copy_.Streams[i].Data = append([]uint8(nil), ts.Streams[i].Data...)
+	}
+	copy_.Ready = append([]plm_demux_ts_pes_t(nil), ts.Ready...)
+	for i := range copy_.Ready {
+		copy_.Ready[i].Data = append([]uint8(nil), ts.Ready[i].Data...)
+	}
+	copy_.Current = append([]uint8(nil), ts.Current...)
+	copy_.Spare = nil
+	return copy_
+}
+
+// plm_demux_ts_restore makes "ts", a copy taken with "plm_demux_ts_copy", the
+// reassembly state again. It must be called after the current packet was
+// restored, as its data is moved to the copy.
+func plm_demux_ts_restore(self *plm_demux_t, ts *plm_demux_ts_t) {
+	if ts == nil {
+		return
+	}
+	self.Ts = ts
+	if self.Current_packet.Length != 0 && uint64(len(ts.Current)) >= self.Current_packet.Length {
+		self.Current_packet.Data = &ts.Current[uint64(len(ts.Current))-self.Current_packet.Length]
+	}
+}
+
+// plm_demux_ts_decode returns the next PES packet of type "type_", or of any
+// type if it is 0. It returns nil when more data is needed.
+func plm_demux_ts_decode(self *plm_demux_t, type_ int64) *plm_packet_t {
+	var ts *plm_demux_ts_t = self.Ts
+	if ts.Current != nil {
+		ts.Spare = ts.Current[:0]
+		ts.Current = nil
+	}
+	self.Current_packet.Length = 0
+	for {
+		for len(ts.Ready) != 0 {
+			var pes plm_demux_ts_pes_t = ts.Ready[0]
+			ts.Ready = ts.Ready[1:]
+			if type_ != 0 && pes.Type != type_ {
+				continue
+			}
+			var packet *plm_packet_t = plm_demux_ts_get_packet(self, &pes)
+			if packet != nil {
+				return packet
+			}
+		}
+		if plm_demux_ts_read_packet(self, type_) == 0 {
+			if plm_buffer_has_ended(self.Buffer) == 0 || plm_demux_ts_flush(self, type_) == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// plm_demux_ts_read_packet reads the next transport packet. It returns false
+// when no whole packet is left in the buffer. Payloads of streams other than
+// "type_" are dropped, unless it is 0.
+func plm_demux_ts_read_packet(self *plm_demux_t, type_ int64) int64 {
+	var (
+		ts   *plm_demux_ts_t = self.Ts
+		size uint64          = plm_demux_ts_packet_size
+		data []uint8
+	)
+	for {
+		if plm_buffer_has(self.Buffer, size<<3) == 0 {
+			return _false
+		}
+		var next_known int64 = ts.Synced
+		if next_known == 0 && plm_buffer_has(self.Buffer, (size+1)<<3) == 0 {
+			next_known = _true
+		}
+		var pos uint64 = self.Buffer.Bit_index >> 3
+		data = unsafe.Slice((*uint8)(unsafe.Add(unsafe.Pointer(self.Buffer.Bytes), pos)), self.Buffer.Length-pos)
+		if data[0] == plm_demux_ts_sync_byte && (next_known != 0 || data[size] == plm_demux_ts_sync_byte) {
+			break
+		}
+		if ts.Synced != 0 {
+			self.Error = plm_error_demux_packet_header
+			self.Error_offset = pos
+			ts.Synced = _false
+		}
+		self.Buffer.Bit_index += 8
+	}
+	ts.Synced = _true
+	var packet_start uint64 = plm_buffer_tell(self.Buffer)
+	data = data[:size]
+	self.Buffer.Bit_index += size << 3
+	var (
+		error_indicator    int64   = int64(data[1] >> 7)
+		payload_start      int64   = int64(data[1]>>6) & 1
+		pid                int64   = int64(data[1]&31)<<8 | int64(data[2])
+		scrambling         int64   = int64(data[3] >> 6)
+		adaptation_control int64   = int64(data[3]>>4) & 3
+		continuity         int64   = int64(data[3] & 15)
+		discontinuity      int64   = _false
+		payload            []uint8 = data[4:]
+	)
+	if error_indicator != 0 {
+		self.Error = plm_error_demux_packet_header
+		self.Error_offset = packet_start
+		return _true
+	}
+	if adaptation_control&2 != 0 {
+		var length int64 = int64(payload[0])
+		if length > int64(len(payload))-1 {
+			self.Error = plm_error_demux_packet_header
+			self.Error_offset = packet_start
+			return _true
+		}
+		if length > 0 {
+			discontinuity = int64(payload[1]>>7) & 1
+			if payload[1]&16 != 0 && length >= 7 && pid == ts.Pcr_pid {
+				plm_demux_ts_decode_pcr(self, payload[2:8], packet_start)
+			}
+		}
+		payload = payload[1+length:]
+	}
+	if adaptation_control&1 == 0 {
+		return _true
+	}
+	if self.Has_headers == 0 {
+		var wanted int64 = 0
+		if ts.Pmt_pid != -1 {
+			wanted = ts.Pmt_pid
+		}
+		if pid == wanted {
+			plm_demux_ts_read_section(self, payload_start, payload)
+		}
+		return _true
+	}
+	var stream *plm_demux_ts_stream_t = nil
+	for i := range ts.Streams {
+		if ts.Streams[i].Pid == pid {
+			stream = &ts.Streams[i]
+			break
+		}
+	}
+	if stream == nil {
+		return _true
+	}
+	if stream.Continuity != -1 && discontinuity == 0 {
+		if continuity == stream.Continuity {
+			return _true
+		}
+		if continuity != (stream.Continuity+1)&15 && stream.Started != 0 {
+			self.Error = plm_error_demux_packet_lost
+			self.Error_offset = packet_start
+			stream.Started = _false
+			stream.Data = stream.Data[:0]
+		}
+	}
+	stream.Continuity = continuity
+	if scrambling != 0 || type_ != 0 && stream.Type != type_ {
+		stream.Started = _false
+		stream.Data = stream.Data[:0]
+		return _true
+	}
+	if payload_start != 0 {
+		if stream.Started != 0 {
+			plm_demux_ts_complete(self, stream)
+		}
+		stream.Started = _true
+		stream.Start = packet_start
+	} else if stream.Started == 0 {
+		return _true
+	}
+	stream.Data = append(stream.Data, payload...)
+	if len(stream.Data) >= 6 {
+		var length int = int(stream.Data[4])<<8 | int(stream.Data[5])
+		if length != 0 && len(stream.Data) >= length+6 {
+			plm_demux_ts_complete(self, stream)
+		}
+	}
+	return _true
+}
+
+// plm_demux_ts_decode_pcr reads the program clock reference of an adaptation
+// field. The mux rate is estimated from the first two of them.
+func plm_demux_ts_decode_pcr(self *plm_demux_t, data []uint8, pos uint64) {
+	var (
+		ts        *plm_demux_ts_t = self.Ts
+		base      int64           = int64(data[0])<<25 | int64(data[1])<<17 | int64(data[2])<<9 | int64(data[3])<<1 | int64(data[4]>>7)
+		extension int64           = int64(data[4]&1)<<8 | int64(data[5])
+	)
+	self.System_clock_ref = float64(base)/90000.0 + float64(extension)/27000000.0
+	if ts.First_pcr == float64(-1) || pos < ts.First_pcr_at {
+		ts.First_pcr = self.System_clock_ref
+		ts.First_pcr_at = pos
+	} else if self.Mux_rate == 0 && self.System_clock_ref > ts.First_pcr {
+		self.Mux_rate = int64(float64(pos-ts.First_pcr_at) / (self.System_clock_ref - ts.First_pcr) / 50)
+	}
+}
+
+// plm_demux_ts_read_section assembles the sections of the PAT and the PMT
+// from the payloads of their packets, and parses them once complete.
+func plm_demux_ts_read_section(self *plm_demux_t, payload_start int64, payload []uint8) {
+	var ts *plm_demux_ts_t = self.Ts
+	if payload_start != 0 {
+		if len(payload) == 0 || int(payload[0])+1 > len(payload) {
+			return
+		}
+		ts.Section = append(ts.Section[:0], payload[int(payload[0])+1:]...)
+	} else if len(ts.Section) != 0 {
+		ts.Section = append(ts.Section, payload...)
+	}
+	if len(ts.Section) < 3 {
+		return
+	}
+	var length int = (int(ts.Section[1]&15)<<8 | int(ts.Section[2])) + 3
+	if len(ts.Section) < length {
+		return
+	}
+	var section []uint8 = ts.Section[:length]
+	ts.Section = ts.Section[:0]
+	if length < 12 || section[5]&1 == 0 {
+		return
+	}
+	if section[0] == 0 && ts.Pmt_pid == -1 {
+		for i := 8; i+4 <= length-4; i += 4 {
+			var program int64 = int64(section[i])<<8 | int64(section[i+1])
+			if program != 0 {
+				ts.Pmt_pid = int64(section[i+2]&31)<<8 | int64(section[i+3])
+				break
+			}
+		}
+	} else if section[0] == 2 && ts.Pmt_pid != -1 {
+		plm_demux_ts_parse_pmt(self, section)
+	}
+}
+
+// plm_demux_ts_parse_pmt reads the PCR PID and the elementary streams of the
+// program map table. Streams that are not MPEG video or audio are ignored.
+func plm_demux_ts_parse_pmt(self *plm_demux_t, section []uint8) {
+	var (
+		ts          *plm_demux_ts_t = self.Ts
+		length      int             = len(section)
+		info_length int             = int(section[10]&15)<<8 | int(section[11])
+	)
+	ts.Pcr_pid = int64(section[8]&31)<<8 | int64(section[9])
+	for i := info_length + 12; i+5 <= length-4; {
+		var (
+			stream_type int64 = int64(section[i])
+			pid         int64 = int64(section[i+1]&31)<<8 | int64(section[i+2])
+			type_       int64
+		)
+		i += (int(section[i+3]&15)<<8 | int(section[i+4])) + 5
+		if (stream_type == plm_demux_ts_stream_mpeg1_video || stream_type == plm_demux_ts_stream_mpeg2_video) && self.Num_video_streams < 16 {
+			type_ = plm_demux_packet_video_1 + self.Num_video_streams
+			self.Num_video_streams++
+		} else if (stream_type == plm_demux_ts_stream_mpeg1_audio || stream_type == plm_demux_ts_stream_mpeg2_audio) && self.Num_audio_streams < 32 {
+			type_ = plm_demux_packet_audio_1 + self.Num_audio_streams
+			self.Num_audio_streams++
+		} else {
+			continue
+		}
+		ts.Streams = append(ts.Streams, plm_demux_ts_stream_t{Pid: pid, Type: type_, Continuity: -1})
+		if self.Num_stream_ids < int64(len(self.Stream_ids)) {
+			self.Stream_ids[self.Num_stream_ids] = uint8(type_)
+			self.Num_stream_ids++
+		}
+	}
+	self.Has_headers = _true
+}
+
+// plm_demux_ts_complete queues the PES packet reassembled for "stream".
+func plm_demux_ts_complete(self *plm_demux_t, stream *plm_demux_ts_stream_t) {
+	var ts *plm_demux_ts_t = self.Ts
+	ts.Ready = append(ts.Ready, plm_demux_ts_pes_t{Type: stream.Type, Start: stream.Start, Data: stream.Data})
+	stream.Data = ts.Spare
+	ts.Spare = nil
+	stream.Started = _false
+}
+
+// plm_demux_ts_flush queues the PES packets still being reassembled once the
+// buffer has ended, as there is no next packet to end them. It returns false
+// if there were none.
+func plm_demux_ts_flush(self *plm_demux_t, type_ int64) int64 {
+	var (
+		ts      *plm_demux_ts_t = self.Ts
+		flushed int64           = _false
+	)
+	for i := range ts.Streams {
+		var stream *plm_demux_ts_stream_t = &ts.Streams[i]
+		if stream.Started != 0 && (type_ == 0 || stream.Type == type_) {
+			plm_demux_ts_complete(self, stream)
+			flushed = _true
+		}
+	}
+	return flushed
+}
+
+// plm_demux_ts_get_packet reads the PES header of a reassembled packet, and
+// makes its payload the current packet. It returns nil for invalid or empty
+// packets.
+func plm_demux_ts_get_packet(self *plm_demux_t, pes *plm_demux_ts_pes_t) *plm_packet_t {
+	var (
+		data   []uint8 = pes.Data
+		header int     = 6
+		pts    float64 = float64(-1)
+		dts    float64 = float64(-1)
+	)
+	if len(data) < 9 || data[0] != 0 || data[1] != 0 || data[2] != 1 {
+		self.Error = plm_error_demux_packet_header
+		self.Error_offset = pes.Start
+		return nil
+	}
+	var length int = int(data[4])<<8 | int(data[5])
+	if length != 0 && length+6 < len(data) {
+		data = data[:length+6]
+	}
+	var stream_id int64 = int64(data[3])
+	if stream_id != plm_demux_packet_padding && stream_id != plm_demux_packet_private_2 {
+		// The length may leave no room for the optional header.
+		if len(data) < 9 || data[6]>>6 != 2 || int(data[8])+9 > len(data) {
+			self.Error = plm_error_demux_packet_header
+			self.Error_offset = pes.Start
+			return nil
+		}
+		var header_length int = int(data[8])
+		var pts_dts_flags int64 = int64(data[7] >> 6)
+		if pts_dts_flags&2 != 0 && header_length >= 5 {
+			pts = plm_demux_ts_decode_time(data[9:])
+			dts = pts
+			if pts_dts_flags == 3 && header_length >= 10 {
+				dts = plm_demux_ts_decode_time(data[14:])
+			}
+		}
+		header = header_length + 9
+	}
+	if header == len(data) {
+		return nil
+	}
+	if pts != float64(-1) {
+		self.Last_decoded_pts = pts
+	}
+	self.Ts.Current = data
+	self.Ts.Packet_start = pes.Start
+	self.Current_packet.Type = pes.Type
+	self.Current_packet.Pts = pts
+	self.Current_packet.Dts = dts
+	self.Current_packet.Data = &data[header]
+	self.Current_packet.Length = uint64(len(data) - header)
+	return &self.Current_packet
+}
+
+// plm_demux_ts_decode_time reads a 33 bit time stamp of a PES header, like
+// "plm_demux_decode_time" does from the buffer.
+func plm_demux_ts_decode_time(data []uint8) float64 {
+	var clock int64 = int64(data[0]>>1&7)<<30 | int64(data[1])<<22 | int64(data[2]>>1)<<15 | int64(data[3])<<7 | int64(data[4]>>1)
+	return float64(clock) / 90000.0
+}
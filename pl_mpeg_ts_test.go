@@ -0,0 +1,280 @@
+package mpg
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// tsWriter writes transport packets, counting the continuity of each PID.
+type tsWriter struct {
+	out        []byte
+	continuity map[int]byte
+}
+
+// packet writes a transport packet of "pid" that carries as much of "payload"
+// as fits, and returns the number of payload bytes written. A "pcr" other than
+// -1 is written as the program clock reference, in 90 kHz ticks. The rest of
+// the packet is filled with stuffing bytes in the adaptation field, and a
+// packet without payload only has the adaptation field.
+func (w *tsWriter) packet(pid int, start bool, pcr int64, payload []byte) int {
+	if w.continuity == nil {
+		w.continuity = map[int]byte{}
+	}
+	packet := []byte{plm_demux_ts_sync_byte, byte(pid >> 8 & 31), byte(pid), 0}
+	if start {
+		packet[1] |= 0x40
+	}
+	var adaptation []byte
+	if pcr != -1 {
+		adaptation = []byte{0x10, byte(pcr >> 25), byte(pcr >> 17), byte(pcr >> 9), byte(pcr >> 1), byte(pcr<<7) | 0x7E, 0}
+	}
+	n := len(payload)
+	if adaptation == nil && n >= 184 {
+		n = 184
+	} else {
+		if n > 183-len(adaptation) {
+			n = 183 - len(adaptation)
+		}
+		if stuffing := 183 - len(adaptation) - n; stuffing > 0 {
+			if adaptation == nil {
+				adaptation = []byte{0}
+				stuffing--
+			}
+			adaptation = append(adaptation, bytes.Repeat([]byte{0xFF}, stuffing)...)
+		}
+	}
+	switch {
+	case adaptation == nil:
+		packet[3] |= 0x10
+	case n == 0:
+		packet[3] |= 0x20
+	default:
+		packet[3] |= 0x30
+	}
+	if n > 0 {
+		// Packets without payload do not count.
+		packet[3] |= w.continuity[pid]
+		w.continuity[pid] = (w.continuity[pid] + 1) & 15
+	}
+	if adaptation != nil {
+		packet = append(append(packet, byte(len(adaptation))), adaptation...)
+	}
+	w.out = append(append(w.out, packet...), payload[:n]...)
+	return n
+}
+
+// section writes a transport packet of "pid" that carries the PSI section
+// "table" with the body "body". The CRC is left zero, as the demuxer does not
+// check it.
+func (w *tsWriter) section(pid int, table byte, body []byte) {
+	length := 5 + len(body) + 4
+	section := []byte{0, table, 0xB0 | byte(length>>8), byte(length), 0, 1, 0xC1, 0, 0}
+	section = append(section, body...)
+	w.packet(pid, true, -1, append(section, 0, 0, 0, 0))
+}
+
+// The PIDs used by "toTS": the PMT, the clock references, and the streams in
+// the order of the system header.
+const (
+	tsTestPmtPid    = 0x10
+	tsTestPcrPid    = 0x1FF
+	tsTestStreamPid = 0x100
+)
+
+// toTS packetizes the MPEG2 program stream "ps" as a transport stream. The SCR
+// of each pack is written as a clock reference where the pack was. The PAT and
+// the PMT come first, and are repeated every 40 PES packets. If "unbounded" is true, video PES
+// packets are written with a length of 0.
+//
+// It also returns the mux rate that the first two clock references give, in
+// bytes per second.
+func toTS(t testing.TB, ps []byte, unbounded bool) (ts []byte, muxRate int) {
+	t.Helper()
+	system := bytes.Index(ps, []byte{0x00, 0x00, 0x01, 0xBB})
+	if system == -1 {
+		t.Fatal("the program stream has no system header")
+	}
+	var pmt []byte
+	pmt = append(pmt, 0xE0|tsTestPcrPid>>8, tsTestPcrPid&0xFF, 0xF0, 0)
+	pids := map[byte]int{}
+	streams := ps[system+12 : system+6+(int(ps[system+4])<<8|int(ps[system+5]))]
+	for k := 0; k+3 <= len(streams); k += 3 {
+		id := streams[k]
+		pids[id] = tsTestStreamPid + k/3
+		var streamType byte = plm_demux_ts_stream_mpeg2_audio
+		if id >= 0xE0 {
+			streamType = plm_demux_ts_stream_mpeg2_video
+		}
+		pmt = append(pmt, streamType, 0xE0|byte(pids[id]>>8), byte(pids[id]), 0xF0, 0)
+	}
+
+	var w tsWriter
+	var pcrAt, pcrs []int
+	packets := 0
+	psi := func() {
+		w.section(0, 0x00, []byte{0, 1, 0xE0 | tsTestPmtPid>>8, tsTestPmtPid & 0xFF})
+		w.section(tsTestPmtPid, 0x02, pmt)
+	}
+	psi()
+	for i := 0; i+4 <= len(ps); {
+		code := ps[i+3]
+		switch {
+		case code == 0xBA:
+			h := ps[i+4:]
+			scr := readBits(h, 0, 2, 3)<<30 | readBits(h, 0, 6, 15)<<15 | readBits(h, 0, 22, 15)
+			pcrAt, pcrs = append(pcrAt, len(w.out)), append(pcrs, scr)
+			w.packet(tsTestPcrPid, false, int64(scr), nil)
+			i += 4 + 10 + int(h[9]&7)
+		case code == 0xB9:
+			i += 4
+		default:
+			n := int(ps[i+4])<<8 | int(ps[i+5])
+			pes := append([]byte(nil), ps[i:i+6+n]...)
+			i += 6 + n
+			if code < 0xC0 {
+				continue
+			}
+			if packets++; packets%40 == 0 {
+				psi()
+			}
+			if unbounded && code >= 0xE0 {
+				pes[4], pes[5] = 0, 0
+			}
+			for start := true; len(pes) > 0; start = false {
+				pes = pes[w.packet(pids[code], start, -1, pes):]
+			}
+		}
+	}
+	if len(pcrs) < 2 {
+		t.Fatalf("the program stream has %d packs, want at least 2", len(pcrs))
+	}
+	seconds := float64(pcrs[1]-pcrs[0]) / 90000
+	return w.out, int(float64(pcrAt[1]-pcrAt[0])/seconds/50) * 50
+}
+
+// TestTransportStream plays the streams of the test file from a transport
+// stream, and checks that it plays like the program stream it was made from.
+func TestTransportStream(t *testing.T) {
+	ps := toMPEG2PS(t, readTestFile(t, testFile))
+	want, err := NewPlayerFromBytes(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantInfo := want.Info()
+	wantFrames := decodeFrames(t, want)
+	var wantSamples [2][]float32
+	for stream := range wantSamples {
+		plm, err := NewPlayerFromBytes(ps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plm.SetAudioStream(stream)
+		wantSamples[stream] = decodeSamples(t, plm)
+	}
+
+	for _, unbounded := range []bool{false, true} {
+		ts, muxRate := toTS(t, ps, unbounded)
+		for _, source := range []string{"bytes", "reader"} {
+			name := source
+			if unbounded {
+				name += ", unbounded video packets"
+			}
+			open := func() *Player {
+				var plm *Player
+				var err error
+				if source == "bytes" {
+					plm, err = NewPlayerFromBytes(ts)
+				} else {
+					plm, err = NewPlayerFromReader(struct{ io.Reader }{bytes.NewReader(ts)})
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				return plm
+			}
+
+			plm := open()
+			info := plm.Info()
+			if !reflect.DeepEqual(info.StreamIDs, wantInfo.StreamIDs) {
+				t.Errorf("%s: stream IDs are % X, want % X", name, info.StreamIDs, wantInfo.StreamIDs)
+			}
+			if info.Width != wantInfo.Width || info.Height != wantInfo.Height ||
+				info.FrameRate != wantInfo.FrameRate || info.SampleRate != wantInfo.SampleRate {
+				t.Errorf("%s: info is %+v, want %+v", name, info, wantInfo)
+			}
+			if info.MuxRate != muxRate {
+				t.Errorf("%s: mux rate is %d, want %d", name, info.MuxRate, muxRate)
+			}
+			if source == "bytes" {
+				if info.StartTime != wantInfo.StartTime || plm.Duration() != want.Duration() {
+					t.Errorf("%s: start time and duration are %v and %v, want %v and %v",
+						name, info.StartTime, plm.Duration(), wantInfo.StartTime, want.Duration())
+				}
+			} else if plm.Duration() != UnknownDuration {
+				t.Errorf("%s: duration is %v, want %v", name, plm.Duration(), UnknownDuration)
+			}
+
+			frames := decodeFrames(t, plm)
+			if !reflect.DeepEqual(frames, wantFrames) {
+				t.Errorf("%s: decoded %d frames unlike the %d of the program stream", name, len(frames), len(wantFrames))
+			}
+			for stream := range wantSamples {
+				// Packets of the second stream that were read before
+				// switching to it can only be found again in a file.
+				if stream != 0 && source != "bytes" {
+					break
+				}
+				plm = open()
+				plm.SetAudioStream(stream)
+				if samples := decodeSamples(t, plm); !reflect.DeepEqual(samples, wantSamples[stream]) {
+					t.Errorf("%s: decoded %d samples of stream %d unlike the %d of the program stream",
+						name, len(samples), stream, len(wantSamples[stream]))
+				}
+			}
+
+			if source == "bytes" {
+				plm = open()
+				if err := plm.Seek(time.Second, true); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if f := plm.Frame(); f == nil || frameHash(f) != wantFrames[testFrameRate] {
+					t.Errorf("%s: seeking to 1s did not give frame %d", name, testFrameRate)
+				}
+			}
+		}
+	}
+}
+
+func TestDemuxTransportStreamShortPES(t *testing.T) {
+	// The PES length of 1 or 2 cuts the packet short of its optional header.
+	for _, length := range []byte{1, 2} {
+		// Program 1 with its PMT on PID 0x10, and MPEG1 video on PID 0x100.
+		var w tsWriter
+		w.section(0, 0x00, []byte{0, 1, 0xE0, 0x10})
+		w.section(0x10, 0x02, []byte{0xE1, 0x00, 0xF0, 0, plm_demux_ts_stream_mpeg1_video, 0xE1, 0x00, 0xF0, 0})
+		w.packet(0x100, true, -1, []byte{0, 0, 1, 0xE0, 0, length, 0x80, 0, 0, 0xAA, 0xAA})
+		w.packet(0x100, true, -1, []byte{0, 0, 1, 0xE0, 0, 8, 0x80, 0, 0, 1, 2, 3, 4, 5})
+		ts := w.out
+
+		buffer := plm_buffer_create_with_memory(&ts[0], uint64(len(ts)), _false)
+		demux := plm_demux_create(buffer, _true)
+		packet := plm_demux_decode(demux)
+		if demux.Error != plm_error_demux_packet_header {
+			t.Errorf("PES length %d: error is %d, want %d", length, demux.Error, plm_error_demux_packet_header)
+		}
+		if demux.Error_offset != 2*plm_demux_ts_packet_size {
+			t.Errorf("PES length %d: error offset is %d, want %d", length, demux.Error_offset, 2*plm_demux_ts_packet_size)
+		}
+		if packet == nil || packet.Type != plm_demux_packet_video_1 {
+			t.Fatalf("PES length %d: the next packet was not decoded", length)
+		}
+		if got := unsafe.Slice(packet.Data, packet.Length); !bytes.Equal(got, []byte{1, 2, 3, 4, 5}) {
+			t.Errorf("PES length %d: payload of the next packet is % X, want 01 02 03 04 05", length, got)
+		}
+		plm_demux_destroy(demux)
+	}
+}
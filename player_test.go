@@ -0,0 +1,59 @@
+package mpg
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPlayerConcurrentUse reads audio on one goroutine, like an audio player
+// pulling from "Read", while another decodes, seeks and switches audio
+// streams. Run it with "go test -race" to check the locking of "Player".
+func TestPlayerConcurrentUse(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plm.SetLoop(true)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		buf := make([]byte, 4096)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := plm.Read(buf); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		plm.Decode(time.Second / testFrameRate)
+		switch i % 50 {
+		case 10:
+			if err := plm.Seek(time.Second, true); err != nil {
+				t.Error(err)
+			}
+		case 20:
+			plm.ClearAudioBuffer()
+		case 30:
+			if err := plm.SetAudioStream((i / 50) % 2); err != nil {
+				t.Error(err)
+			}
+		case 40:
+			if f := plm.Frame(); f == nil {
+				t.Error("no frame after decoding")
+			}
+		}
+	}
+	close(done)
+	wg.Wait()
+}
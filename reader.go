@@ -0,0 +1,131 @@
+package mpg
+
+import (
+	"io"
+	"os"
+	"time"
+	"unsafe"
+
+	"github.com/gotranspile/cxgo/runtime/stdio"
+)
+
+// UnknownDuration is returned by "Duration" when the length of the video can
+// not be determined, such as when the player reads from a source that can not
+// seek.
+const UnknownDuration time.Duration = -1
+
+// NewPlayerFromReader creates a new player that reads from "r".
+//
+// If "r" is also an "io.Seeker" that is able to seek, this is the same as
+// "NewPlayerFromReadSeeker": "r" is rewound, and the video is read from offset
+// 0. Otherwise the video can only be played forward once: "Duration" returns
+// "UnknownDuration", and seeking, rewinding and looping are not possible.
+//
+// Data is read from "r" as it is needed, so a slow reader, such as a live feed,
+// only stalls decoding until the next packet has arrived.
+//
+// If "r" is an "io.Closer", it is not closed when the player is closed.
+func NewPlayerFromReader(r io.Reader) (*Player, error) {
+	buffer, src := newReaderBuffer(r)
+	plm, err := newPlayer(plm_create_with_buffer(buffer, _true))
+	if err != nil {
+		return nil, err
+	}
+	plm.seekable = src == nil
+	plm.reader = src
+	return plm, nil
+}
+
+// NewPlayerFromReadSeeker creates a new player that reads from "rs". The video
+// is expected to start at offset 0 of "rs".
+//
+// If "rs" is an "io.Closer", it is not closed when the player is closed.
+func NewPlayerFromReadSeeker(rs io.ReadSeeker) (*Player, error) {
+	p := plm_create_with_file(stdio.OpenFrom(&readSeekerFile{rs}), _true)
+	return newPlayer(p)
+}
+
+// newReaderBuffer creates a buffer that reads from "r". If "r" is able to seek,
+// it is read like a file and "src" is nil. Otherwise "src" feeds a ring buffer
+// that can only be read forward.
+func newReaderBuffer(r io.Reader) (buffer *plm_buffer_t, src *readerSource) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		// Some readers, such as pipes opened as an "*os.File", implement
+		// "io.Seeker" but fail to actually seek.
+		if _, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			return plm_buffer_create_with_file(stdio.OpenFrom(&readSeekerFile{rs}), _true), nil
+		}
+	}
+	src = &readerSource{r: r}
+	buffer = plm_buffer_create_with_capacity(128 * 1024)
+	plm_buffer_set_load_callback(buffer, func(self *plm_buffer_t, user unsafe.Pointer) {
+		src.load(self)
+	}, nil)
+	return buffer, src
+}
+
+// readerSource feeds a ring buffer from a reader that can not seek. The
+// "StreamPlayer" of "NewLiveStreamPlayer" uses one without a reader, only to
+// count the data it dropped.
+type readerSource struct {
+	r io.Reader
+	// discarded is how many bytes were dropped from the start of the buffer,
+	// so offsets in the buffer can be turned into offsets in the reader.
+	discarded int64
+}
+
+// load fills the buffer from the reader. It is the counterpart of
+// "plm_buffer_load_file_callback".
+func (src *readerSource) load(self *plm_buffer_t) {
+	if self.Discard_read_bytes != 0 {
+		length := self.Length
+		plm_buffer_discard_read_bytes(self)
+		src.discarded += int64(length - self.Length)
+	}
+	bytesAvailable := int(self.Capacity - self.Length)
+	if bytesAvailable == 0 {
+		return
+	}
+	dst := unsafe.Slice((*byte)(unsafe.Add(unsafe.Pointer(self.Bytes), self.Length)), bytesAvailable)
+	// A short read is fine, "plm_buffer_has" loads again until it has what it
+	// needs. Only a read that returns nothing at all is retried here.
+	var n int
+	var err error
+	for n == 0 && err == nil {
+		n, err = src.r.Read(dst)
+	}
+	self.Length += uint64(n)
+	if err != nil {
+		// Like "plm_buffer_load_file_callback", the buffer has ended once
+		// the source has nothing more to give.
+		self.Has_ended = _true
+	}
+}
+
+// readSeekerFile lets cxgo's stdio treat an "io.ReadSeeker" like an
+// "*os.File", so it can be read and seeked by "plm_buffer_load_file_callback".
+type readSeekerFile struct {
+	io.ReadSeeker
+}
+
+// Fd returns a unique identifier stdio uses to keep track of open files.
+func (f *readSeekerFile) Fd() uintptr { return uintptr(unsafe.Pointer(f)) }
+
+func (f *readSeekerFile) Name() string { return "" }
+
+func (f *readSeekerFile) Sync() error { return nil }
+
+func (f *readSeekerFile) Write(p []byte) (int, error) { return 0, os.ErrPermission }
+
+// Close does nothing. The underlying reader is owned by the caller.
+func (f *readSeekerFile) Close() error { return nil }
+
+// Read fills "p" as far as possible, since "plm_buffer_has" only calls the
+// load callback once before it gives up.
+func (f *readSeekerFile) Read(p []byte) (int, error) {
+	n, err := io.ReadFull(f.ReadSeeker, p)
+	if err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
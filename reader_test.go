@@ -0,0 +1,107 @@
+package mpg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestNewPlayerFromReaderShortReads(t *testing.T) {
+	data := readTestFile(t, testFile)
+	want, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "iotest.OneByteReader" hides the "io.Seeker" of the reader, and returns
+	// a single byte per read.
+	plm, err := NewPlayerFromReader(iotest.OneByteReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plm.Seekable() || plm.Duration() != UnknownDuration {
+		t.Error("player reading from a reader that can not seek is seekable")
+	}
+	if err := plm.Seek(time.Second, false); err != ErrNotSeekable {
+		t.Errorf("seeking a player that can not seek returned %v, want ErrNotSeekable", err)
+	}
+	wantFrames, gotFrames := decodeFrames(t, want), decodeFrames(t, plm)
+	if len(gotFrames) != testFrames || len(gotFrames) != len(wantFrames) {
+		t.Fatalf("decoded %d frames, want %d", len(gotFrames), len(wantFrames))
+	}
+	for i := range wantFrames {
+		if gotFrames[i] != wantFrames[i] {
+			t.Fatalf("frame %d differs", i)
+		}
+	}
+}
+
+func TestNewPlayerFromReadSeeker(t *testing.T) {
+	data := readTestFile(t, testFile)
+	want, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Readers that can seek are not read from the start.
+	r := bytes.NewReader(data)
+	r.Seek(100, io.SeekStart)
+	plm, err := NewPlayerFromReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plm.Seekable() || plm.Duration() != want.Duration() {
+		t.Errorf("player is seekable: %v, with a duration of %v, want true and %v", plm.Seekable(), plm.Duration(), want.Duration())
+	}
+	wantFrames := decodeFrames(t, want)
+	if err := plm.Seek(time.Second, true); err != nil {
+		t.Fatal(err)
+	}
+	// Seeking decoded the frame at 1s, the next one follows it.
+	gotFrames := decodeFrames(t, plm)
+	if len(gotFrames) != testFrames-testFrameRate-1 {
+		t.Fatalf("decoded %d frames after seeking to 1s, want %d", len(gotFrames), testFrames-testFrameRate-1)
+	}
+	for i := range gotFrames {
+		if n := testFrameRate + 1 + i; gotFrames[i] != wantFrames[n] {
+			t.Fatalf("frame %d differs", n)
+		}
+	}
+}
+
+func TestNewPlayerFromReaderSlowSource(t *testing.T) {
+	data := readTestFile(t, testFile)
+	r, w := io.Pipe()
+	defer r.Close()
+	// Only the first half of the video arrives until the first frame was
+	// decoded. The player must not wait for a full buffer before decoding.
+	decoded := make(chan struct{})
+	go func() {
+		w.Write(data[:len(data)/2])
+		<-decoded
+		w.Write(data[len(data)/2:])
+		w.Close()
+	}()
+	done := make(chan error, 1)
+	var plm *Player
+	go func() {
+		var err error
+		if plm, err = NewPlayerFromReader(r); err == nil && plm_decode_video(plm.plm) == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("decoding blocked until the source was read completely")
+	}
+	close(decoded)
+	frames := 1 + len(decodeFrames(t, plm))
+	if frames != testFrames {
+		t.Errorf("decoded %d frames, want %d", frames, testFrames)
+	}
+}
@@ -0,0 +1,158 @@
+package mpg
+
+import "math"
+
+// ResampleQuality is the algorithm used to convert audio to the output sample
+// rate set with "SetOutputSampleRate".
+type ResampleQuality int
+
+const (
+	// ResampleLinear interpolates linearly between samples. It is fast, but
+	// dulls high frequencies and lets some aliasing through.
+	ResampleLinear ResampleQuality = iota
+	// ResampleSinc uses a windowed sinc filter, which keeps high frequencies
+	// and filters out aliasing when downsampling. It is slower than
+	// "ResampleLinear".
+	ResampleSinc
+)
+
+const (
+	// sincTaps is the number of input samples on each side of an output sample
+	// that the sinc filter looks at.
+	sincTaps = 16
+	// sincResolution is the number of entries per input sample in the
+	// precomputed filter table.
+	sincResolution = 256
+)
+
+// resampler converts interleaved stereo samples from one sample rate to
+// another. Input is fed in as it is decoded, and the last few input samples
+// are kept so the filter continues smoothly across audio frames.
+type resampler struct {
+	quality ResampleQuality
+	inRate  int
+	outRate int
+	// step is how far to move in the input for each output sample.
+	step float64
+	// pos is the position of the next output sample, in sample frames from
+	// the start of "history".
+	pos     float64
+	history []float32
+	out     []float32
+	// table holds the filter for "ResampleSinc", from 0 to "sincTaps" input
+	// samples away from the output sample.
+	table []float32
+}
+
+// taps is the number of input samples needed on each side of an output
+// sample.
+func (r *resampler) taps() int {
+	if r.quality == ResampleSinc {
+		return sincTaps
+	}
+	return 1
+}
+
+// configure prepares the resampler for new rates or a new quality. It resets
+// the resampler if anything changed.
+func (r *resampler) configure(quality ResampleQuality, inRate, outRate int) {
+	if r.quality == quality && r.inRate == inRate && r.outRate == outRate {
+		return
+	}
+	r.quality, r.inRate, r.outRate = quality, inRate, outRate
+	r.step = float64(inRate) / float64(outRate)
+	r.table = nil
+	if quality == ResampleSinc {
+		r.table = sincTable(r.step)
+	}
+	r.reset()
+}
+
+// reset discards the kept input, such as after seeking.
+func (r *resampler) reset() {
+	// The first output sample lands on the first input sample, with silence
+	// before it.
+	taps := r.taps()
+	r.history = append(r.history[:0], make([]float32, (taps-1)*2)...)
+	r.pos = float64(taps - 1)
+}
+
+// resample consumes "in" and returns the samples that could be produced so
+// far. The returned slice is only valid until the next call.
+func (r *resampler) resample(in []float32) []float32 {
+	r.history = append(r.history, in...)
+	frames := len(r.history) / 2
+	taps := r.taps()
+	r.out = r.out[:0]
+	for {
+		i := int(r.pos)
+		if i+taps >= frames {
+			break
+		}
+		frac := r.pos - float64(i)
+		var left, right float32
+		if r.quality == ResampleSinc {
+			left, right = r.sinc(i, frac)
+		} else {
+			a, b := r.history[i*2:i*2+2], r.history[i*2+2:i*2+4]
+			t := float32(frac)
+			left = a[0] + (b[0]-a[0])*t
+			right = a[1] + (b[1]-a[1])*t
+		}
+		r.out = append(r.out, left, right)
+		r.pos += r.step
+	}
+	// Drop input that no later output sample needs.
+	if drop := int(r.pos) - (taps - 1); drop > 0 {
+		n := copy(r.history, r.history[drop*2:])
+		r.history = r.history[:n]
+		r.pos -= float64(drop)
+	}
+	return r.out
+}
+
+// sinc filters the input around sample frame "i" plus "frac".
+func (r *resampler) sinc(i int, frac float64) (left, right float32) {
+	for k := -sincTaps + 1; k <= sincTaps; k++ {
+		x := math.Abs(float64(k) - frac)
+		w := r.weight(x)
+		s := r.history[(i+k)*2:]
+		left += s[0] * w
+		right += s[1] * w
+	}
+	return
+}
+
+// weight looks up the filter "x" input samples away from the output sample.
+func (r *resampler) weight(x float64) float32 {
+	x *= sincResolution
+	j := int(x)
+	if j+1 >= len(r.table) {
+		return 0
+	}
+	t := float32(x - float64(j))
+	return r.table[j] + (r.table[j+1]-r.table[j])*t
+}
+
+// sincTable computes a Blackman windowed sinc filter. When downsampling, the
+// cutoff is lowered to the output's Nyquist frequency to filter out aliasing.
+func sincTable(step float64) []float32 {
+	cutoff := 1.0
+	if step > 1 {
+		cutoff = 1 / step
+	}
+	table := make([]float32, sincTaps*sincResolution+2)
+	for j := range table {
+		x := float64(j) / sincResolution
+		if x >= sincTaps {
+			continue
+		}
+		w := cutoff
+		if x > 0 {
+			w = math.Sin(math.Pi*x*cutoff) / (math.Pi * x)
+		}
+		n := math.Pi * (x/sincTaps + 1)
+		table[j] = float32(w * (0.42 - 0.5*math.Cos(n) + 0.08*math.Cos(2*n)))
+	}
+	return table
+}
@@ -0,0 +1,123 @@
+package mpg
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+// dominantFrequency returns the frequency between "from" and "to" Hz with the
+// most energy in "samples", in steps of "step" Hz.
+func dominantFrequency(samples []float32, rate int, from, to, step float64) float64 {
+	best, bestPower := 0.0, -1.0
+	for f := from; f <= to; f += step {
+		// Goertzel filter
+		coeff := 2 * math.Cos(2*math.Pi*f/float64(rate))
+		var s1, s2 float64
+		for _, s := range samples {
+			s1, s2 = float64(s)+coeff*s1-s2, s1
+		}
+		if power := s1*s1 + s2*s2 - coeff*s1*s2; power > bestPower {
+			best, bestPower = f, power
+		}
+	}
+	return best
+}
+
+func TestResampler(t *testing.T) {
+	const inRate, outRate, freq = 44100, 48000, 1000
+	in := make([]float32, inRate*2)
+	for i := 0; i < inRate; i++ {
+		s := float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/inRate))
+		in[i*2], in[i*2+1] = s, -s
+	}
+	for _, quality := range []ResampleQuality{ResampleLinear, ResampleSinc} {
+		var r resampler
+		r.configure(quality, inRate, outRate)
+		// Feed the input in chunks of an MP2 frame, like the decoder does.
+		var out []float32
+		for i := 0; i < len(in); i += plm_audio_samples_per_frame * 2 {
+			end := i + plm_audio_samples_per_frame*2
+			if end > len(in) {
+				end = len(in)
+			}
+			out = append(out, r.resample(in[i:end])...)
+		}
+		// Output lags behind the input by the samples the filter looks ahead.
+		frames := len(out) / 2
+		want := float64(len(in)/2) * outRate / inRate
+		if float64(frames) > want || float64(frames) < want-float64(r.taps())*outRate/inRate-1 {
+			t.Errorf("quality %d: resampled %d to %d samples, want about %.0f", quality, len(in)/2, frames, want)
+		}
+
+		left, right := make([]float32, frames), make([]float32, frames)
+		for i := range left {
+			left[i], right[i] = out[i*2], out[i*2+1]
+			if left[i] != -right[i] {
+				t.Fatalf("quality %d: channels of sample %d are mixed", quality, i)
+			}
+		}
+		if f := dominantFrequency(left, outRate, 500, 2000, 2); f != freq {
+			t.Errorf("quality %d: dominant frequency is %v Hz, want %v Hz", quality, f, freq)
+		}
+		// Past the silence the filter starts with, the tone keeps its level.
+		var peak float32
+		for _, s := range left[outRate/10:] {
+			if s > peak {
+				peak = s
+			}
+		}
+		if peak < 0.49 || peak > 0.51 {
+			t.Errorf("quality %d: peak is %v, want 0.5", quality, peak)
+		}
+	}
+}
+
+// TestResamplerResets checks that audio kept by the resampler is discarded when
+// the player seeks or rewinds, so it does not blend into audio after it.
+func TestResamplerResets(t *testing.T) {
+	plm, err := NewPlayerFromBytes(readTestFile(t, testFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plm.SetOutputSampleRate(48000)
+	var fresh resampler
+	fresh.configure(plm.ResampleQuality(), plm.SampleRate(), 48000)
+	isReset := func() bool {
+		r := &plm.resampler
+		return r.pos == fresh.pos && len(r.history) == len(fresh.history) &&
+			string(float32Bytes(r.history)) == string(float32Bytes(fresh.history))
+	}
+	for _, c := range []struct {
+		name string
+		move func()
+	}{
+		{"Seek", func() {
+			// Seeking decodes audio at the new position unless audio is
+			// disabled.
+			plm.SetAudioEnabled(false)
+			if err := plm.Seek(time.Second/2, true); err != nil {
+				t.Fatal(err)
+			}
+			plm.SetAudioEnabled(true)
+		}},
+		{"Rewind", plm.Rewind},
+		{"ClearAudioBuffer", plm.ClearAudioBuffer},
+	} {
+		plm.Decode(time.Second / 4)
+		if isReset() {
+			t.Fatalf("%s: resampler is not in use", c.name)
+		}
+		c.move()
+		if !isReset() {
+			t.Errorf("%s: resampler was not reset", c.name)
+		}
+	}
+}
+
+func float32Bytes(s []float32) []byte {
+	var buf bytes.Buffer
+	SampleF32LE.writeSamples(&buf, s)
+	return buf.Bytes()
+}
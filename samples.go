@@ -0,0 +1,76 @@
+package mpg
+
+import (
+	"bytes"
+	"math"
+)
+
+// SampleFormat is the encoding of audio samples written to the audio buffer
+// read by "Read". Samples of the left and right channel are interleaved.
+type SampleFormat int
+
+const (
+	// SampleF32LE is 32-bit little-endian floating point, ranging from -1 to 1.
+	SampleF32LE SampleFormat = iota
+	// SampleS16LE is 16-bit signed little-endian integers, used by Ebiten.
+	SampleS16LE
+	// SampleS24LE is packed 24-bit signed little-endian integers.
+	SampleS24LE
+	// SampleS32LE is 32-bit signed little-endian integers.
+	SampleS32LE
+	// SampleU8 is 8-bit unsigned integers, with silence at 0x80.
+	SampleU8
+)
+
+// BytesPerSample is how many bytes each sample of a single channel takes up in
+// this format.
+func (format SampleFormat) BytesPerSample() int {
+	switch format {
+	case SampleS16LE:
+		return 2
+	case SampleS24LE:
+		return 3
+	case SampleU8:
+		return 1
+	}
+	return 4
+}
+
+// writeSamples encodes "samples" to "buf". Samples outside of -1 to 1 are
+// clipped so they do not overflow, and NaN is written as silence.
+func (format SampleFormat) writeSamples(buf *bytes.Buffer, samples []float32) {
+	var b [4]byte
+	n := format.BytesPerSample()
+	for _, s := range samples {
+		if s != s {
+			// NaN
+			s = 0
+		} else if s > 1 {
+			s = 1
+		} else if s < -1 {
+			s = -1
+		}
+		switch format {
+		case SampleF32LE:
+			putUint32(b[:], math.Float32bits(s))
+		case SampleS16LE:
+			putUint32(b[:], uint32(int32(s*0x7FFF)))
+		case SampleS24LE:
+			putUint32(b[:], uint32(int32(s*0x7FFFFF)))
+		case SampleS32LE:
+			// float32 can not hold 0x7FFFFFFF, which would round up and
+			// overflow.
+			putUint32(b[:], uint32(int32(float64(s)*0x7FFFFFFF)))
+		case SampleU8:
+			b[0] = byte(int32(s*0x7F) + 0x80)
+		}
+		buf.Write(b[:n])
+	}
+}
+
+func putUint32(b []byte, v uint32) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+}
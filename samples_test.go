@@ -0,0 +1,116 @@
+package mpg
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWriteSamples(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(1))
+	samples := []float32{0, 0.5, -0.5, 1, -1, 1.5, -1.5, inf, -inf, nan}
+	for _, c := range []struct {
+		format SampleFormat
+		want   [][]byte
+	}{
+		{SampleF32LE, [][]byte{
+			{0x00, 0x00, 0x00, 0x00},
+			{0x00, 0x00, 0x00, 0x3F},
+			{0x00, 0x00, 0x00, 0xBF},
+			{0x00, 0x00, 0x80, 0x3F},
+			{0x00, 0x00, 0x80, 0xBF},
+			{0x00, 0x00, 0x80, 0x3F},
+			{0x00, 0x00, 0x80, 0xBF},
+			{0x00, 0x00, 0x80, 0x3F},
+			{0x00, 0x00, 0x80, 0xBF},
+			{0x00, 0x00, 0x00, 0x00},
+		}},
+		{SampleS16LE, [][]byte{
+			{0x00, 0x00},
+			{0xFF, 0x3F},
+			{0x01, 0xC0},
+			{0xFF, 0x7F},
+			{0x01, 0x80},
+			{0xFF, 0x7F},
+			{0x01, 0x80},
+			{0xFF, 0x7F},
+			{0x01, 0x80},
+			{0x00, 0x00},
+		}},
+		{SampleS24LE, [][]byte{
+			{0x00, 0x00, 0x00},
+			{0xFF, 0xFF, 0x3F},
+			{0x01, 0x00, 0xC0},
+			{0xFF, 0xFF, 0x7F},
+			{0x01, 0x00, 0x80},
+			{0xFF, 0xFF, 0x7F},
+			{0x01, 0x00, 0x80},
+			{0xFF, 0xFF, 0x7F},
+			{0x01, 0x00, 0x80},
+			{0x00, 0x00, 0x00},
+		}},
+		{SampleS32LE, [][]byte{
+			{0x00, 0x00, 0x00, 0x00},
+			{0xFF, 0xFF, 0xFF, 0x3F},
+			{0x01, 0x00, 0x00, 0xC0},
+			{0xFF, 0xFF, 0xFF, 0x7F},
+			{0x01, 0x00, 0x00, 0x80},
+			{0xFF, 0xFF, 0xFF, 0x7F},
+			{0x01, 0x00, 0x00, 0x80},
+			{0xFF, 0xFF, 0xFF, 0x7F},
+			{0x01, 0x00, 0x00, 0x80},
+			{0x00, 0x00, 0x00, 0x00},
+		}},
+		{SampleU8, [][]byte{
+			{0x80},
+			{0xBF},
+			{0x41},
+			{0xFF},
+			{0x01},
+			{0xFF},
+			{0x01},
+			{0xFF},
+			{0x01},
+			{0x80},
+		}},
+	} {
+		var buf bytes.Buffer
+		c.format.writeSamples(&buf, samples)
+		if want := len(samples) * c.format.BytesPerSample(); buf.Len() != want {
+			t.Errorf("format %d: wrote %d bytes, want %d", c.format, buf.Len(), want)
+			continue
+		}
+		for i, want := range c.want {
+			got := buf.Next(c.format.BytesPerSample())
+			if !bytes.Equal(got, want) {
+				t.Errorf("format %d: sample %v is % X, want % X", c.format, samples[i], got, want)
+			}
+		}
+	}
+}
+
+func TestPlayerSampleFormat(t *testing.T) {
+	data := readTestFile(t, testFile)
+	var buffered []int
+	for format := SampleF32LE; format <= SampleU8; format++ {
+		plm, err := NewPlayerFromBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		plm.SetSampleFormat(format)
+		plm.SetSampleFormat(SampleU8 + 1)
+		if plm.SampleFormat() != format {
+			t.Fatalf("unknown sample format replaced %d with %d", format, plm.SampleFormat())
+		}
+		plm.Decode(time.Second)
+		buffered = append(buffered, plm.audioBuffer.Len()/format.BytesPerSample())
+	}
+	// The same samples are buffered in every format.
+	for format, n := range buffered {
+		if n == 0 || n != buffered[0] {
+			t.Errorf("format %d buffered %d samples, want %d", format, n, buffered[0])
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package mpg
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNeedMoreData is returned by "NextFrame" and "NextSamples" of a
+// "StreamPlayer" when all data written so far was decoded.
+var ErrNeedMoreData = errors.New("mpg: need more data")
+
+// StreamPlayer is a "Player" that decodes data while it is being written to
+// it, such as a live feed arriving from a socket or a pipe.
+//
+// A player created by "NewStreamPlayer" keeps all written data, so it can seek,
+// rewind and loop within the data that was written so far. Its memory grows
+// with every byte written, so feeds that run indefinitely should use
+// "NewLiveStreamPlayer" instead, which drops data once it was decoded. Until
+// "CloseWrite" is called, "Duration" returns "UnknownDuration" and reaching the
+// end of the written data does not finish the player, it waits for more data
+// instead.
+type StreamPlayer struct {
+	*Player
+	buffer     *plm_buffer_t
+	hasHeaders bool
+	closed     bool
+}
+
+// NewStreamPlayer creates a new player that decodes data written to it. Unlike
+// other players, it can be created before any data is available. Properties of
+// the video, such as "Width", "SampleRate" or "HasAudio", are only known once
+// "HasHeaders" returns true.
+func NewStreamPlayer() *StreamPlayer {
+	buffer := plm_buffer_create_for_appending(128 * 1024)
+	plm := &StreamPlayer{
+		Player: initPlayer(plm_create_with_buffer(buffer, _true)),
+		buffer: buffer,
+	}
+	plm.stream = plm
+	return plm
+}
+
+// NewLiveStreamPlayer creates a new player like "NewStreamPlayer", except that
+// written data is dropped once the demuxer has read past it, so memory only
+// holds data that was not decoded yet. Like a player reading from a reader that
+// can not seek, it can not seek, rewind or loop, and "Duration" and
+// "BufferedDuration" return "UnknownDuration".
+func NewLiveStreamPlayer() *StreamPlayer {
+	plm := NewStreamPlayer()
+	plm.buffer.Discard_read_bytes = _true
+	plm.seekable = false
+	// Offsets of decode errors count the dropped data, like those of a
+	// player reading from a reader.
+	plm.reader = &readerSource{}
+	return plm
+}
+
+// Write appends "data" to the stream. It returns "io.ErrClosedPipe" if
+// "CloseWrite" was already called.
+func (plm *StreamPlayer) Write(data []byte) (n int, err error) {
+	defer plm.lock()()
+	if plm.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	length := plm.buffer.Length
+	plm_buffer_write(plm.buffer, bytesToUintPtr(data), uint64(len(data)))
+	if plm.reader != nil {
+		plm.reader.discarded += int64(length + uint64(len(data)) - plm.buffer.Length)
+	}
+	plm.checkHeaders()
+	plm.collectErrors()
+	return len(data), nil
+}
+
+// CloseWrite signals that no more data will be written. The player finishes
+// once all data was decoded and "Duration" becomes available.
+func (plm *StreamPlayer) CloseWrite() error {
+	defer plm.lock()()
+	if !plm.closed {
+		plm.closed = true
+		plm_buffer_signal_end(plm.buffer)
+	}
+	return nil
+}
+
+// HasHeaders returns true once enough data was written to know the properties
+// of the video and audio streams.
+func (plm *StreamPlayer) HasHeaders() bool { defer plm.lock()(); return plm.checkHeaders() }
+
+func (plm *StreamPlayer) checkHeaders() bool {
+	if !plm.hasHeaders && plm_has_headers(plm.plm) == _true {
+		plm.hasHeaders = true
+		// The audio buffer size depends on the sample rate, which is only
+		// known now.
+		plm.setAudioLeadTime(plm.audioLeadTime())
+	}
+	return plm.hasHeaders
+}
+
+// Decode is like "Player.Decode", except that time does not advance while the
+// player waits for more data to be written. This way no frames are skipped
+// when the data arrives late.
+func (plm *StreamPlayer) Decode(elapsed time.Duration) {
+	defer plm.lock()()
+	previous := plm.plm.Time
+	plm.decode(elapsed)
+	if !plm.closed && plm.starved() {
+		plm.plm.Time = previous
+	}
+}
+
+// starved returns true if the decoders could not catch up with the current
+// time because the data written so far ran out.
+func (plm *StreamPlayer) starved() bool {
+	p := plm.plm
+	if p.Has_decoders == _false {
+		return true
+	}
+	if p.Video_packet_type != 0 && p.Video_decode_callback != nil {
+		return plm_video_get_time(p.Video_decoder) < p.Time
+	}
+	if p.Audio_packet_type != 0 && p.Audio_decode_callback != nil {
+		return plm_audio_get_time(p.Audio_decoder) < p.Time
+	}
+	return false
+}
+
+// Duration is how long the entire video is. This is "UnknownDuration" until
+// "CloseWrite" is called, and always for a player created by
+// "NewLiveStreamPlayer".
+func (plm *StreamPlayer) Duration() time.Duration {
+	defer plm.lock()()
+	if !plm.closed {
+		return UnknownDuration
+	}
+	return plm.duration()
+}
+
+// BufferedDuration is how long the data written so far plays for. Seeking past
+// it fails. It is "UnknownDuration" for a player created by
+// "NewLiveStreamPlayer".
+func (plm *StreamPlayer) BufferedDuration() time.Duration {
+	defer plm.lock()()
+	if !plm.checkHeaders() {
+		return 0
+	}
+	return plm.duration()
+}
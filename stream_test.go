@@ -0,0 +1,141 @@
+package mpg
+
+import (
+	"crypto/sha1"
+	"io"
+	"testing"
+)
+
+// writeStream writes "data" to "plm" in chunks, and returns the hashes of the
+// frames that were decoded while writing. "written" is called after every
+// chunk.
+func writeStream(t testing.TB, plm *StreamPlayer, data []byte, written func()) (frames [][sha1.Size]byte) {
+	t.Helper()
+	const chunk = 4096
+	for i := 0; i < len(data); i += chunk {
+		end := i + chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		if _, err := plm.Write(data[i:end]); err != nil {
+			t.Fatal(err)
+		}
+		if plm.HasHeaders() {
+			frames = append(frames, decodeFrames(t, plm.Player)...)
+		}
+		if written != nil {
+			written()
+		}
+	}
+	plm.CloseWrite()
+	return append(frames, decodeFrames(t, plm.Player)...)
+}
+
+func TestStreamPlayer(t *testing.T) {
+	data := readTestFile(t, testFile)
+	want, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFrames := decodeFrames(t, want)
+
+	plm := NewStreamPlayer()
+	if plm.HasHeaders() {
+		t.Error("stream player has headers before any data was written")
+	}
+	frames := writeStream(t, plm, data, func() {
+		if plm.Duration() != UnknownDuration {
+			t.Error("stream player has a duration before the end was written")
+		}
+	})
+	if len(frames) != len(wantFrames) {
+		t.Fatalf("decoded %d frames, want %d", len(frames), len(wantFrames))
+	}
+	for i := range frames {
+		if frames[i] != wantFrames[i] {
+			t.Fatalf("frame %d differs", i)
+		}
+	}
+	if !plm.Finished() || plm.Duration() != want.Duration() {
+		t.Errorf("player finished: %v, with a duration of %v, want true and %v", plm.Finished(), plm.Duration(), want.Duration())
+	}
+	if _, err := plm.Write(data); err == nil {
+		t.Error("writing after CloseWrite succeeded")
+	}
+	// All data is kept, so the player can seek back.
+	if plm.Seek(0, false) != nil || len(decodeFrames(t, plm.Player)) != testFrames-1 {
+		t.Error("seeking back to the start failed")
+	}
+}
+
+func TestLiveStreamPlayerDropsDecodedData(t *testing.T) {
+	data := readTestFile(t, testFile)
+	want, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFrames := decodeFrames(t, want)
+
+	plm := NewLiveStreamPlayer()
+	if plm.Seekable() {
+		t.Error("live stream player is seekable")
+	}
+	var maxBuffered uint64
+	frames := writeStream(t, plm, data, func() {
+		if plm.buffer.Length > maxBuffered {
+			maxBuffered = plm.buffer.Length
+		}
+	})
+	if len(frames) != len(wantFrames) {
+		t.Fatalf("decoded %d frames, want %d", len(frames), len(wantFrames))
+	}
+	for i := range frames {
+		if frames[i] != wantFrames[i] {
+			t.Fatalf("frame %d differs", i)
+		}
+	}
+	// Only data that was not decoded yet is kept, which is much less than the
+	// whole video.
+	if maxBuffered > uint64(len(data))/2 {
+		t.Errorf("kept up to %d of %d bytes", maxBuffered, len(data))
+	}
+	if plm.Duration() != UnknownDuration || plm.BufferedDuration() != UnknownDuration {
+		t.Error("live stream player has a duration")
+	}
+	if err := plm.Seek(0, false); err != ErrNotSeekable {
+		t.Errorf("seeking a live stream player returned %v, want ErrNotSeekable", err)
+	}
+}
+
+func TestStreamPlayerNeedMoreData(t *testing.T) {
+	data := readTestFile(t, testFile)
+	plm := NewStreamPlayer()
+	plm.Write(data[:len(data)/2])
+	n := 0
+	for {
+		_, err := plm.NextFrame()
+		if err == ErrNeedMoreData {
+			break
+		} else if err != nil {
+			t.Fatalf("frame %d: %v", n, err)
+		}
+		n++
+	}
+	if n == 0 || n >= testFrames {
+		t.Fatalf("decoded %d frames from half the data", n)
+	}
+	plm.Write(data[len(data)/2:])
+	plm.CloseWrite()
+	for {
+		_, err := plm.NextFrame()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("frame %d: %v", n, err)
+		}
+		n++
+	}
+	if n != testFrames {
+		t.Errorf("decoded %d frames, want %d", n, testFrames)
+	}
+}
@@ -5,11 +5,6 @@ import (
 	"unsafe"
 )
 
-func index(data *uint8, i int) byte {
-	ptr := unsafe.Add(unsafe.Pointer(data), unsafe.Sizeof(uint8(0))*uintptr(i))
-	return *(*byte)(ptr)
-}
-
 func bytesToUintPtr(data []byte) *uint8 {
 	return (*uint8)(unsafe.Pointer(&data[0]))
 }
@@ -0,0 +1,256 @@
+package mpg
+
+import (
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gotranspile/cxgo/runtime/stdio"
+)
+
+// VideoDecoder decodes a raw MPEG1 or MPEG2 video stream, such as an ".m1v" or
+// ".m2v" file, that is not wrapped in an MPG container. Use a "Player" for MPG
+// files.
+//
+// A decoder is safe to use from multiple goroutines.
+type VideoDecoder struct {
+	mu       sync.Mutex
+	video    *plm_video_t
+	seekable bool
+	reader   *readerSource
+	lastErr  error
+	frame    *Frame
+
+	// pending is a frame decoded by "Seek", which "NextFrame" returns next.
+	pending *plm_frame_t
+
+	// scanned is true once "scan" has found the duration and "keyframes".
+	scanned   bool
+	duration  time.Duration
+	keyframes []Keyframe
+}
+
+func newVideoDecoder(buffer *plm_buffer_t, src *readerSource) (*VideoDecoder, error) {
+	video := plm_video_create_with_buffer(buffer, _true)
+	if plm_video_has_header(video) != _true {
+		plm_video_destroy(video)
+		return nil, ExpectedHeader{}
+	}
+	return &VideoDecoder{video: video, seekable: src == nil, reader: src}, nil
+}
+
+// NewVideoDecoderFromFile creates a new video decoder from a given file. The
+// file is not closed when the decoder is closed.
+func NewVideoDecoderFromFile(f *os.File) (*VideoDecoder, error) {
+	return newVideoDecoder(plm_buffer_create_with_file(stdio.OpenFrom(f), _false), nil)
+}
+
+// NewVideoDecoderFromFilename creates a new video decoder from a given
+// filename.
+func NewVideoDecoderFromFilename(file string) (*VideoDecoder, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	return newVideoDecoder(plm_buffer_create_with_file(stdio.OpenFrom(f), _true), nil)
+}
+
+// NewVideoDecoderFromBytes creates a new video decoder from a list of raw
+// bytes. "data" must not be modified while the decoder is in use.
+func NewVideoDecoderFromBytes(data []byte) (*VideoDecoder, error) {
+	if len(data) == 0 {
+		return nil, ExpectedHeader{}
+	}
+	return newVideoDecoder(plm_buffer_create_with_memory(&data[0], uint64(len(data)), _false), nil)
+}
+
+// NewVideoDecoderFromReader creates a new video decoder that reads from "r".
+// Like "NewPlayerFromReader", it can only decode forward once if "r" can not
+// seek.
+//
+// If "r" is an "io.Closer", it is not closed when the decoder is closed.
+func NewVideoDecoderFromReader(r io.Reader) (*VideoDecoder, error) {
+	return newVideoDecoder(newReaderBuffer(r))
+}
+
+// Close closes the decoder and discards data.
+func (d *VideoDecoder) Close() {
+	defer d.lock()()
+	d.frame, d.pending = nil, nil
+	plm_video_destroy(d.video)
+	d.video = nil
+}
+
+// lock locks the decoder and returns the function that unlocks it.
+func (d *VideoDecoder) lock() (unlock func()) {
+	d.mu.Lock()
+	return d.mu.Unlock
+}
+
+// Width returns the width of the video in pixels.
+func (d *VideoDecoder) Width() int { defer d.lock()(); return int(d.video.Width) }
+
+// Height returns the height of the video in pixels.
+func (d *VideoDecoder) Height() int { defer d.lock()(); return int(d.video.Height) }
+
+// FrameRate returns the framerate of the video.
+func (d *VideoDecoder) FrameRate() float64 { defer d.lock()(); return d.video.Framerate }
+
+// PixelAspectRatio is the width of a pixel divided by its height, like
+// "Player.PixelAspectRatio".
+func (d *VideoDecoder) PixelAspectRatio() float64 {
+	defer d.lock()()
+	return videoPixelAspectRatio(d.video)
+}
+
+// Frame returns the current frame, or nil if no frame was decoded yet.
+func (d *VideoDecoder) Frame() *Frame { defer d.lock()(); return d.frame }
+
+// NextFrame decodes and returns the next frame in display order. It returns
+// "io.EOF" once the video has ended.
+func (d *VideoDecoder) NextFrame() (*Frame, error) {
+	defer d.lock()()
+	f := d.pending
+	if f != nil {
+		d.pending = nil
+	} else {
+		f = plm_video_decode(d.video)
+		d.collectErrors()
+	}
+	if f == nil {
+		return nil, io.EOF
+	}
+	d.frame = newFrame(f)
+	return d.frame, nil
+}
+
+// ReadPixels converts the current frame to "format" and writes it to "dst".
+// See "Frame.ReadPixels" for details.
+func (d *VideoDecoder) ReadPixels(dst []byte, format PixelFormat, stride int) error {
+	defer d.lock()()
+	if d.frame == nil {
+		return nil
+	}
+	return d.frame.ReadPixels(dst, format, stride)
+}
+
+// Time is the presentation time of the next frame.
+func (d *VideoDecoder) Time() time.Duration {
+	defer d.lock()()
+	if d.pending != nil {
+		return floatToSecs(d.pending.Time)
+	}
+	return floatToSecs(d.video.Time)
+}
+
+// Duration is how long the entire video is. The whole stream is scanned the
+// first time this is called. This is "UnknownDuration" if the decoder can not
+// seek.
+func (d *VideoDecoder) Duration() time.Duration {
+	defer d.lock()()
+	if !d.seekable {
+		return UnknownDuration
+	}
+	d.scan()
+	return d.duration
+}
+
+// Seekable returns true if the decoder is able to seek and rewind.
+func (d *VideoDecoder) Seekable() bool { return d.seekable }
+
+// Rewind moves to the beginning. This does nothing if the decoder can not
+// seek.
+func (d *VideoDecoder) Rewind() {
+	defer d.lock()()
+	if d.seekable {
+		d.pending = nil
+		plm_video_rewind(d.video)
+	}
+}
+
+// Seek moves to the specified time, so that "NextFrame" returns the frame shown
+// at that time.
+//
+// If "exact" is false, this will seek to the nearest intra frame.
+// If "exact" is true, this will seek to the exact time. this can be slower as
+// each frame since the last intra frame would need to be decoded.
+//
+// The whole stream is scanned for intra frames the first time this is called.
+// Seek returns "ErrNotSeekable" if the decoder can not seek, and
+// "ErrSeekFailed" if no intra frame was found.
+func (d *VideoDecoder) Seek(elapsed time.Duration, exact bool) error {
+	defer d.lock()()
+	if !d.seekable {
+		return ErrNotSeekable
+	}
+	d.scan()
+	d.pending = nil
+	video := d.video
+	// Find the last intra frame shown at or before "elapsed".
+	i := sort.Search(len(d.keyframes), func(i int) bool { return d.keyframes[i].Time > elapsed }) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(d.keyframes) {
+		return ErrSeekFailed
+	}
+	keyframe := d.keyframes[i]
+	plm_buffer_seek(video.Buffer, uint64(keyframe.Offset))
+	video.Start_code = -1
+	video.Has_reference_frame = _false
+	if video.Mpeg2 != nil {
+		video.Mpeg2.Second_field = _false
+	}
+	target := elapsed.Seconds() * video.Framerate
+	d.pending = decodeFromKeyframe(video, keyframe, func(number int, frame *plm_frame_t) bool {
+		return !exact || frameEnd(video, frame) > target+1e-6
+	})
+	d.collectErrors()
+	if d.pending == nil {
+		return ErrSeekFailed
+	}
+	return nil
+}
+
+// scan reads the whole stream once to find its duration and the intra frames
+// seeking can start from. Decoding continues where it was afterwards.
+func (d *VideoDecoder) scan() {
+	if d.scanned {
+		return
+	}
+	d.scanned = true
+	buffer := d.video.Buffer
+	resume := plm_buffer_tell(buffer)
+	plm_buffer_rewind(buffer)
+	scanner := videoScanner{framerate: d.video.Framerate}
+	for {
+		code := plm_buffer_next_start_code(buffer)
+		if code == -1 {
+			break
+		}
+		switch {
+		case code == plm_start_picture && plm_buffer_has(buffer, 13) == _true:
+			offset := int64(plm_buffer_tell(buffer)) - 4
+			plm_buffer_skip(buffer, 10)
+			scanner.picture(plm_buffer_read(buffer, 3), offset)
+		case code == plm_start_extension && plm_buffer_has(buffer, 40) == _true:
+			v := uint64(plm_buffer_read(buffer, 20)) << 20
+			scanner.extension(v | uint64(plm_buffer_read(buffer, 20)))
+		}
+	}
+	scanner.finish()
+	d.duration, d.keyframes = scanner.duration(), scanner.keyframes
+	plm_buffer_seek(buffer, resume)
+}
+
+// LastError returns the last "*DecodeError" found while decoding, or nil if the
+// data decoded so far was valid.
+func (d *VideoDecoder) LastError() error { defer d.lock()(); return d.lastErr }
+
+func (d *VideoDecoder) collectErrors() {
+	if d.video.Error != plm_error_none {
+		d.lastErr = newDecodeError(StageVideo, &d.video.Error, int64(plm_buffer_tell(d.video.Buffer)), d.reader)
+	}
+}
@@ -0,0 +1,91 @@
+package mpg
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"io"
+	"testing"
+	"unsafe"
+)
+
+// demuxTestStream returns the payload of all packets of type "typ" in the
+// program stream "data".
+func demuxTestStream(data []byte, typ int64) []byte {
+	demux := plm_demux_create(plm_buffer_create_with_memory(&data[0], uint64(len(data)), _false), _true)
+	defer plm_demux_destroy(demux)
+	var es []byte
+	for {
+		p := plm_demux_decode(demux)
+		if p == nil {
+			return es
+		}
+		if p.Type == typ {
+			es = append(es, unsafe.Slice(p.Data, p.Length)...)
+		}
+	}
+}
+
+func TestVideoDecoder(t *testing.T) {
+	data := readTestFile(t, "testdata/test.mpg")
+	plm, err := NewPlayerFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := decodeFrames(t, plm)
+
+	es := demuxTestStream(data, plm_demux_packet_video_1)
+	for _, tc := range []struct {
+		name string
+		open func() (*VideoDecoder, error)
+	}{
+		{"bytes", func() (*VideoDecoder, error) { return NewVideoDecoderFromBytes(es) }},
+		{"reader", func() (*VideoDecoder, error) { return NewVideoDecoderFromReader(bytes.NewReader(es)) }},
+	} {
+		d, err := tc.open()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if d.Width() != 64 || d.Height() != 48 || d.FrameRate() != 25 {
+			t.Errorf("%s: %dx%d at %v fps, want 64x48 at 25 fps", tc.name, d.Width(), d.Height(), d.FrameRate())
+		}
+		var got [][sha1.Size]byte
+		for {
+			f, err := d.NextFrame()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			got = append(got, frameHash(f))
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: decoded %d frames, want %d", tc.name, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: frame %d differs from the player's", tc.name, i)
+			}
+		}
+		if d.LastError() != nil {
+			t.Errorf("%s: %v", tc.name, d.LastError())
+		}
+		d.Close()
+	}
+
+	d, err := NewVideoDecoderFromBytes(es)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if err := d.Seek(d.Duration()/2, true); err != nil {
+		t.Fatal(err)
+	}
+	f, err := d.NextFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frameHash(f) != want[25] {
+		t.Error("frame after seeking to the middle is not frame 25")
+	}
+}
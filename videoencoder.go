@@ -0,0 +1,795 @@
+package mpg
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"unsafe"
+)
+
+// VideoEncoderOptions configures a "VideoEncoder".
+type VideoEncoderOptions struct {
+	// Width and Height of the video in pixels. Both are required.
+	Width, Height int
+	// FrameRate is the number of frames per second. It is rounded to the
+	// nearest rate MPEG1 supports (23.976, 24, 25, 29.97, 30, 50, 59.94 or 60).
+	// Defaults to 30.
+	FrameRate float64
+	// Quantizer is the fixed quantizer scale (1-31) used when "BitRate" is 0.
+	// Lower values give better quality and bigger files. Defaults to 8.
+	Quantizer int
+	// BitRate is the targeted bit rate in bits per second. When set, the
+	// quantizer is adjusted for every slice to meet the target.
+	BitRate int
+	// GOPSize is the number of frames in a group of pictures, meaning an
+	// intra frame is produced every "GOPSize" frames. A value of 1 produces
+	// only intra frames. Defaults to 15.
+	GOPSize int
+}
+
+// motionSearchRange is how far in whole pixels motion vectors are searched.
+// Together with half pixel refinement it fits a forward_f_code of 1.
+const motionSearchRange = 7
+
+// startCodeGOP starts a group of pictures, which restarts the temporal
+// reference of the pictures in it.
+const startCodeGOP int64 = 0xB8
+
+var (
+	videoAddressCodes   = vlcEncodeTable(plm_video_macroblock_address_increment[:])
+	videoIntraTypeCodes = vlcEncodeTable(plm_video_macroblock_type_intra[:])
+	videoPredTypeCodes  = vlcEncodeTable(plm_video_macroblock_type_predictive[:])
+	videoPatternCodes   = vlcEncodeTable(plm_video_code_block_pattern[:])
+	videoMotionCodes    = vlcEncodeTable(plm_video_motion[:])
+	videoDCLumaCodes    = vlcEncodeTable(plm_video_dct_size_luminance[:])
+	videoDCChromaCodes  = vlcEncodeTable(plm_video_dct_size_chrominance[:])
+	videoCoeffCodes     = vlcUintEncodeTable(plm_video_dct_coeff[:])
+	videoDCTCos         = dctCosTable()
+	videoEndOfBlockCode = vlcCode{0b10, 2}
+	videoEscapeCode     = videoCoeffCodes[math.MaxUint16]
+	videoEscapeAddress  = int16(35)
+)
+
+// VideoEncoder encodes images into an MPEG1 video elementary stream using
+// intra (I) and predicted (P) frames.
+//
+// The output of "Encode" can be written to a ".m1v" file, decoded by
+// "VideoDecoder" or multiplexed together with audio using a "Muxer".
+type VideoEncoder struct {
+	opts           VideoEncoderOptions
+	rateCode       int64
+	mbWidth        int
+	mbHeight       int
+	lumaWidth      int
+	lumaHeight     int
+	chromaWidth    int
+	chromaHeight   int
+	frames         int
+	gopFrame       int
+	y, cb, cr      []byte
+	w              bitWriter
+	recon          *plm_video_t
+	quantizer      int
+	fullness       float64
+	mvH, mvV       int
+	dcPredictor    [3]int
+	coeffs         [6][64]int
+	headerBits     int
+	sequenceHeader []byte
+}
+
+// NewVideoEncoder creates a new encoder with the given options. It returns
+// "ErrInvalidOptions" if the size, quantizer, bitrate or GOP size is out of
+// range.
+func NewVideoEncoder(opts VideoEncoderOptions) (*VideoEncoder, error) {
+	if opts.Width <= 0 || opts.Height <= 0 || opts.Width > 4095 || opts.Height > 4095 {
+		return nil, ErrInvalidOptions
+	}
+	if opts.FrameRate <= 0 {
+		opts.FrameRate = 30
+	}
+	if opts.Quantizer == 0 {
+		opts.Quantizer = 8
+	}
+	if opts.Quantizer < 1 || opts.Quantizer > 31 || opts.BitRate < 0 || opts.GOPSize < 0 {
+		return nil, ErrInvalidOptions
+	}
+	if opts.GOPSize == 0 {
+		opts.GOPSize = 15
+	}
+	enc := &VideoEncoder{opts: opts}
+	best := math.Inf(1)
+	for code := int64(1); code < 9; code++ {
+		if diff := math.Abs(plm_video_picture_rate[code] - opts.FrameRate); diff < best {
+			best, enc.rateCode = diff, code
+		}
+	}
+	enc.opts.FrameRate = plm_video_picture_rate[enc.rateCode]
+	enc.mbWidth = (opts.Width + 15) >> 4
+	enc.mbHeight = (opts.Height + 15) >> 4
+	enc.lumaWidth, enc.lumaHeight = enc.mbWidth<<4, enc.mbHeight<<4
+	enc.chromaWidth, enc.chromaHeight = enc.mbWidth<<3, enc.mbHeight<<3
+	enc.y = make([]byte, enc.lumaWidth*enc.lumaHeight)
+	enc.cb = make([]byte, enc.chromaWidth*enc.chromaHeight)
+	enc.cr = make([]byte, enc.chromaWidth*enc.chromaHeight)
+	enc.quantizer = opts.Quantizer
+	if opts.BitRate > 0 {
+		enc.quantizer = 10
+		enc.fullness = enc.reaction() * 10 / 31
+	}
+
+	enc.writeSequenceHeader()
+	enc.sequenceHeader = append([]byte(nil), enc.w.bytes()...)
+	enc.w.reset()
+
+	// The reconstructed frames, used as a reference for predicted frames, are
+	// produced by the regular decoder so both always agree on every pixel.
+	buffer := plm_buffer_create_for_appending(128 * 1024)
+	plm_buffer_write(buffer, bytesToUintPtr(enc.sequenceHeader), uint64(len(enc.sequenceHeader)))
+	// The decoder wants to see enough data for a header with quant matrices.
+	padding := make([]byte, 160)
+	plm_buffer_write(buffer, bytesToUintPtr(padding), uint64(len(padding)))
+	enc.recon = plm_video_create_with_buffer(buffer, _true)
+	if plm_video_has_header(enc.recon) == 0 {
+		return nil, ErrInvalidOptions
+	}
+	return enc, nil
+}
+
+// FrameRate returns the frame rate that is actually encoded.
+func (enc *VideoEncoder) FrameRate() float64 { return enc.opts.FrameRate }
+
+// Frames returns the number of frames encoded so far.
+func (enc *VideoEncoder) Frames() int { return enc.frames }
+
+// Encode encodes "img" as the next frame and returns the coded picture. Intra
+// frames start with a sequence header and a group of pictures header so
+// decoding can start from any of them.
+//
+// "img" is not scaled. The area of the configured width and height at the
+// image's minimum point is encoded, and missing pixels are filled by repeating
+// the edges. "*image.YCbCr" images with 4:2:0 subsampling and decoded
+// "*Frame"s are copied as is. Other images are converted using the same BT.601
+// studio swing matrix "ReadRGBA" and "ReadPixels" use to convert back.
+//
+// The returned slice is only valid until the next call to Encode. It returns
+// "ErrInvalidOptions" if "img" is nil.
+func (enc *VideoEncoder) Encode(img image.Image) ([]byte, error) {
+	if img == nil {
+		return nil, ErrInvalidOptions
+	}
+	if f, ok := img.(*Frame); ok {
+		img = f.YCbCr()
+	}
+	enc.loadImage(img)
+	enc.w.reset()
+
+	intra := enc.gopFrame%enc.opts.GOPSize == 0
+	if intra {
+		enc.gopFrame = 0
+		enc.w.data = append(enc.w.data, enc.sequenceHeader...)
+		enc.writeGOPHeader()
+	}
+	pictureStart := len(enc.w.data)
+	enc.writePictureHeader(intra)
+	enc.headerBits = enc.w.len()
+
+	target := 0.0
+	if enc.opts.BitRate > 0 {
+		target = float64(enc.opts.BitRate) / enc.opts.FrameRate
+	}
+	for row := 0; row < enc.mbHeight; row++ {
+		if enc.opts.BitRate > 0 {
+			spent := float64(enc.w.len() - enc.headerBits)
+			expected := target * float64(row) / float64(enc.mbHeight)
+			q := int(math.Round((enc.fullness + spent - expected) * 31 / enc.reaction()))
+			if q < 1 {
+				q = 1
+			} else if q > 31 {
+				q = 31
+			}
+			enc.quantizer = q
+		}
+		enc.encodeSlice(row, intra)
+	}
+	if enc.opts.BitRate > 0 {
+		enc.fullness += float64(enc.w.len()-enc.headerBits) - target
+		if enc.fullness < 0 {
+			enc.fullness = 0
+		}
+	}
+	data := enc.w.bytes()
+
+	enc.reconstruct(data[pictureStart:])
+	enc.frames++
+	enc.gopFrame++
+	return data, nil
+}
+
+// Flush returns the sequence end code that terminates the video stream.
+func (enc *VideoEncoder) Flush() []byte {
+	return []byte{0x00, 0x00, 0x01, byte(plm_start_end)}
+}
+
+// reaction is how strongly the rate control reacts to an over- or undershoot.
+func (enc *VideoEncoder) reaction() float64 {
+	return 2 * float64(enc.opts.BitRate) / enc.opts.FrameRate
+}
+
+func (enc *VideoEncoder) writeSequenceHeader() {
+	w := &enc.w
+	w.startCode(byte(plm_start_sequence))
+	w.write(uint32(enc.opts.Width), 12)
+	w.write(uint32(enc.opts.Height), 12)
+	w.write(1, 4) // square pixels
+	w.write(uint32(enc.rateCode), 4)
+	bitRate := uint32(0x3FFFF)
+	if enc.opts.BitRate > 0 {
+		bitRate = uint32((enc.opts.BitRate + 399) / 400)
+		if bitRate > 0x3FFFE {
+			bitRate = 0x3FFFE
+		}
+	}
+	w.write(bitRate, 18)
+	w.write(1, 1)   // marker
+	w.write(20, 10) // vbv_buffer_size
+	w.write(0, 1)   // constrained_parameters_flag
+	w.write(0, 1)   // load_intra_quantizer_matrix
+	w.write(0, 1)   // load_non_intra_quantizer_matrix
+}
+
+func (enc *VideoEncoder) writeGOPHeader() {
+	w := &enc.w
+	rate := int(math.Round(enc.opts.FrameRate))
+	seconds := enc.frames / rate
+	w.startCode(byte(startCodeGOP))
+	w.write(0, 1) // drop_frame_flag
+	w.write(uint32(seconds/3600%24), 5)
+	w.write(uint32(seconds/60%60), 6)
+	w.write(1, 1) // marker
+	w.write(uint32(seconds%60), 6)
+	w.write(uint32(enc.frames%rate), 6)
+	w.write(1, 1) // closed_gop
+	w.write(0, 1) // broken_link
+}
+
+func (enc *VideoEncoder) writePictureHeader(intra bool) {
+	w := &enc.w
+	w.startCode(byte(plm_start_picture))
+	w.write(uint32(enc.gopFrame&1023), 10)
+	if intra {
+		w.write(uint32(plm_video_picture_type_intra), 3)
+	} else {
+		w.write(uint32(plm_video_picture_type_predictive), 3)
+	}
+	w.write(0xFFFF, 16) // vbv_delay
+	if !intra {
+		w.write(0, 1) // full_pel_forward_vector
+		w.write(1, 3) // forward_f_code
+	}
+	w.write(0, 1) // extra_bit_picture
+}
+
+// loadImage copies "img" into the internal 4:2:0 planes, repeating the right
+// and bottom edges to fill partial macroblocks.
+func (enc *VideoEncoder) loadImage(img image.Image) {
+	width, height := enc.opts.Width, enc.opts.Height
+	bounds := img.Bounds()
+	if bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+	if bounds.Dy() < height {
+		height = bounds.Dy()
+	}
+	if width <= 0 || height <= 0 {
+		for i := range enc.y {
+			enc.y[i] = 16
+		}
+		for i := range enc.cb {
+			enc.cb[i], enc.cr[i] = 128, 128
+		}
+		return
+	}
+	lw, cw := enc.lumaWidth, enc.chromaWidth
+	min := bounds.Min
+	if src, ok := img.(*image.YCbCr); ok && src.SubsampleRatio == image.YCbCrSubsampleRatio420 && min.X%2 == 0 && min.Y%2 == 0 {
+		for y := 0; y < height; y++ {
+			copy(enc.y[y*lw:y*lw+width], src.Y[src.YOffset(min.X, min.Y+y):])
+		}
+		for y := 0; y < (height+1)/2; y++ {
+			offset := src.COffset(min.X, min.Y+y*2)
+			copy(enc.cb[y*cw:y*cw+(width+1)/2], src.Cb[offset:])
+			copy(enc.cr[y*cw:y*cw+(width+1)/2], src.Cr[offset:])
+		}
+	} else {
+		var rows [2][]int
+		for i := range rows {
+			rows[i] = make([]int, width*2)
+		}
+		for y := 0; y < height; y += 2 {
+			for dy := 0; dy < 2; dy++ {
+				sy := y + dy
+				if sy >= height {
+					sy = height - 1
+				}
+				for x := 0; x < width; x++ {
+					r, g, b := rgbAt(img, min.X+x, min.Y+sy)
+					// BT.601 studio swing, the inverse of "plm_frame_to_rgb".
+					enc.y[(y+dy)*lw+x] = byte((66*r+129*g+25*b+128)>>8 + 16)
+					rows[dy][x*2] = -38*r - 74*g + 112*b
+					rows[dy][x*2+1] = 112*r - 94*g - 18*b
+				}
+			}
+			for x := 0; x < width; x += 2 {
+				nx := x + 1
+				if nx >= width {
+					nx = width - 1
+				}
+				cb := rows[0][x*2] + rows[0][nx*2] + rows[1][x*2] + rows[1][nx*2]
+				cr := rows[0][x*2+1] + rows[0][nx*2+1] + rows[1][x*2+1] + rows[1][nx*2+1]
+				enc.cb[y/2*cw+x/2] = byte((cb+512)>>10 + 128)
+				enc.cr[y/2*cw+x/2] = byte((cr+512)>>10 + 128)
+			}
+		}
+	}
+	padPlane(enc.y, lw, enc.lumaHeight, width, height)
+	padPlane(enc.cb, cw, enc.chromaHeight, (width+1)/2, (height+1)/2)
+	padPlane(enc.cr, cw, enc.chromaHeight, (width+1)/2, (height+1)/2)
+}
+
+// rgbAt returns the 8-bit color components of a pixel.
+func rgbAt(img image.Image, x, y int) (r, g, b int) {
+	switch img := img.(type) {
+	case *image.RGBA:
+		i := img.PixOffset(x, y)
+		return int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2])
+	case *image.NRGBA:
+		i := img.PixOffset(x, y)
+		return int(img.Pix[i]), int(img.Pix[i+1]), int(img.Pix[i+2])
+	}
+	c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	return int(c.R), int(c.G), int(c.B)
+}
+
+// padPlane fills the area outside of "width" and "height" by repeating the
+// last column and row.
+func padPlane(plane []byte, stride, rows, width, height int) {
+	for y := 0; y < height; y++ {
+		line := plane[y*stride : (y+1)*stride]
+		for x := width; x < stride; x++ {
+			line[x] = line[width-1]
+		}
+	}
+	for y := height; y < rows; y++ {
+		copy(plane[y*stride:(y+1)*stride], plane[(height-1)*stride:height*stride])
+	}
+}
+
+// reconstruct decodes the picture that was just encoded into the reference
+// frames of the internal decoder.
+func (enc *VideoEncoder) reconstruct(picture []byte) {
+	buffer := enc.recon.Buffer
+	plm_buffer_discard_read_bytes(buffer)
+	plm_buffer_write(buffer, bytesToUintPtr(picture), uint64(len(picture)))
+	end := []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, byte(plm_start_end)}
+	plm_buffer_write(buffer, bytesToUintPtr(end), uint64(len(end)))
+	enc.recon.Start_code = plm_buffer_find_start_code(buffer, plm_start_picture)
+	plm_video_decode_picture(enc.recon)
+}
+
+// reference returns the luma and chroma planes of the last reconstructed frame.
+func (enc *VideoEncoder) reference() (y, cb, cr []byte) {
+	f := &enc.recon.Frame_backward
+	return unsafe.Slice(f.Y.Data, len(enc.y)), unsafe.Slice(f.Cb.Data, len(enc.cb)), unsafe.Slice(f.Cr.Data, len(enc.cr))
+}
+
+func (enc *VideoEncoder) encodeSlice(row int, intra bool) {
+	w := &enc.w
+	w.startCode(byte(row + 1))
+	w.write(uint32(enc.quantizer), 5)
+	w.write(0, 1) // extra_bit_slice
+
+	enc.dcPredictor = [3]int{128, 128, 128}
+	enc.mvH, enc.mvV = 0, 0
+	lastAddress := -1
+	for col := 0; col < enc.mbWidth; col++ {
+		last := col == enc.mbWidth-1
+		if intra {
+			enc.writeAddress(col - lastAddress)
+			w.writeCode(videoIntraTypeCodes[1])
+			enc.encodeIntra(row, col)
+			lastAddress = col
+			continue
+		}
+		if enc.encodePredicted(row, col, col-lastAddress, lastAddress == -1 || last) {
+			lastAddress = col
+		}
+	}
+}
+
+func (enc *VideoEncoder) writeAddress(increment int) {
+	for increment > 33 {
+		enc.w.writeCode(videoAddressCodes[videoEscapeAddress])
+		increment -= 33
+	}
+	enc.w.writeCode(videoAddressCodes[int16(increment)])
+}
+
+// encodeIntra writes the six blocks of an intra coded macroblock.
+func (enc *VideoEncoder) encodeIntra(row, col int) {
+	for block := 0; block < 6; block++ {
+		plane, stride, x, y := enc.blockSource(row, col, block)
+		var samples [64]float64
+		for i := 0; i < 8; i++ {
+			for j := 0; j < 8; j++ {
+				samples[i*8+j] = float64(plane[(y+i)*stride+x+j])
+			}
+		}
+		coeffs := &enc.coeffs[block]
+		forwardDCT(&samples, coeffs)
+
+		component := 0
+		if block > 3 {
+			component = block - 3
+		}
+		dc := (coeffs[0] + 4) >> 3
+		if dc < 0 {
+			dc = 0
+		} else if dc > 255 {
+			dc = 255
+		}
+		enc.writeDC(dc-enc.dcPredictor[component], component)
+		enc.dcPredictor[component] = dc
+
+		q := enc.quantizer
+		for i := 1; i < 64; i++ {
+			c := coeffs[plm_video_zig_zag[i]]
+			div := q * int(plm_video_intra_quant_matrix[plm_video_zig_zag[i]])
+			level := (abs(c)*8*8/div + 3) / 8
+			if c < 0 {
+				level = -level
+			}
+			coeffs[plm_video_zig_zag[i]] = clampLevel(level)
+		}
+		enc.writeCoefficients(coeffs, 1)
+	}
+}
+
+// encodePredicted decides how to code a macroblock of a predicted frame and
+// writes it. It returns false if the macroblock was skipped.
+func (enc *VideoEncoder) encodePredicted(row, col, increment int, mustCode bool) bool {
+	refY, _, _ := enc.reference()
+	lw := enc.lumaWidth
+	x0, y0 := col<<4, row<<4
+
+	// Motion search on the luma plane: first whole pixels, then half pixels.
+	bestH, bestV := 0, 0
+	zeroSAD := sad16(enc.y, refY, lw, x0, y0, 0, 0)
+	bestSAD := zeroSAD
+	for v := -motionSearchRange; v <= motionSearchRange; v++ {
+		for h := -motionSearchRange; h <= motionSearchRange; h++ {
+			if !enc.validMotion(x0, y0, h*2, v*2) {
+				continue
+			}
+			if sad := sad16(enc.y, refY, lw, x0, y0, h*2, v*2); sad < bestSAD {
+				bestSAD, bestH, bestV = sad, h*2, v*2
+			}
+		}
+	}
+	centerH, centerV := bestH, bestV
+	for v := -1; v <= 1; v++ {
+		for h := -1; h <= 1; h++ {
+			if (h == 0 && v == 0) || !enc.validMotion(x0, y0, centerH+h, centerV+v) {
+				continue
+			}
+			if sad := sad16(enc.y, refY, lw, x0, y0, centerH+h, centerV+v); sad < bestSAD {
+				bestSAD, bestH, bestV = sad, centerH+h, centerV+v
+			}
+		}
+	}
+	// Prefer the zero vector as it is cheaper to code.
+	if zeroSAD <= bestSAD+64 {
+		bestSAD, bestH, bestV = zeroSAD, 0, 0
+	}
+
+	// Use intra coding when prediction does not help.
+	mean := 0
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			mean += int(enc.y[(y0+y)*lw+x0+x])
+		}
+	}
+	mean = (mean + 128) >> 8
+	activity := 0
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			activity += abs(int(enc.y[(y0+y)*lw+x0+x]) - mean)
+		}
+	}
+	w := &enc.w
+	if bestSAD > activity+512 {
+		enc.writeAddress(increment)
+		w.writeCode(videoPredTypeCodes[1])
+		// The decoder keeps the DC predictors of a previous intra macroblock
+		// and only resets them after skipped or non-intra ones.
+		if increment > 1 {
+			enc.dcPredictor = [3]int{128, 128, 128}
+		}
+		enc.mvH, enc.mvV = 0, 0
+		enc.encodeIntra(row, col)
+		return true
+	}
+
+	cbp := enc.quantizeResidual(row, col, bestH, bestV)
+	zero := bestH == 0 && bestV == 0
+	if zero && cbp == 0 && !mustCode {
+		// Skipped macroblocks reset the motion vector predictor.
+		enc.mvH, enc.mvV = 0, 0
+		return false
+	}
+
+	enc.writeAddress(increment)
+	if increment > 1 {
+		enc.mvH, enc.mvV = 0, 0
+	}
+	enc.dcPredictor = [3]int{128, 128, 128}
+	switch {
+	case zero && cbp != 0:
+		w.writeCode(videoPredTypeCodes[2])
+		enc.mvH, enc.mvV = 0, 0
+	case cbp != 0:
+		w.writeCode(videoPredTypeCodes[10])
+		enc.writeMotion(bestH, bestV)
+	default:
+		w.writeCode(videoPredTypeCodes[8])
+		enc.writeMotion(bestH, bestV)
+	}
+	if cbp != 0 {
+		w.writeCode(videoPatternCodes[int16(cbp)])
+		for block, mask := 0, 32; block < 6; block++ {
+			if cbp&mask != 0 {
+				enc.writeCoefficients(&enc.coeffs[block], 0)
+			}
+			mask >>= 1
+		}
+	}
+	return true
+}
+
+// validMotion returns true if the motion vector, given in half pixels, keeps
+// the luma and chroma predictions inside of the reference frame.
+func (enc *VideoEncoder) validMotion(x, y, h, v int) bool {
+	inside := func(x, y, h, v, size, width, height int) bool {
+		sx, sy := x+h>>1, y+v>>1
+		ex, ey := sx+size+h&1, sy+size+v&1
+		return sx >= 0 && sy >= 0 && ex <= width && ey <= height
+	}
+	return inside(x, y, h, v, 16, enc.lumaWidth, enc.lumaHeight) &&
+		inside(x>>1, y>>1, h/2, v/2, 8, enc.chromaWidth, enc.chromaHeight)
+}
+
+// writeMotion codes a forward motion vector relative to the predictor.
+func (enc *VideoEncoder) writeMotion(h, v int) {
+	enc.writeMotionComponent(h - enc.mvH)
+	enc.writeMotionComponent(v - enc.mvV)
+	enc.mvH, enc.mvV = h, v
+}
+
+func (enc *VideoEncoder) writeMotionComponent(delta int) {
+	// forward_f_code is 1, so every difference maps directly to a code.
+	if delta > 15 {
+		delta -= 32
+	} else if delta < -16 {
+		delta += 32
+	}
+	enc.w.writeCode(videoMotionCodes[int16(delta)])
+}
+
+// quantizeResidual computes the prediction error of every block, quantizes it
+// into "coeffs" and returns the coded block pattern.
+func (enc *VideoEncoder) quantizeResidual(row, col, h, v int) (cbp int) {
+	refY, refCb, refCr := enc.reference()
+	var pred [256]int
+	for block := 0; block < 6; block++ {
+		plane, stride, x, y := enc.blockSource(row, col, block)
+		var ref []byte
+		mh, mv := h, v
+		switch {
+		case block < 4:
+			ref = refY
+		case block == 4:
+			ref, mh, mv = refCb, h/2, v/2
+		default:
+			ref, mh, mv = refCr, h/2, v/2
+		}
+		predictBlock(ref, stride, x, y, mh, mv, pred[:64])
+		var samples [64]float64
+		for i := 0; i < 8; i++ {
+			for j := 0; j < 8; j++ {
+				samples[i*8+j] = float64(int(plane[(y+i)*stride+x+j]) - pred[i*8+j])
+			}
+		}
+		coeffs := &enc.coeffs[block]
+		forwardDCT(&samples, coeffs)
+		q := enc.quantizer
+		coded := false
+		for i := 0; i < 64; i++ {
+			c := coeffs[i]
+			level := abs(c) * 8 / (q * int(plm_video_non_intra_quant_matrix[i]))
+			if c < 0 {
+				level = -level
+			}
+			coeffs[i] = clampLevel(level)
+			coded = coded || level != 0
+		}
+		if coded {
+			cbp |= 32 >> block
+		}
+	}
+	return cbp
+}
+
+// predictBlock computes an 8x8 motion compensated prediction the same way
+// "plm_video_process_macroblock" does.
+func predictBlock(ref []byte, stride, x, y, h, v int, dst []int) {
+	si := (y+v>>1)*stride + x + h>>1
+	oddH, oddV := h&1, v&1
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			p := si + i*stride + j
+			switch oddH<<1 | oddV {
+			case 0:
+				dst[i*8+j] = int(ref[p])
+			case 1:
+				dst[i*8+j] = (int(ref[p]) + int(ref[p+stride]) + 1) >> 1
+			case 2:
+				dst[i*8+j] = (int(ref[p]) + int(ref[p+1]) + 1) >> 1
+			default:
+				dst[i*8+j] = (int(ref[p]) + int(ref[p+1]) + int(ref[p+stride]) + int(ref[p+stride+1]) + 2) >> 2
+			}
+		}
+	}
+}
+
+// sad16 returns the sum of absolute differences of a 16x16 luma block and its
+// prediction using a half pixel motion vector.
+func sad16(cur, ref []byte, stride, x, y, h, v int) int {
+	var pred [64]int
+	sum := 0
+	for block := 0; block < 4; block++ {
+		bx, by := x+(block&1)*8, y+(block>>1)*8
+		predictBlock(ref, stride, bx, by, h, v, pred[:])
+		for i := 0; i < 8; i++ {
+			line := cur[(by+i)*stride+bx:]
+			for j := 0; j < 8; j++ {
+				sum += abs(int(line[j]) - pred[i*8+j])
+			}
+		}
+	}
+	return sum
+}
+
+// blockSource returns the plane and position of one of the six blocks of a
+// macroblock.
+func (enc *VideoEncoder) blockSource(row, col, block int) (plane []byte, stride, x, y int) {
+	switch {
+	case block < 4:
+		return enc.y, enc.lumaWidth, col<<4 + (block&1)<<3, row<<4 + (block>>1)<<3
+	case block == 4:
+		return enc.cb, enc.chromaWidth, col << 3, row << 3
+	default:
+		return enc.cr, enc.chromaWidth, col << 3, row << 3
+	}
+}
+
+// writeDC codes the difference of an intra DC coefficient to its predictor.
+func (enc *VideoEncoder) writeDC(diff, component int) {
+	size := 0
+	for abs(diff)>>size != 0 {
+		size++
+	}
+	if component == 0 {
+		enc.w.writeCode(videoDCLumaCodes[int16(size)])
+	} else {
+		enc.w.writeCode(videoDCChromaCodes[int16(size)])
+	}
+	if size > 0 {
+		if diff < 0 {
+			diff += 1<<size - 1
+		}
+		enc.w.write(uint32(diff), uint(size))
+	}
+}
+
+// writeCoefficients run length codes the quantized coefficients of a block,
+// starting at the zig zag position "start", followed by the end of block code.
+func (enc *VideoEncoder) writeCoefficients(coeffs *[64]int, start int) {
+	w := &enc.w
+	run := 0
+	first := true
+	for i := start; i < 64; i++ {
+		level := coeffs[plm_video_zig_zag[i]]
+		if level == 0 {
+			run++
+			continue
+		}
+		value := uint16(run<<8 | abs(level))
+		if code, ok := videoCoeffCodes[value]; ok && abs(level) < 256 && run < 32 {
+			if value == 1 && !(first && start == 0) {
+				code = vlcCode{0b11, 2}
+			}
+			w.writeCode(code)
+			w.writeBool(level < 0)
+		} else {
+			w.writeCode(videoEscapeCode)
+			w.write(uint32(run), 6)
+			switch {
+			case level > 127:
+				w.write(0, 8)
+				w.write(uint32(level), 8)
+			case level < -127:
+				w.write(128, 8)
+				w.write(uint32(level+256), 8)
+			default:
+				w.write(uint32(level)&0xFF, 8)
+			}
+		}
+		run = 0
+		first = false
+	}
+	w.writeCode(videoEndOfBlockCode)
+}
+
+// dctCosTable returns the basis functions of an orthonormal 8 point DCT.
+func dctCosTable() (table [8][8]float64) {
+	for u := 0; u < 8; u++ {
+		scale := 0.5
+		if u == 0 {
+			scale = math.Sqrt(0.125)
+		}
+		for x := 0; x < 8; x++ {
+			table[u][x] = scale * math.Cos(float64(2*x+1)*float64(u)*math.Pi/16)
+		}
+	}
+	return table
+}
+
+// forwardDCT transforms an 8x8 block of samples into DCT coefficients, using
+// the same scale the decoder's inverse transform expects.
+func forwardDCT(samples *[64]float64, out *[64]int) {
+	var tmp [64]float64
+	for y := 0; y < 8; y++ {
+		for u := 0; u < 8; u++ {
+			sum := 0.0
+			for x := 0; x < 8; x++ {
+				sum += samples[y*8+x] * videoDCTCos[u][x]
+			}
+			tmp[y*8+u] = sum
+		}
+	}
+	for u := 0; u < 8; u++ {
+		for v := 0; v < 8; v++ {
+			sum := 0.0
+			for y := 0; y < 8; y++ {
+				sum += tmp[y*8+u] * videoDCTCos[v][y]
+			}
+			out[v*8+u] = int(math.Round(sum))
+		}
+	}
+}
+
+func clampLevel(level int) int {
+	if level > 255 {
+		return 255
+	} else if level < -255 {
+		return -255
+	}
+	return level
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,111 @@
+package mpg
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// lumaPSNR returns the peak signal to noise ratio in decibels of the luma
+// plane of "f" compared to the BT.601 luma of "testImage(n)".
+func lumaPSNR(f *Frame, n int) float64 {
+	img := testImage(n)
+	var noise float64
+	for y := 0; y < testHeight; y++ {
+		for x := 0; x < testWidth; x++ {
+			r, g, b := rgbAt(img, x, y)
+			want := float64((66*r+129*g+25*b+128)>>8 + 16)
+			diff := float64(f.Y[y*f.YStride+x]) - want
+			noise += diff * diff
+		}
+	}
+	return 10 * math.Log10(255*255/(noise/(testWidth*testHeight)))
+}
+
+func TestVideoEncoderRoundTrip(t *testing.T) {
+	enc, err := NewVideoEncoder(VideoEncoderOptions{Width: testWidth, Height: testHeight, FrameRate: testFrameRate, GOPSize: testGOPSize, Quantizer: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stream bytes.Buffer
+	for n := 0; n < 12; n++ {
+		data, err := enc.Encode(testImage(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Write(data)
+	}
+	stream.Write(enc.Flush())
+	if enc.Frames() != 12 {
+		t.Errorf("encoder counted %d frames, want 12", enc.Frames())
+	}
+
+	d, err := NewVideoDecoderFromBytes(stream.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Width() != testWidth || d.Height() != testHeight || d.FrameRate() != testFrameRate {
+		t.Fatalf("decoded %dx%d at %v fps, want %dx%d at %d fps", d.Width(), d.Height(), d.FrameRate(), testWidth, testHeight, testFrameRate)
+	}
+	n := 0
+	for ; ; n++ {
+		f, err := d.NextFrame()
+		if err != nil {
+			break
+		}
+		want := PicturePredictive
+		if n%testGOPSize == 0 {
+			want = PictureIntra
+		}
+		if f.Type != want {
+			t.Errorf("frame %d is a %v frame, want %v", n, f.Type, want)
+		}
+		if psnr := lumaPSNR(f, n); psnr < 35 {
+			t.Errorf("frame %d has a luma PSNR of %.1f dB, want at least 35 dB", n, psnr)
+		}
+	}
+	if n != 12 {
+		t.Errorf("decoded %d frames, want 12", n)
+	}
+	if err := d.LastError(); err != nil {
+		t.Errorf("decoding failed: %v", err)
+	}
+}
+
+// TestVideoEncoderBitRate checks that the rate control meets the targeted bit
+// rate on average.
+func TestVideoEncoderBitRate(t *testing.T) {
+	const frames = 100
+	for _, bitRate := range []int{40000, 80000, 120000} {
+		enc, err := NewVideoEncoder(VideoEncoderOptions{Width: testWidth, Height: testHeight, FrameRate: testFrameRate, GOPSize: testGOPSize, BitRate: bitRate})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stream bytes.Buffer
+		for n := 0; n < frames; n++ {
+			data, err := enc.Encode(testImage(n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream.Write(data)
+		}
+		got := stream.Len() * 8 * testFrameRate / frames
+		if got < bitRate*9/10 || got > bitRate*11/10 {
+			t.Errorf("targeting %d bit/s produced %d bit/s", bitRate, got)
+		}
+
+		d, err := NewVideoDecoderFromBytes(stream.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for ; ; n++ {
+			if _, err := d.NextFrame(); err != nil {
+				break
+			}
+		}
+		if n != frames || d.LastError() != nil {
+			t.Errorf("targeting %d bit/s: decoded %d frames, want %d (%v)", bitRate, n, frames, d.LastError())
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package mpg
+
+import (
+	"math"
+	"time"
+)
+
+// videoScanner counts the frames of a video stream and finds its intra frames
+// from the headers of its pictures, without decoding them. Frames are numbered
+// and timed in the order the video decoder returns them, so MPEG2 field
+// pictures are counted once per pair, and frames that repeat a field are shown
+// for longer.
+//
+// Headers are passed to it in the order they are in the stream, and "finish"
+// is called at the end.
+type videoScanner struct {
+	framerate float64
+	// progressive is the progressive_sequence flag of an MPEG2 sequence
+	// extension, which decides how long repeated fields are shown.
+	progressive bool
+
+	frames int
+	fields int
+	// keyframes are the intra frames in display order.
+	keyframes []Keyframe
+
+	// current is the last picture, until its extension is read.
+	current *scannedPicture
+	// firstField is a field picture waiting for its second field.
+	firstField          *scannedPicture
+	firstFieldStructure int
+	// held is the last intra or predicted frame, which is shown after the B
+	// frames that follow it.
+	held *scannedPicture
+}
+
+// scannedPicture is a picture found by "videoScanner".
+type scannedPicture struct {
+	typ int64
+	// extra is the number of fields the frame repeats.
+	extra  int
+	offset int64
+}
+
+// picture starts a picture of type "typ" at "offset".
+func (s *videoScanner) picture(typ int64, offset int64) {
+	s.flush()
+	s.current = &scannedPicture{typ: typ, offset: offset}
+}
+
+// extension reads the first 40 bits "v" of an extension.
+func (s *videoScanner) extension(v uint64) {
+	switch v >> 36 {
+	case plm_video_extension_sequence:
+		s.progressive = v>>27&1 != 0
+	case plm_video_extension_picture_coding:
+		p := s.current
+		if p == nil {
+			return
+		}
+		s.current = nil
+		structure := int(v >> 16 & 3)
+		if s.firstField != nil {
+			first := s.firstField
+			s.firstField = nil
+			if structure != plm_video_picture_frame && structure != s.firstFieldStructure {
+				s.add(first)
+				return
+			}
+			// Like the decoder, a frame missing its second field is kept
+			// only if other frames refer to it.
+			if first.typ != plm_video_picture_type_b {
+				s.add(first)
+			}
+		}
+		if structure != plm_video_picture_frame {
+			s.firstField, s.firstFieldStructure = p, structure
+			return
+		}
+		if v>>9&1 != 0 {
+			switch {
+			case !s.progressive:
+				p.extra = 1
+			case v>>15&1 != 0:
+				p.extra = 4
+			default:
+				p.extra = 2
+			}
+		}
+		s.add(p)
+	}
+}
+
+// flush adds the last picture if it had no picture coding extension, as in
+// MPEG1 streams.
+func (s *videoScanner) flush() {
+	if s.current != nil {
+		s.add(s.current)
+		s.current = nil
+	}
+}
+
+// add adds a decoded frame, reordering it like the decoder does.
+func (s *videoScanner) add(p *scannedPicture) {
+	if p.typ == plm_video_picture_type_b {
+		s.show(p)
+		return
+	}
+	if s.held != nil {
+		s.show(s.held)
+	}
+	s.held = p
+}
+
+// show numbers a frame in display order.
+func (s *videoScanner) show(p *scannedPicture) {
+	if p.typ == plm_video_picture_type_intra {
+		t := floatToSecs((float64(s.frames) + float64(s.fields)/2) / s.framerate)
+		s.keyframes = append(s.keyframes, Keyframe{s.frames, t, p.offset})
+	}
+	s.frames++
+	s.fields += p.extra
+}
+
+// finish shows the frames left at the end of the stream.
+func (s *videoScanner) finish() {
+	s.flush()
+	s.firstField = nil
+	if s.held != nil {
+		s.show(s.held)
+		s.held = nil
+	}
+}
+
+// duration is how long the frames found so far are shown.
+func (s *videoScanner) duration() time.Duration {
+	return floatToSecs((float64(s.frames) + float64(s.fields)/2) / s.framerate)
+}
+
+// decodeFromKeyframe decodes the video from the intra picture of "keyframe",
+// which must be the next picture in its buffer, and numbers frames in display
+// order as it goes. It returns the first frame "done" returns true for, the
+// last frame if the video ends before that, or nil if no frame was decoded.
+func decodeFromKeyframe(video *plm_video_t, keyframe Keyframe, done func(number int, frame *plm_frame_t) bool) *plm_frame_t {
+	// The fields repeated before the intra frame are found from its time.
+	extra := int64(math.Round((keyframe.Time.Seconds()*video.Framerate - float64(keyframe.Frame)) * 2))
+	var last *plm_frame_t
+	for number := keyframe.Frame; ; {
+		if number == keyframe.Frame {
+			video.Frames_decoded, video.Extra_fields = int64(number), extra
+			video.Time = (float64(number) + float64(extra)/2) / video.Framerate
+		}
+		frame := plm_video_decode(video)
+		if frame == nil {
+			return last
+		}
+		// B frames shown before the intra frame refer to the previous group of
+		// pictures, so they can not be decoded correctly from here. They are
+		// the only frames returned from the current picture.
+		if number == keyframe.Frame && frame == &video.Frame_current {
+			continue
+		}
+		if done(number, frame) {
+			return frame
+		}
+		last = frame
+		number++
+	}
+}
+
+// frameEnd returns the time in frames that "frame" stops being shown at.
+func frameEnd(video *plm_video_t, frame *plm_frame_t) float64 {
+	return frame.Time*video.Framerate + 1 + float64(frame.Extra_fields)/2
+}